@@ -0,0 +1,55 @@
+// Command rewrap re-wraps the data keys of SSE-S3 objects with the active
+// master key. Run it after adding a new key to the keyring and making it
+// active; once it has finished, the old keys can be removed.
+package main
+
+import (
+	"log"
+	"s3-like/internal/config"
+	"s3-like/internal/database"
+	"s3-like/internal/encryption"
+	"s3-like/internal/repository"
+	"s3-like/internal/usecase"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg := config.Load()
+
+	keyring, err := encryption.LoadKeyring(cfg.Encryption)
+	if err != nil {
+		log.Fatal("Failed to load encryption keys:", err)
+	}
+	if keyring == nil {
+		log.Fatal("No master key configured; set SSE_MASTER_KEY or SSE_KEYRING_FILE")
+	}
+
+	db, err := database.NewPostgresConnection(cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	// The wrapped key columns may not exist yet on a database the server
+	// has not started against since upgrading
+	if err := database.RunMigrations(db); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	keyRotationUseCase := usecase.NewKeyRotationUseCase(
+		repository.NewObjectRepository(db),
+		repository.NewMultipartUploadRepository(db),
+		keyring,
+		0,
+	)
+
+	rewrapped, err := keyRotationUseCase.RewrapKeys()
+	if err != nil {
+		log.Fatalf("Re-wrapped %d data keys before failing: %v", rewrapped, err)
+	}
+	log.Printf("Re-wrapped %d data keys with master key %q", rewrapped, keyring.ActiveKeyID())
+}
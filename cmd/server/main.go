@@ -20,14 +20,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"s3-like/docs"
 	"s3-like/internal/config"
 	"s3-like/internal/database"
+	"s3-like/internal/domain"
+	"s3-like/internal/encryption"
 	"s3-like/internal/handler"
 	"s3-like/internal/middleware"
 	"s3-like/internal/repository"
+	"s3-like/internal/storage"
 	"s3-like/internal/usecase"
+	"s3-like/internal/worker"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -61,21 +67,72 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	// Initialize storage backend
+	storageBackend, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
+	// Load the master keys for server-side encryption, if configured
+	keyring, err := encryption.LoadKeyring(cfg.Encryption)
+	if err != nil {
+		log.Fatal("Failed to load encryption keys:", err)
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	bucketRepo := repository.NewBucketRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	accessKeyRepo := repository.NewAccessKeyRepository(db)
 	objectRepo := repository.NewObjectRepository(db)
+	multipartUploadRepo := repository.NewMultipartUploadRepository(db)
+	lifecycleRepo := repository.NewLifecycleRepository(db)
+	blobRepo := repository.NewBlobRepository(db)
 
 	// Initialize use cases
-	authUseCase := usecase.NewAuthUseCase(userRepo, refreshTokenRepo, cfg.JWT.Secret)
-	bucketUseCase := usecase.NewBucketUseCase(bucketRepo)
-	objectUseCase := usecase.NewObjectUseCase(objectRepo, cfg.Storage.BasePath)
+	authUseCase := usecase.NewAuthUseCase(userRepo, refreshTokenRepo, accessKeyRepo, cfg.JWT.Secret)
+	bucketUseCase := usecase.NewBucketUseCase(bucketRepo, keyring)
+	objectUseCase := usecase.NewObjectUseCase(objectRepo, bucketRepo, storageBackend, keyring)
+	presignUseCase := usecase.NewPresignUseCase(accessKeyRepo, cfg.S3.PublicURL, cfg.S3.Region)
+	multipartUseCase := usecase.NewMultipartUseCase(multipartUploadRepo, objectRepo, bucketRepo, storageBackend, keyring)
+	lifecycleUseCase := usecase.NewLifecycleUseCase(lifecycleRepo, bucketRepo, objectRepo, multipartUseCase, storageBackend, cfg.Lifecycle.BatchSize)
+	blobUseCase := usecase.NewBlobUseCase(blobRepo, storageBackend, 0)
+	scrubUseCase := usecase.NewScrubUseCase(objectRepo, blobRepo, multipartUploadRepo, storageBackend, int64(cfg.Scrub.Rate), 0)
+	recoveryUseCase := usecase.NewRecoveryUseCase(objectRepo, multipartUploadRepo, storageBackend)
+
+	// Clean up after a previous run that crashed mid-write, before any new
+	// writes can start
+	if err := recoveryUseCase.Recover(); err != nil {
+		log.Fatal("Failed to recover storage:", err)
+	}
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authUseCase)
 	bucketHandler := handler.NewBucketHandler(bucketUseCase)
 	objectHandler := handler.NewObjectHandler(objectUseCase, bucketUseCase)
+	presignHandler := handler.NewPresignHandler(presignUseCase, bucketUseCase)
+	multipartHandler := handler.NewMultipartHandler(multipartUseCase, bucketUseCase)
+	lifecycleHandler := handler.NewLifecycleHandler(lifecycleUseCase)
+	adminHandler := handler.NewAdminHandler(scrubUseCase)
+	s3Handler := handler.NewS3Handler(bucketUseCase, objectUseCase, multipartUseCase, lifecycleUseCase)
+
+	// Background jobs
+	go worker.Every(context.Background(), "multipart-cleanup", cfg.Multipart.CleanupInterval, func() error {
+		return multipartUseCase.AbortStaleUploads(cfg.Multipart.StaleAfter)
+	})
+	go worker.Every(context.Background(), "lifecycle", cfg.Lifecycle.Interval, lifecycleUseCase.ApplyLifecycle)
+	go worker.Every(context.Background(), "blob-gc", cfg.Storage.GCInterval, func() error {
+		_, err := blobUseCase.CollectGarbage()
+		return err
+	})
+	go worker.Every(context.Background(), "scrub", cfg.Scrub.Interval, func() error {
+		_, err := scrubUseCase.Scrub(cfg.Scrub.Repair)
+		if errors.Is(err, domain.ErrScrubRunning) {
+			// One started through the admin API is still going
+			return nil
+		}
+		return err
+	})
 
 	// Setup router
 	router := gin.Default()
@@ -86,7 +143,25 @@ func main() {
 	router.Use(middleware.ErrorHandler())
 
 	// Routes
-	setupRoutes(router, authHandler, bucketHandler, objectHandler, cfg.JWT.Secret)
+	setupRoutes(router, authHandler, bucketHandler, objectHandler, presignHandler, multipartHandler, lifecycleHandler, adminHandler, middleware.RequireAdmin(userRepo, cfg.Admin.Users), cfg.JWT.Secret)
+
+	// S3-compatible router, served on its own port since path-style
+	// bucket names would collide with the JSON API routes
+	s3Router := gin.New()
+	s3Router.RedirectTrailingSlash = false
+	s3Router.RedirectFixedPath = false
+	s3Router.Use(gin.Recovery())
+	s3Router.Use(middleware.CORS())
+	s3Router.Use(middleware.Logger())
+
+	setupS3Routes(s3Router, s3Handler, authUseCase, cfg.S3.Region)
+
+	go func() {
+		log.Printf("S3 API starting on port %s", cfg.S3.Port)
+		if err := s3Router.Run(":" + cfg.S3.Port); err != nil {
+			log.Fatal("Failed to start S3 API server:", err)
+		}
+	}()
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Server.Port)
@@ -101,6 +176,11 @@ func setupRoutes(
 	authHandler *handler.AuthHandler,
 	bucketHandler *handler.BucketHandler,
 	objectHandler *handler.ObjectHandler,
+	presignHandler *handler.PresignHandler,
+	multipartHandler *handler.MultipartHandler,
+	lifecycleHandler *handler.LifecycleHandler,
+	adminHandler *handler.AdminHandler,
+	requireAdmin gin.HandlerFunc,
 	jwtSecret string,
 ) {
 	// Swagger documentation
@@ -121,6 +201,9 @@ func setupRoutes(
 		auth := api.Group("/auth")
 		{
 			auth.POST("/logout-all", authHandler.LogoutAll)
+			auth.GET("/access-keys", authHandler.ListAccessKeys)
+			auth.POST("/access-keys", authHandler.CreateAccessKey)
+			auth.DELETE("/access-keys/:accessKeyId", authHandler.RevokeAccessKey)
 		}
 
 		// Bucket routes
@@ -130,6 +213,29 @@ func setupRoutes(
 			buckets.POST("", bucketHandler.CreateBucket)
 			buckets.GET("/:bucket", bucketHandler.GetBucket)
 			buckets.DELETE("/:bucket", bucketHandler.DeleteBucket)
+			buckets.POST("/:bucket/presign", presignHandler.PresignObject)
+			buckets.POST("/:bucket/delete", objectHandler.DeleteObjects)
+			buckets.GET("/:bucket/versioning", bucketHandler.GetBucketVersioning)
+			buckets.PUT("/:bucket/versioning", bucketHandler.PutBucketVersioning)
+			buckets.GET("/:bucket/object-lock", bucketHandler.GetObjectLockConfiguration)
+			buckets.PUT("/:bucket/object-lock", bucketHandler.PutObjectLockConfiguration)
+			buckets.GET("/:bucket/encryption", bucketHandler.GetBucketEncryption)
+			buckets.PUT("/:bucket/encryption", bucketHandler.PutBucketEncryption)
+			buckets.DELETE("/:bucket/encryption", bucketHandler.DeleteBucketEncryption)
+			buckets.GET("/:bucket/compression", bucketHandler.GetBucketCompression)
+			buckets.PUT("/:bucket/compression", bucketHandler.PutBucketCompression)
+			buckets.DELETE("/:bucket/compression", bucketHandler.DeleteBucketCompression)
+			buckets.GET("/:bucket/lifecycle", lifecycleHandler.GetBucketLifecycle)
+			buckets.PUT("/:bucket/lifecycle", lifecycleHandler.PutBucketLifecycle)
+			buckets.DELETE("/:bucket/lifecycle", lifecycleHandler.DeleteBucketLifecycle)
+
+			// Object subresources come before the key, so a key may contain
+			// slashes
+			buckets.PUT("/:bucket/metadata/*key", objectHandler.PutObjectMetadata)
+			buckets.GET("/:bucket/versions/*key", objectHandler.ListObjectVersions)
+			buckets.GET("/:bucket/tagging/*key", objectHandler.GetObjectTagging)
+			buckets.PUT("/:bucket/tagging/*key", objectHandler.PutObjectTagging)
+			buckets.DELETE("/:bucket/tagging/*key", objectHandler.DeleteObjectTagging)
 		}
 
 		// Object routes
@@ -137,19 +243,64 @@ func setupRoutes(
 		{
 			objects.GET("", objectHandler.ListObjects)
 			objects.POST("", objectHandler.UploadObject)
-			// objects.GET("/:key", objectHandler.GetObject)
-			objects.DELETE("/:key", objectHandler.DeleteObject)
-			objects.GET("/:key/versions", objectHandler.ListObjectVersions)
-			objects.GET("/:key/versions/:version", objectHandler.GetObjectVersion)
+			objects.POST("/*key", objectHandler.ObjectAction)
+			objects.PUT("/*key", objectHandler.PutObject)
+			objects.DELETE("/*key", objectHandler.DeleteObject)
+		}
+
+		// Multipart upload routes
+		uploads := api.Group("/buckets/:bucket/uploads")
+		{
+			uploads.GET("", multipartHandler.ListMultipartUploads)
+			uploads.POST("", multipartHandler.CreateMultipartUpload)
+			uploads.DELETE("/:uploadId", multipartHandler.AbortMultipartUpload)
+			uploads.GET("/:uploadId/parts", multipartHandler.ListParts)
+			uploads.PUT("/:uploadId/parts/:partNumber", multipartHandler.UploadPart)
+			uploads.POST("/:uploadId/complete", multipartHandler.CompleteMultipartUpload)
+		}
+
+		// Admin routes, for the users named in ADMIN_USERS
+		admin := api.Group("/admin")
+		admin.Use(requireAdmin)
+		{
+			admin.GET("/scrub", adminHandler.GetScrubReport)
+			admin.POST("/scrub", adminHandler.StartScrub)
+			admin.GET("/metrics", adminHandler.GetMetrics)
 		}
 	}
 
-	router.GET("/api/v1/buckets/:bucket/objects/:key", objectHandler.GetObject)
+	// Downloads also serve public buckets, so authentication is optional
+	publicObjects := router.Group("/api/v1/buckets/:bucket/objects")
+	publicObjects.Use(middleware.OptionalJWTAuth(jwtSecret))
+	{
+		publicObjects.GET("/*key", objectHandler.GetObject)
+		publicObjects.HEAD("/*key", objectHandler.HeadObject)
+	}
 
 	// Health check
 	router.GET("/health", healthCheck)
 }
 
+func setupS3Routes(router *gin.Engine, s3Handler *handler.S3Handler, authUseCase domain.AuthUseCase, region string) {
+	router.Use(middleware.SigV4Auth(authUseCase, region))
+
+	router.GET("/", s3Handler.ListBuckets)
+
+	// Bucket routes
+	router.PUT("/:bucket", s3Handler.BucketPut)
+	router.HEAD("/:bucket", s3Handler.HeadBucket)
+	router.GET("/:bucket", s3Handler.BucketGet)
+	router.POST("/:bucket", s3Handler.BucketPost)
+	router.DELETE("/:bucket", s3Handler.BucketDelete)
+
+	// Object routes; "/{bucket}/" with an empty key is a bucket request
+	router.PUT("/:bucket/*key", s3Handler.BucketRoute(s3Handler.BucketPut, s3Handler.ObjectPut))
+	router.HEAD("/:bucket/*key", s3Handler.BucketRoute(s3Handler.HeadBucket, s3Handler.HeadObject))
+	router.GET("/:bucket/*key", s3Handler.BucketRoute(s3Handler.BucketGet, s3Handler.ObjectGet))
+	router.POST("/:bucket/*key", s3Handler.BucketRoute(s3Handler.BucketPost, s3Handler.ObjectPost))
+	router.DELETE("/:bucket/*key", s3Handler.BucketRoute(s3Handler.BucketDelete, s3Handler.ObjectDelete))
+}
+
 // @Summary Health Check
 // @Description Check if the service is running
 // @Tags health
@@ -23,27 +23,24 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/buckets": {
+        "/api/v1/admin/metrics": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get list of all buckets owned by the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the server's expvar metrics, among them the storage scrub totals under \"scrub\"",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "buckets"
+                    "admin"
                 ],
-                "summary": "List user buckets",
+                "summary": "Get server metrics",
                 "responses": {
                     "200": {
-                        "description": "List of buckets",
+                        "description": "Metrics",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -56,22 +53,24 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Not an admin",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/v1/admin/scrub": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new storage bucket",
+                "description": "Get the report of the running storage scrub, or else of the last one. A scrub rehashes stored content and looks for records whose content is missing and content no record points to.",
                 "consumes": [
                     "application/json"
                 ],
@@ -79,52 +78,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "buckets"
+                    "admin"
                 ],
-                "summary": "Create a new bucket",
-                "parameters": [
-                    {
-                        "description": "Bucket creation details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get the storage scrub report",
+                "responses": {
+                    "200": {
+                        "description": "Scrub report",
                         "schema": {
-                            "$ref": "#/definitions/domain.CreateBucketRequest"
+                            "$ref": "#/definitions/domain.ScrubReport"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Bucket created successfully",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/domain.Bucket"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Invalid request or bucket already exists",
+                    "403": {
+                        "description": "Not an admin",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "No scrub has run yet",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/buckets/{bucket}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get details of a specific bucket",
+                "description": "Start a storage scrub in the background; poll GET /api/v1/admin/scrub for its report. With repair set, orphaned content is moved under .quarantine/ rather than only reported.",
                 "consumes": [
                     "application/json"
                 ],
@@ -132,23 +125,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "buckets"
+                    "admin"
                 ],
-                "summary": "Get bucket details",
+                "summary": "Start a storage scrub",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bucket name",
-                        "name": "bucket",
-                        "in": "path",
-                        "required": true
+                        "description": "Scrub options",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ScrubRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Bucket details",
+                    "202": {
+                        "description": "Scrub started",
                         "schema": {
-                            "$ref": "#/definitions/domain.Bucket"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -158,22 +160,31 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Bucket not found",
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "A scrub is already running",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/auth/access-keys": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete a bucket and all its contents",
+                "description": "List the S3 access keys of the authenticated user, without their secrets",
                 "consumes": [
                     "application/json"
                 ],
@@ -181,24 +192,12 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "buckets"
-                ],
-                "summary": "Delete a bucket",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bucket name",
-                        "name": "bucket",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "List access keys",
                 "responses": {
-                    "204": {
-                        "description": "Bucket deleted successfully"
-                    },
-                    "400": {
-                        "description": "Invalid request",
+                    "200": {
+                        "description": "List of access keys",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -211,24 +210,22 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Bucket not found",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/buckets/{bucket}/objects": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a paginated list of objects in a bucket",
+                "description": "Create a new S3 access key pair for the authenticated user. The secret is only returned once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -236,41 +233,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "objects"
-                ],
-                "summary": "List objects in a bucket",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bucket name",
-                        "name": "bucket",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Object key prefix filter",
-                        "name": "prefix",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page size (default: 50, max: 1000)",
-                        "name": "page_size",
-                        "in": "query"
-                    }
+                    "auth"
                 ],
+                "summary": "Create an access key",
                 "responses": {
-                    "200": {
-                        "description": "List of objects",
+                    "201": {
+                        "description": "Access key created",
                         "schema": {
-                            "$ref": "#/definitions/domain.ListObjectsResponse"
+                            "$ref": "#/definitions/domain.CreateAccessKeyResponse"
                         }
                     },
                     "401": {
@@ -280,13 +250,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Bucket not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -295,83 +258,38 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/v1/auth/access-keys/{accessKeyId}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload a file to a specific bucket with optional metadata",
+                "description": "Revoke one of the authenticated user's S3 access keys",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "objects"
+                    "auth"
                 ],
-                "summary": "Upload a file",
+                "summary": "Revoke an access key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bucket name",
-                        "name": "bucket",
+                        "description": "Access key ID",
+                        "name": "accessKeyId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Object key (if not provided, filename will be used)",
-                        "name": "key",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "JSON string with custom metadata (e.g., {\\",
-                        "name": "metadata",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content type override",
-                        "name": "content-type",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "File description",
-                        "name": "description",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Comma-separated tags",
-                        "name": "tags",
-                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "File uploaded successfully",
-                        "schema": {
-                            "$ref": "#/definitions/domain.UploadObjectResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                    "204": {
+                        "description": "Access key revoked"
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -381,14 +299,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Bucket not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
+                        "description": "Access key not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -397,45 +308,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/buckets/{bucket}/objects/{key}": {
+        "/api/v1/buckets": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Download the latest version of a file from a bucket",
+                "description": "Get list of all buckets owned by the authenticated user",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "objects"
-                ],
-                "summary": "Download a file",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bucket name",
-                        "name": "bucket",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Object key",
-                        "name": "key",
-                        "in": "path",
-                        "required": true
-                    }
+                    "buckets"
                 ],
+                "summary": "List user buckets",
                 "responses": {
                     "200": {
-                        "description": "File content",
+                        "description": "List of buckets",
                         "schema": {
-                            "type": "file"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -445,8 +341,8 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Object or bucket not found",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -454,13 +350,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete the latest version of an object from a bucket",
+                "description": "Create a new storage bucket. Creating it with Object Lock enabled also enables versioning; a default retention applied to new versions can be given at the same time. New blobs can be compressed with zstd or gzip.",
                 "consumes": [
                     "application/json"
                 ],
@@ -468,45 +364,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "objects"
+                    "buckets"
                 ],
-                "summary": "Delete an object",
+                "summary": "Create a new bucket",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bucket name",
-                        "name": "bucket",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Object key",
-                        "name": "key",
-                        "in": "path",
-                        "required": true
+                        "description": "Bucket creation details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateBucketRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Object deleted successfully"
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                    "201": {
+                        "description": "Bucket created successfully",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.Bucket"
                         }
                     },
-                    "404": {
-                        "description": "Object or bucket not found",
+                    "400": {
+                        "description": "Invalid request or bucket already exists",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -515,14 +402,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/buckets/{bucket}/objects/{key}/versions": {
+        "/api/v1/buckets/{bucket}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all versions of a specific object",
+                "description": "Get details of a specific bucket",
                 "consumes": [
                     "application/json"
                 ],
@@ -530,9 +417,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "objects"
+                    "buckets"
                 ],
-                "summary": "List all versions of an object",
+                "summary": "Get bucket details",
                 "parameters": [
                     {
                         "type": "string",
@@ -540,21 +427,13 @@ const docTemplate = `{
                         "name": "bucket",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Object key",
-                        "name": "key",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of object versions",
+                        "description": "Bucket details",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.Bucket"
                         }
                     },
                     "401": {
@@ -570,35 +449,26 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     }
                 }
-            }
-        },
-        "/api/v1/buckets/{bucket}/objects/{key}/versions/{version}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Download a specific version of a file from a bucket",
+                "description": "Delete a bucket and all its contents",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "objects"
+                    "buckets"
                 ],
-                "summary": "Download a specific version of a file",
+                "summary": "Delete a bucket",
                 "parameters": [
                     {
                         "type": "string",
@@ -606,27 +476,17 @@ const docTemplate = `{
                         "name": "bucket",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Object key",
-                        "name": "key",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Version ID",
-                        "name": "version",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "File content",
+                    "204": {
+                        "description": "Bucket deleted successfully"
+                    },
+                    "400": {
+                        "description": "Invalid request",
                         "schema": {
-                            "type": "file"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -637,7 +497,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Object version or bucket not found",
+                        "description": "Bucket not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -646,9 +506,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Authenticate user and return access token and refresh token",
+        "/api/v1/buckets/{bucket}/compression": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the algorithm new blobs are compressed with; algorithm is empty when they are stored as uploaded",
                 "consumes": [
                     "application/json"
                 ],
@@ -656,52 +521,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "buckets"
                 ],
-                "summary": "User login",
+                "summary": "Get bucket compression",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful",
+                        "description": "Compression",
                         "schema": {
-                            "$ref": "#/definitions/domain.AuthResponse"
+                            "$ref": "#/definitions/domain.BucketCompressionRequest"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Invalid credentials",
+                    "404": {
+                        "description": "Bucket not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/auth/logout": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Revoke refresh token (logout)",
+                "description": "Compress new blobs with zstd or gzip. Size and ETag still describe the content as uploaded, and reads decompress it transparently. Content types that are compressed already, and content sent with a Content-Encoding, are stored as uploaded.",
                 "consumes": [
                     "application/json"
                 ],
@@ -709,30 +570,36 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "buckets"
                 ],
-                "summary": "User logout",
+                "summary": "Set bucket compression",
                 "parameters": [
                     {
-                        "description": "Refresh token to revoke",
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Compression",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.RefreshTokenRequest"
+                            "$ref": "#/definitions/domain.BucketCompressionRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Logout successful",
+                        "description": "Compression",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.BucketCompressionRequest"
                         }
                     },
                     "400": {
-                        "description": "Invalid request",
+                        "description": "Unsupported algorithm",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -744,18 +611,30 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
-            }
-        },
-        "/auth/logout-all": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Revoke all refresh tokens for the authenticated user",
+                "description": "Store new blobs as uploaded. Blobs already compressed stay compressed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -763,15 +642,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "buckets"
+                ],
+                "summary": "Turn off bucket compression",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Logout from all devices",
                 "responses": {
                     "200": {
-                        "description": "Logout from all devices successful",
+                        "description": "Compression",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.BucketCompressionRequest"
                         }
                     },
                     "401": {
@@ -780,13 +667,32 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/auth/refresh": {
+        "/api/v1/buckets/{bucket}/delete": {
             "post": {
-                "description": "Use refresh token to get a new access token and refresh token pair",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete up to 1000 objects, or specific versions, in one request. Deletion runs in a single transaction and the outcome is reported per key; keys that do not exist count as deleted, and versions protected by Object Lock are reported as errors. In quiet mode only errors are returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -794,25 +700,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "objects"
                 ],
-                "summary": "Refresh access token",
+                "summary": "Delete several objects",
                 "parameters": [
                     {
-                        "description": "Refresh token",
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Objects to delete",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.RefreshTokenRequest"
+                            "$ref": "#/definitions/domain.DeleteObjectsRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Token refreshed successfully",
+                        "description": "Per-key results",
                         "schema": {
-                            "$ref": "#/definitions/domain.AuthResponse"
+                            "$ref": "#/definitions/domain.DeleteObjectsResponse"
                         }
                     },
                     "400": {
@@ -823,7 +736,28 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Invalid or expired refresh token",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -832,9 +766,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Register a new user account and return access token and refresh token",
+        "/api/v1/buckets/{bucket}/encryption": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the server-side encryption applied to uploads that do not ask for any; sse_algorithm is empty when there is none",
                 "consumes": [
                     "application/json"
                 ],
@@ -842,29 +781,162 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "buckets"
                 ],
-                "summary": "User registration",
+                "summary": "Get bucket default encryption",
                 "parameters": [
                     {
-                        "description": "Registration details",
-                        "name": "request",
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Default encryption",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BucketEncryptionRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encrypt uploads that do not ask for server-side encryption with SSE-S3. AES256 is the only algorithm supported, and the server needs a master key configured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "buckets"
+                ],
+                "summary": "Set bucket default encryption",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Default encryption",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.RegisterRequest"
+                            "$ref": "#/definitions/domain.BucketEncryptionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Registration successful",
+                    "200": {
+                        "description": "Default encryption",
                         "schema": {
-                            "$ref": "#/definitions/domain.AuthResponse"
+                            "$ref": "#/definitions/domain.BucketEncryptionRequest"
                         }
                     },
                     "400": {
-                        "description": "Invalid request or user already exists",
+                        "description": "Unsupported algorithm, or no master key configured",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop encrypting uploads that do not ask for server-side encryption. Objects already encrypted stay encrypted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "buckets"
+                ],
+                "summary": "Remove bucket default encryption",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Default encryption",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BucketEncryptionRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -873,9 +945,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/api/v1/buckets/{bucket}/lifecycle": {
             "get": {
-                "description": "Check if the service is running",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the lifecycle rules of a bucket",
                 "consumes": [
                     "application/json"
                 ],
@@ -883,163 +960,3488 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "lifecycle"
+                ],
+                "summary": "Get bucket lifecycle rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Health Check",
                 "responses": {
                     "200": {
-                        "description": "Service is healthy",
+                        "description": "Lifecycle rules",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BucketLifecycle"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket or lifecycle configuration not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "domain.AuthResponse": {
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the lifecycle rules of a bucket. Rules select objects by prefix, tags and size, and can expire current versions after a number of days, permanently delete noncurrent versions by age or beyond a number of newer versions, remove delete markers with no versions left behind them and abort incomplete multipart uploads. Rules are evaluated periodically by a background job.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lifecycle"
+                ],
+                "summary": "Set bucket lifecycle rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lifecycle rules",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PutBucketLifecycleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Lifecycle rules saved",
+                        "schema": {
+                            "$ref": "#/definitions/domain.BucketLifecycle"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid lifecycle configuration",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove all lifecycle rules from a bucket",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lifecycle"
+                ],
+                "summary": "Delete bucket lifecycle rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Lifecycle rules deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/metadata/{key}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the user metadata of the latest version of an object, or of version_id, in place. The content is not rewritten, so the ETag does not change. Keys are stored in lower case and returned as x-amz-meta-* headers; keys and values may total at most 2 KB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Replace object metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User metadata",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PutObjectMetadataRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Object version with its new metadata",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Object"
+                        }
+                    },
+                    "400": {
+                        "description": "Metadata too large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/object-lock": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get whether Object Lock is enabled on a bucket and its default retention",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "buckets"
+                ],
+                "summary": "Get bucket Object Lock configuration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Object Lock configuration",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ObjectLockConfigurationRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable Object Lock on a bucket with versioning enabled and set the default retention for new versions. Object Lock cannot be disabled again, and versioning can no longer be suspended once it is on. Omitting default_retention removes the default.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "buckets"
+                ],
+                "summary": "Set bucket Object Lock configuration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Object Lock configuration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ObjectLockConfigurationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Object Lock configuration",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ObjectLockConfigurationRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid configuration",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Versioning is not enabled on the bucket",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/objects": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the latest object versions in key order. With a delimiter, keys containing it after the prefix are rolled up into common_prefixes, like folders. Pass next_continuation_token back as continuation_token to fetch the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "List objects in a bucket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key prefix filter",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Character used to group keys into common prefixes, usually /",
+                        "name": "delimiter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only list keys after this key",
+                        "name": "start_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token from a previous truncated response",
+                        "name": "continuation_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of objects and common prefixes (default: 50, max: 1000)",
+                        "name": "max_keys",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only list objects carrying this tag, as key=value; repeat to require several",
+                        "name": "tag",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of objects",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ListObjectsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid continuation token or tag filter",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a file to a specific bucket with optional metadata",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Upload a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key (if not provided, filename will be used)",
+                        "name": "key",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JSON string with custom metadata (e.g., {\\",
+                        "name": "metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type override",
+                        "name": "content-type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File description",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tags in URL query format, e.g. project=alpha\u0026team=storage",
+                        "name": "tags",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object Lock retention mode: GOVERNANCE or COMPLIANCE",
+                        "name": "object_lock_mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retain-until date in RFC 3339 format",
+                        "name": "object_lock_retain_until",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Place a legal hold on the new version",
+                        "name": "object_lock_legal_hold",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "File uploaded successfully",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UploadObjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner, or the version being replaced is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/objects/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download the latest version of a file from a bucket, or the version named by version_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Download a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ID (default: latest version)",
+                        "name": "version_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte ranges to return, e.g. bytes=0-1023; several ranges give a multipart/byteranges response",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return the object if its ETag matches",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return 304 if the object's ETag matches",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return 304 unless the object was modified since this date",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return the object if it was not modified since this date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ENABLED to return the stored x-amz-checksum-* headers; ignored for range reads",
+                        "name": "x-amz-checksum-mode",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested byte ranges",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object, version or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The requested version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition failed"
+                    },
+                    "416": {
+                        "description": "Requested range not satisfiable"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream the raw request body to storage as a new version of the key, without multipart/form-data buffering. Content-Type, Cache-Control, Content-Disposition and Content-Encoding are stored and returned on download, x-amz-meta-* headers become user metadata and x-amz-tagging sets the tags. The upload is rejected if the body does not match Content-MD5 or an x-amz-checksum-* header, or is shorter than Content-Length; the checksums given are stored with the version.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Upload an object from the request body",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 MD5 digest of the body",
+                        "name": "Content-MD5",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 CRC32 checksum of the body",
+                        "name": "x-amz-checksum-crc32",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 CRC32C checksum of the body",
+                        "name": "x-amz-checksum-crc32c",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 SHA-1 digest of the body",
+                        "name": "x-amz-checksum-sha1",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 SHA-256 digest of the body",
+                        "name": "x-amz-checksum-sha256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cache-Control to return on download",
+                        "name": "Cache-Control",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content-Disposition to return on download",
+                        "name": "Content-Disposition",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content-Encoding of the body, returned on download",
+                        "name": "Content-Encoding",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tags in URL query format, e.g. project=alpha\u0026team=storage",
+                        "name": "x-amz-tagging",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Object content",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Object uploaded",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UploadObjectResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Quoted MD5 of the content"
+                            },
+                            "X-Object-Version-ID": {
+                                "type": "string",
+                                "description": "Version ID of the new object"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, or the body does not match its digests or Content-Length",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner, or the version being replaced is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an object from a bucket. In a versioned bucket this places a delete marker on top of the version history unless version_id names a version to remove permanently; the previous version then becomes the latest. In an unversioned bucket the object is removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Delete an object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version to delete permanently",
+                        "name": "version_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete a version under GOVERNANCE retention; bucket owner only",
+                        "name": "bypass_governance",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Object deleted successfully",
+                        "headers": {
+                            "X-Delete-Marker": {
+                                "type": "string",
+                                "description": "true when a delete marker was created or removed"
+                            },
+                            "X-Object-Version-ID": {
+                                "type": "string",
+                                "description": "Version ID of the new delete marker, or of the deleted version"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner, or the version is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "head": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the headers of a download (size, ETag, version and X-Object-Meta-* metadata) without the body. Conditional headers are evaluated as for a download.",
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Get object metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ID (default: latest version)",
+                        "name": "version_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Succeed only if the object's ETag matches",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return 304 if the object's ETag matches",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Return 304 unless the object was modified since this date",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Succeed only if the object was not modified since this date",
+                        "name": "If-Unmodified-Since",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ENABLED to return the stored x-amz-checksum-* headers; ignored for range reads",
+                        "name": "x-amz-checksum-mode",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Object exists"
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Object or bucket not found"
+                    },
+                    "412": {
+                        "description": "Precondition failed"
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/objects/{key}/copy": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copy the latest version, or a specific version, of an object to a destination bucket and key without downloading it. The metadata directive COPY keeps the source content type and metadata; REPLACE uses the ones in the request. The copy fails with 412 if a condition on the source does not hold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Copy an object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Source bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Source object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CopyObjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Object copied",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UploadObjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the owner of the destination bucket",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/objects/{key}/legal-hold": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Place or remove a legal hold on the latest version of an object, or on version_id. While the hold is on the version cannot be deleted or overwritten, whatever its retention.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Set object legal hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Legal hold",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PutObjectLegalHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Object version with its new legal hold",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Object"
+                        }
+                    },
+                    "400": {
+                        "description": "Object Lock not enabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object version or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/objects/{key}/retention": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set the Object Lock retention of the latest version of an object, or of version_id. Retention can always be extended or moved from GOVERNANCE to COMPLIANCE; shortening or removing it (empty mode and retain_until) is only possible in GOVERNANCE mode with bypass_governance, which only the bucket owner may use.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Set object retention",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Retention",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PutObjectRetentionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Object version with its new retention",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Object"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid retention or Object Lock not enabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner, or the retention cannot be weakened",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object version or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/objects/{key}/versions/{version}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make a previous version of an object the latest again. The version is copied into a new latest version, so the history is kept and the restore can itself be undone. Restoring the current version is a no-op.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Restore an object version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version ID to restore",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Version restored",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UploadObjectResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object version or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/presign": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a time-limited SigV4 presigned URL on the S3 API for downloading (GET) or uploading (PUT) an object without credentials. Requires an active access key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Create a presigned URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Presign parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PresignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Presigned URL",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PresignResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or no active access key",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "PUT on a bucket the user does not own",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/tagging/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the tag set of the latest version of an object, or of version_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Get object tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version to read the tags of",
+                        "name": "version_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag set",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PutObjectTaggingRequest"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the tag set of the latest version of an object, or of version_id, without rewriting its content. At most 10 tags, with keys of up to 128 and values of up to 256 characters; keys may not start with aws:.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Set object tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag set",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PutObjectTaggingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tag set",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PutObjectTaggingRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tag set",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove all tags from the latest version of an object, or from version_id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "Delete object tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Version to remove the tags from",
+                        "name": "version_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Tags deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Object or bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "405": {
+                        "description": "The version is a delete marker",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/uploads": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the multipart uploads in progress in a bucket",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "multipart"
+                ],
+                "summary": "List multipart uploads",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of uploads",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start a multipart upload for a large object. Upload the parts with the returned upload_id, then complete or abort the upload. The Object Lock retention and legal hold requested here are given to the object when the upload completes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "multipart"
+                ],
+                "summary": "Start a multipart upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Upload parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateMultipartUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Upload started",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MultipartUpload"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, invalid retention or Object Lock not enabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/uploads/{uploadId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Abort a multipart upload and discard its parts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "multipart"
+                ],
+                "summary": "Abort a multipart upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Upload aborted"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket or upload not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/uploads/{uploadId}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Assemble the listed parts, in ascending part number order, into a new object version",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "multipart"
+                ],
+                "summary": "Complete a multipart upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parts to assemble",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CompleteMultipartUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Object created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UploadObjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid part list, or the requested retention has lapsed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner, or the version being replaced is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket or upload not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/uploads/{uploadId}/parts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the parts uploaded so far for a multipart upload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "multipart"
+                ],
+                "summary": "List uploaded parts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload and its parts",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket or upload not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/uploads/{uploadId}/parts/{partNumber}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload one part of a multipart upload as the raw request body. Uploading the same part number again replaces the part. The part is rejected if it does not match Content-MD5 or an x-amz-checksum-* header.",
+                "consumes": [
+                    "application/octet-stream"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "multipart"
+                ],
+                "summary": "Upload a part",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Part number (1-10000)",
+                        "name": "partNumber",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 MD5 digest of the part",
+                        "name": "Content-MD5",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Part uploaded",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MultipartPart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid part number, or the part does not match its digests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket or upload not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/versioning": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the versioning status of a bucket: Unversioned, Enabled or Suspended",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "buckets"
+                ],
+                "summary": "Get bucket versioning",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Versioning status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable or suspend versioning on a bucket. While suspended, uploads write a \"null\" version that replaces the previous null version; existing versions are kept. A bucket cannot return to Unversioned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "buckets"
+                ],
+                "summary": "Set bucket versioning",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Versioning status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.BucketVersioningRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Versioning status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid versioning status",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Not the bucket owner",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Object Lock is enabled on the bucket",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/buckets/{bucket}/versions/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all versions of a specific object",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "objects"
+                ],
+                "summary": "List all versions of an object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket name",
+                        "name": "bucket",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Object key, may contain slashes",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of object versions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Bucket not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate user and return access token and refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke refresh token (logout)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User logout",
+                "parameters": [
+                    {
+                        "description": "Refresh token to revoke",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logout successful",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke all refresh tokens for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout from all devices",
+                "responses": {
+                    "200": {
+                        "description": "Logout from all devices successful",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Use refresh token to get a new access token and refresh token pair",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token refreshed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired refresh token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user account and return access token and refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User registration",
+                "parameters": [
+                    {
+                        "description": "Registration details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Registration successful",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or user already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check if the service is running",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health Check",
+                "responses": {
+                    "200": {
+                        "description": "Service is healthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AbortIncompleteMultipartUpload": {
+            "type": "object",
+            "properties": {
+                "days_after_initiation": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "domain.AuthResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.User"
+                }
+            }
+        },
+        "domain.Bucket": {
+            "type": "object",
+            "properties": {
+                "compression": {
+                    "description": "Compression is the algorithm new blobs are compressed with when their\ncontent type is worth compressing: empty, zstd or gzip",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_encryption": {
+                    "description": "DefaultEncryption is the server-side encryption applied to uploads that\ndo not ask for any: empty or AES256",
+                    "type": "string"
+                },
+                "default_retention": {
+                    "$ref": "#/definitions/domain.DefaultRetention"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "object_lock_enabled": {
+                    "description": "ObjectLockEnabled turns on write-once protection for the bucket's\nversions; it cannot be turned off again",
+                    "type": "boolean"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.User"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "versioning": {
+                    "$ref": "#/definitions/domain.VersioningStatus"
+                }
+            }
+        },
+        "domain.BucketCompressionRequest": {
+            "type": "object",
+            "required": [
+                "algorithm"
+            ],
+            "properties": {
+                "algorithm": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BucketEncryptionRequest": {
+            "type": "object",
+            "required": [
+                "sse_algorithm"
+            ],
+            "properties": {
+                "sse_algorithm": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BucketLifecycle": {
+            "type": "object",
+            "properties": {
+                "bucket_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LifecycleRule"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BucketVersioningRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "Enabled",
+                        "Suspended"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.VersioningStatus"
+                        }
+                    ],
+                    "example": "Enabled"
+                }
+            }
+        },
+        "domain.CompleteMultipartUploadRequest": {
+            "type": "object",
+            "required": [
+                "parts"
+            ],
+            "properties": {
+                "parts": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.CompletedPart"
+                    }
+                }
+            }
+        },
+        "domain.CompletedPart": {
+            "type": "object",
+            "required": [
+                "etag",
+                "part_number"
+            ],
+            "properties": {
+                "etag": {
+                    "type": "string"
+                },
+                "part_number": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CopyObjectRequest": {
+            "type": "object",
+            "required": [
+                "destination_key"
+            ],
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "destination_bucket": {
+                    "type": "string"
+                },
+                "destination_key": {
+                    "type": "string"
+                },
+                "if_match": {
+                    "type": "string"
+                },
+                "if_modified_since": {
+                    "type": "string"
+                },
+                "if_none_match": {
+                    "type": "string"
+                },
+                "if_unmodified_since": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "metadata_directive": {
+                    "type": "string",
+                    "enum": [
+                        "COPY",
+                        "REPLACE"
+                    ]
+                },
+                "source_version_id": {
+                    "type": "string"
+                },
+                "tagging_directive": {
+                    "type": "string",
+                    "enum": [
+                        "COPY",
+                        "REPLACE"
+                    ]
+                },
+                "tags": {
+                    "$ref": "#/definitions/domain.ObjectTags"
+                }
+            }
+        },
+        "domain.CreateAccessKeyResponse": {
+            "type": "object",
+            "properties": {
+                "access_key_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "secret_access_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateBucketRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "compression": {
+                    "description": "Compression is the algorithm new blobs are compressed with: zstd or\ngzip",
+                    "type": "string"
+                },
+                "default_retention": {
+                    "$ref": "#/definitions/domain.DefaultRetention"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "object_lock_enabled": {
+                    "description": "ObjectLockEnabled creates the bucket with Object Lock, which also\nenables versioning",
+                    "type": "boolean"
+                },
+                "public": {
+                    "type": "boolean"
+                },
+                "versioning": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.CreateMultipartUploadRequest": {
+            "type": "object",
+            "required": [
+                "key"
+            ],
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "object_lock_legal_hold": {
+                    "type": "boolean"
+                },
+                "object_lock_mode": {
+                    "type": "string"
+                },
+                "object_lock_retain_until": {
+                    "type": "string"
+                },
+                "tags": {
+                    "$ref": "#/definitions/domain.ObjectTags"
+                }
+            }
+        },
+        "domain.DefaultRetention": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "GOVERNANCE",
+                        "COMPLIANCE"
+                    ]
+                },
+                "years": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.DeleteObjectError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "version_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DeleteObjectsRequest": {
+            "type": "object",
+            "required": [
+                "objects"
+            ],
+            "properties": {
+                "bypass_governance": {
+                    "description": "BypassGovernance allows the bucket owner to delete versions under\nGOVERNANCE retention",
+                    "type": "boolean"
+                },
+                "objects": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.ObjectIdentifier"
+                    }
+                },
+                "quiet": {
+                    "description": "Quiet omits successfully deleted keys from the response",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.DeleteObjectsResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeletedObject"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeleteObjectError"
+                    }
+                }
+            }
+        },
+        "domain.DeletedObject": {
+            "type": "object",
+            "properties": {
+                "delete_marker": {
+                    "type": "boolean"
+                },
+                "delete_marker_version_id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "version_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LifecycleExpiration": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "expired_object_delete_marker": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.LifecycleFilter": {
+            "type": "object",
+            "properties": {
+                "object_size_greater_than": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "object_size_less_than": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.LifecycleRule": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "abort_incomplete_multipart_upload": {
+                    "$ref": "#/definitions/domain.AbortIncompleteMultipartUpload"
+                },
+                "expiration": {
+                    "$ref": "#/definitions/domain.LifecycleExpiration"
+                },
+                "filter": {
+                    "$ref": "#/definitions/domain.LifecycleFilter"
+                },
+                "id": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "noncurrent_version_expiration": {
+                    "$ref": "#/definitions/domain.NoncurrentVersionExpiration"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "Enabled",
+                        "Disabled"
+                    ]
+                }
+            }
+        },
+        "domain.ListObjectsResponse": {
+            "type": "object",
+            "properties": {
+                "common_prefixes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "delimiter": {
+                    "type": "string"
+                },
+                "is_truncated": {
+                    "type": "boolean"
+                },
+                "key_count": {
+                    "type": "integer"
+                },
+                "max_keys": {
+                    "type": "integer"
+                },
+                "next_continuation_token": {
+                    "type": "string"
+                },
+                "objects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Object"
+                    }
+                },
+                "prefix": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "pass123"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "domain.MultipartPart": {
+            "type": "object",
+            "properties": {
+                "checksum_crc32": {
+                    "type": "string"
+                },
+                "checksum_crc32c": {
+                    "type": "string"
+                },
+                "checksum_sha1": {
+                    "type": "string"
+                },
+                "checksum_sha256": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "etag": {
+                    "type": "string"
+                },
+                "part_number": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "upload_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MultipartUpload": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "bucket_id": {
                     "type": "string"
                 },
-                "expires_in": {
-                    "type": "integer"
+                "cache_control": {
+                    "type": "string"
                 },
-                "refresh_token": {
+                "content_disposition": {
                     "type": "string"
                 },
-                "token_type": {
+                "content_encoding": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/domain.User"
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/domain.UserMetadata"
+                },
+                "sse": {
+                    "type": "string"
+                },
+                "sse_customer_key_md5": {
+                    "type": "string"
+                },
+                "tags": {
+                    "$ref": "#/definitions/domain.ObjectTags"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "upload_id": {
+                    "type": "string"
                 }
             }
         },
-        "domain.Bucket": {
+        "domain.NoncurrentVersionExpiration": {
             "type": "object",
             "properties": {
+                "newer_noncurrent_versions": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "noncurrent_days": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "domain.Object": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "$ref": "#/definitions/domain.Bucket"
+                },
+                "bucket_id": {
+                    "type": "string"
+                },
+                "cache_control": {
+                    "type": "string"
+                },
+                "checksum_crc32": {
+                    "type": "string"
+                },
+                "checksum_crc32c": {
+                    "type": "string"
+                },
+                "checksum_sha1": {
+                    "type": "string"
+                },
+                "checksum_sha256": {
+                    "type": "string"
+                },
+                "compression": {
+                    "type": "string"
+                },
+                "content_disposition": {
+                    "type": "string"
+                },
+                "content_encoding": {
+                    "type": "string"
+                },
+                "content_type": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "etag": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "is_delete_marker": {
+                    "type": "boolean"
+                },
+                "is_latest": {
+                    "type": "boolean"
+                },
+                "key": {
                     "type": "string"
                 },
-                "public": {
+                "legal_hold": {
                     "type": "boolean"
                 },
-                "updated_at": {
+                "metadata": {
+                    "$ref": "#/definitions/domain.UserMetadata"
+                },
+                "retain_until": {
                     "type": "string"
                 },
-                "user": {
-                    "$ref": "#/definitions/domain.User"
+                "retention_mode": {
+                    "type": "string"
                 },
-                "user_id": {
+                "size": {
+                    "type": "integer"
+                },
+                "sse": {
                     "type": "string"
                 },
-                "versioning": {
-                    "type": "boolean"
+                "sse_customer_key_md5": {
+                    "type": "string"
+                },
+                "storage_path": {
+                    "type": "string"
+                },
+                "stored_size": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "$ref": "#/definitions/domain.ObjectTags"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version_id": {
+                    "type": "string"
                 }
             }
         },
-        "domain.CreateBucketRequest": {
+        "domain.ObjectIdentifier": {
             "type": "object",
             "required": [
-                "name"
+                "key"
             ],
             "properties": {
-                "name": {
+                "key": {
                     "type": "string"
                 },
-                "public": {
-                    "type": "boolean"
-                },
-                "versioning": {
-                    "type": "boolean"
+                "version_id": {
+                    "type": "string"
                 }
             }
         },
-        "domain.ListObjectsResponse": {
+        "domain.ObjectLockConfigurationRequest": {
             "type": "object",
             "properties": {
-                "objects": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.Object"
-                    }
-                },
-                "page": {
-                    "type": "integer"
-                },
-                "page_size": {
-                    "type": "integer"
+                "default_retention": {
+                    "$ref": "#/definitions/domain.DefaultRetention"
                 },
-                "total_count": {
-                    "type": "integer"
+                "object_lock_enabled": {
+                    "type": "boolean"
                 }
             }
         },
-        "domain.LoginRequest": {
+        "domain.ObjectTags": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "domain.PresignRequest": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "key"
             ],
             "properties": {
-                "password": {
-                    "type": "string",
-                    "example": "pass123"
+                "access_key_id": {
+                    "type": "string"
                 },
-                "username": {
+                "content_length": {
+                    "type": "integer"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "key": {
+                    "type": "string"
+                },
+                "method": {
                     "type": "string",
-                    "example": "johndoe"
+                    "enum": [
+                        "GET",
+                        "PUT"
+                    ],
+                    "example": "GET"
+                },
+                "version_id": {
+                    "type": "string"
                 }
             }
         },
-        "domain.Object": {
+        "domain.PresignResponse": {
             "type": "object",
             "properties": {
-                "bucket": {
-                    "$ref": "#/definitions/domain.Bucket"
-                },
-                "bucket_id": {
-                    "type": "string"
-                },
-                "content_type": {
+                "expires_at": {
                     "type": "string"
                 },
-                "created_at": {
-                    "type": "string"
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
-                "etag": {
+                "method": {
                     "type": "string"
                 },
-                "id": {
+                "url": {
                     "type": "string"
-                },
-                "is_latest": {
+                }
+            }
+        },
+        "domain.PutBucketLifecycleRequest": {
+            "type": "object",
+            "required": [
+                "rules"
+            ],
+            "properties": {
+                "rules": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.LifecycleRule"
+                    }
+                }
+            }
+        },
+        "domain.PutObjectLegalHoldRequest": {
+            "type": "object",
+            "properties": {
+                "legal_hold": {
                     "type": "boolean"
                 },
-                "key": {
+                "version_id": {
                     "type": "string"
-                },
+                }
+            }
+        },
+        "domain.PutObjectMetadataRequest": {
+            "type": "object",
+            "properties": {
                 "metadata": {
+                    "$ref": "#/definitions/domain.UserMetadata"
+                },
+                "version_id": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.PutObjectRetentionRequest": {
+            "type": "object",
+            "properties": {
+                "bypass_governance": {
+                    "description": "BypassGovernance allows the bucket owner to shorten or remove\nGOVERNANCE retention",
+                    "type": "boolean"
                 },
-                "size": {
-                    "type": "integer"
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "GOVERNANCE",
+                        "COMPLIANCE"
+                    ]
                 },
-                "storage_path": {
+                "retain_until": {
                     "type": "string"
                 },
-                "updated_at": {
+                "version_id": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.PutObjectTaggingRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "$ref": "#/definitions/domain.ObjectTags"
                 },
                 "version_id": {
                     "type": "string"
@@ -1077,6 +4479,72 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.ScrubFinding": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "quarantined": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.ScrubReport": {
+            "type": "object",
+            "properties": {
+                "blobs_checked": {
+                    "type": "integer"
+                },
+                "bytes_checked": {
+                    "type": "integer"
+                },
+                "corrupt_count": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "findings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ScrubFinding"
+                    }
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "missing_count": {
+                    "type": "integer"
+                },
+                "orphaned_count": {
+                    "type": "integer"
+                },
+                "quarantined": {
+                    "type": "integer"
+                },
+                "repair": {
+                    "type": "boolean"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ScrubRequest": {
+            "type": "object",
+            "properties": {
+                "repair": {
+                    "type": "boolean"
+                }
+            }
+        },
         "domain.UploadObjectResponse": {
             "type": "object",
             "properties": {
@@ -1107,6 +4575,25 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "domain.UserMetadata": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "domain.VersioningStatus": {
+            "type": "string",
+            "enum": [
+                "Unversioned",
+                "Enabled",
+                "Suspended"
+            ],
+            "x-enum-varnames": [
+                "VersioningUnversioned",
+                "VersioningEnabled",
+                "VersioningSuspended"
+            ]
         }
     },
     "securityDefinitions": {
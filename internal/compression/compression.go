@@ -0,0 +1,145 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithms a bucket can compress its objects with.
+const (
+	Zstd = "zstd"
+	Gzip = "gzip"
+)
+
+// readChunk is how much of the source a compressing reader takes in at a
+// time.
+const readChunk = 32 << 10
+
+// Valid reports whether algorithm is a supported compression algorithm.
+func Valid(algorithm string) bool {
+	return algorithm == Zstd || algorithm == Gzip
+}
+
+// incompressibleTypes are media types whose content is compressed already,
+// beyond the image, audio and video families.
+var incompressibleTypes = map[string]bool{
+	"application/gzip":             true,
+	"application/pdf":              true,
+	"application/vnd.rar":          true,
+	"application/x-7z-compressed":  true,
+	"application/x-bzip2":          true,
+	"application/x-compress":       true,
+	"application/x-gzip":           true,
+	"application/x-lz4":            true,
+	"application/x-rar-compressed": true,
+	"application/x-xz":             true,
+	"application/zip":              true,
+	"application/zstd":             true,
+	"font/woff":                    true,
+	"font/woff2":                   true,
+}
+
+// Compressible reports whether content of contentType, sent with
+// contentEncoding, is worth compressing. Content that already has an encoding
+// or whose format is compressed would only cost CPU.
+func Compressible(contentType, contentEncoding string) bool {
+	if contentEncoding != "" && !strings.EqualFold(contentEncoding, "identity") {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	switch {
+	case mediaType == "image/svg+xml", mediaType == "image/bmp":
+		return true
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"):
+		return false
+	default:
+		return !incompressibleTypes[mediaType]
+	}
+}
+
+// NewReader returns the content of r compressed with algorithm. The content
+// is compressed as it is read, without a goroutine, so abandoning the reader
+// leaks nothing.
+func NewReader(r io.Reader, algorithm string) (io.Reader, error) {
+	cr := &compressReader{src: r, chunk: make([]byte, readChunk)}
+
+	var err error
+	switch algorithm {
+	case Zstd:
+		// Encode within Write rather than on background goroutines
+		cr.w, err = zstd.NewWriter(&cr.buf, zstd.WithEncoderConcurrency(1))
+	case Gzip:
+		cr.w = gzip.NewWriter(&cr.buf)
+	default:
+		err = fmt.Errorf("unsupported compression algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// NewDecompressReader returns the content of r, which was compressed with
+// algorithm, decompressed.
+func NewDecompressReader(r io.Reader, algorithm string) (io.ReadCloser, error) {
+	switch algorithm {
+	case Zstd:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	case Gzip:
+		return gzip.NewReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %q", algorithm)
+	}
+}
+
+type compressReader struct {
+	src   io.Reader
+	w     io.WriteCloser
+	buf   bytes.Buffer
+	chunk []byte
+	done  bool
+}
+
+func (r *compressReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+
+		n, err := r.src.Read(r.chunk)
+		if n > 0 {
+			if _, werr := r.w.Write(r.chunk[:n]); werr != nil {
+				return 0, werr
+			}
+		}
+		if err == io.EOF {
+			// Closing flushes the last frame into buf
+			if err := r.w.Close(); err != nil {
+				return 0, err
+			}
+			r.done = true
+		} else if err != nil {
+			// Source errors pass through as-is so callers can tell a body
+			// cut short from a failure here
+			return 0, err
+		}
+	}
+	return r.buf.Read(p)
+}
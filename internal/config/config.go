@@ -5,13 +5,21 @@ import (
 	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Storage  StorageConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Storage    StorageConfig
+	Encryption EncryptionConfig
+	S3         S3Config
+	Multipart  MultipartConfig
+	Lifecycle  LifecycleConfig
+	Scrub      ScrubConfig
+	Admin      AdminConfig
 }
 
 type ServerConfig struct {
@@ -33,16 +41,73 @@ type JWTConfig struct {
 }
 
 type StorageConfig struct {
+	Backend  string
 	BasePath string
+	// Disks are the directories the erasure backend stripes blobs across,
+	// one per shard; DataShards of them hold data and ParityShards parity
+	Disks        []string
+	DataShards   int
+	ParityShards int
+	// GCInterval is how often unreferenced blobs are garbage collected; 0
+	// disables the job
+	GCInterval time.Duration
+}
+
+type EncryptionConfig struct {
+	// MasterKey is a base64 encoded 256-bit key for SSE-S3, used when there
+	// is no keyring file
+	MasterKey string
+	// KeyringFile is a JSON file of master keys that allows rotating them
+	KeyringFile string
+}
+
+type S3Config struct {
+	Port   string
+	Region string
+	// PublicURL is the externally reachable base URL of the S3 API, used
+	// when building presigned URLs
+	PublicURL string
+}
+
+type MultipartConfig struct {
+	// CleanupInterval is how often stale uploads are looked for; 0 disables
+	// the cleanup job
+	CleanupInterval time.Duration
+	// StaleAfter is how long an upload may stay incomplete before it is aborted
+	StaleAfter time.Duration
+}
+
+type LifecycleConfig struct {
+	// Interval is how often lifecycle rules are evaluated; 0 disables the job
+	Interval time.Duration
+	// BatchSize is how many keys are loaded per query while evaluating a bucket
+	BatchSize int
+}
+
+type ScrubConfig struct {
+	// Interval is how often stored content is scrubbed; 0 disables the job
+	Interval time.Duration
+	// Rate is how many bytes per second a scrub may read; 0 does not limit it
+	Rate int
+	// Repair quarantines orphaned content instead of only reporting it
+	Repair bool
+}
+
+type AdminConfig struct {
+	// Users are the usernames allowed to use the admin API
+	Users []string
 }
 
 var Cfg Config
 
 func Load() *Config {
+	ip := getOutboundIP()
+	s3Port := getEnv("S3_PORT", "9000")
+
 	Cfg = Config{
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "9080"),
-			IP:   getOutboundIP(),
+			IP:   ip,
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -56,7 +121,37 @@ func Load() *Config {
 			Secret: getEnv("JWT_SECRET", "your-secret-key"),
 		},
 		Storage: StorageConfig{
-			BasePath: getEnv("STORAGE_PATH", "./storage"),
+			Backend:      getEnv("STORAGE_BACKEND", "filesystem"),
+			BasePath:     getEnv("STORAGE_PATH", "./storage"),
+			GCInterval:   getEnvAsDuration("BLOB_GC_INTERVAL", time.Hour),
+			Disks:        getEnvAsList("STORAGE_DISKS"),
+			DataShards:   getEnvAsInt("STORAGE_DATA_SHARDS", 4),
+			ParityShards: getEnvAsInt("STORAGE_PARITY_SHARDS", 2),
+		},
+		Encryption: EncryptionConfig{
+			MasterKey:   getEnv("SSE_MASTER_KEY", ""),
+			KeyringFile: getEnv("SSE_KEYRING_FILE", ""),
+		},
+		S3: S3Config{
+			Port:      s3Port,
+			Region:    getEnv("S3_REGION", "us-east-1"),
+			PublicURL: getEnv("S3_PUBLIC_URL", "http://"+ip+":"+s3Port),
+		},
+		Multipart: MultipartConfig{
+			CleanupInterval: getEnvAsDuration("MULTIPART_CLEANUP_INTERVAL", time.Hour),
+			StaleAfter:      getEnvAsDuration("MULTIPART_STALE_AFTER", 7*24*time.Hour),
+		},
+		Lifecycle: LifecycleConfig{
+			Interval:  getEnvAsDuration("LIFECYCLE_INTERVAL", time.Hour),
+			BatchSize: getEnvAsInt("LIFECYCLE_BATCH_SIZE", 500),
+		},
+		Scrub: ScrubConfig{
+			Interval: getEnvAsDuration("SCRUB_INTERVAL", 24*time.Hour),
+			Rate:     getEnvAsInt("SCRUB_RATE", 50<<20),
+			Repair:   getEnvAsBool("SCRUB_REPAIR", false),
+		},
+		Admin: AdminConfig{
+			Users: getEnvAsList("ADMIN_USERS"),
 		},
 	}
 
@@ -79,6 +174,35 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma-separated value, dropping empty entries.
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
 func getOutboundIP() string {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
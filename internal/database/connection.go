@@ -27,10 +27,113 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
 }
 
 func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := migrateBucketVersioning(db); err != nil {
+		return err
+	}
+
+	if err := migrateObjectMetadata(db); err != nil {
+		return err
+	}
+
+	err := db.AutoMigrate(
 		&domain.User{},
 		&domain.RefreshToken{},
+		&domain.AccessKey{},
 		&domain.Bucket{},
 		&domain.Object{},
+		&domain.MultipartUpload{},
+		&domain.MultipartPart{},
+		&domain.BucketLifecycle{},
+		&domain.Blob{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateObjectStoredSize(db); err != nil {
+		return err
+	}
+
+	// Object listings walk keys in byte order within a bucket
+	if err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_objects_listing ON objects (bucket_id, key COLLATE "C") WHERE is_latest AND deleted_at IS NULL`).Error; err != nil {
+		return err
+	}
+
+	// A key has at most one latest version
+	return migrateObjectLatestIndex(db)
+}
+
+// migrateObjectLatestIndex adds the unique index on a key's latest version.
+// Writers could race each other before they locked the key, so a key may
+// have several latest versions; all but the newest, by creation time and
+// then ID, are demoted first, as the startup recovery would.
+func migrateObjectLatestIndex(db *gorm.DB) error {
+	if db.Migrator().HasIndex(&domain.Object{}, "idx_objects_latest") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`UPDATE objects o SET is_latest = false
+			WHERE o.is_latest AND o.deleted_at IS NULL AND EXISTS (
+				SELECT 1 FROM objects n
+				WHERE n.bucket_id = o.bucket_id AND n.key = o.key
+					AND n.is_latest AND n.deleted_at IS NULL
+					AND (n.created_at, n.id) > (o.created_at, o.id))`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`CREATE UNIQUE INDEX idx_objects_latest ON objects (bucket_id, key) WHERE is_latest AND deleted_at IS NULL`).Error
+	})
+}
+
+// migrateBucketVersioning converts the old boolean versioning column to the
+// versioning status. The boolean defaulted to true and every write created a
+// new version, so existing buckets carry on as Enabled.
+func migrateBucketVersioning(db *gorm.DB) error {
+	var dataType string
+	err := db.Raw(`SELECT data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = 'buckets' AND column_name = 'versioning'`).Scan(&dataType).Error
+	if err != nil || dataType != "boolean" {
+		return err
+	}
+
+	return db.Exec(`ALTER TABLE buckets ALTER COLUMN versioning DROP DEFAULT, ALTER COLUMN versioning TYPE varchar(16) USING CASE WHEN versioning THEN 'Enabled' ELSE 'Unversioned' END`).Error
+}
+
+// migrateObjectMetadata strips the system keys that used to be mixed into
+// the metadata JSON, so they are no longer served as user metadata. Clients
+// could set those same keys, so none of them is trusted as upload info: the
+// upload info columns stay empty for versions written before they existed.
+// It runs once, on a table from before those columns existed, adding them
+// and stripping the keys in one transaction.
+func migrateObjectMetadata(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&domain.Object{}) || migrator.HasColumn(&domain.Object{}, "original_filename") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`ALTER TABLE objects
+			ADD COLUMN original_filename text,
+			ADD COLUMN uploader_id uuid,
+			ADD COLUMN client_ip text,
+			ADD COLUMN user_agent text`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`UPDATE objects SET
+			metadata = metadata - ARRAY['upload_time', 'file_size', 'content_type', 'content_type_override', 'original_filename', 'uploaded_by', 'uploaded_by_id', 'client_ip', 'user_agent']
+			WHERE jsonb_typeof(metadata) = 'object'`).Error
+	})
+}
+
+// migrateObjectStoredSize fills in the blob size of versions written before
+// it was recorded, which the new column holds as NULL. None of them are
+// compressed, so the blob is the content itself, or with SSE the content plus
+// a 16 byte tag per 64 KiB chunk.
+func migrateObjectStoredSize(db *gorm.DB) error {
+	return db.Exec(`UPDATE objects SET
+		stored_size = CASE WHEN COALESCE(sse, '') = '' THEN size ELSE size + (GREATEST(size, 1) + 65535) / 65536 * 16 END
+		WHERE stored_size IS NULL`).Error
 }
@@ -27,33 +27,345 @@ type RefreshToken struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// AccessKey is an S3 access key pair. The secret has to be kept in a usable
+// form because SigV4 verification recomputes the client's HMAC.
+type AccessKey struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AccessKeyID     string    `json:"access_key_id" gorm:"unique;not null;index"`
+	SecretAccessKey string    `json:"-" gorm:"not null"`
+	UserID          uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User            User      `json:"-" gorm:"foreignKey:UserID"`
+	IsRevoked       bool      `json:"is_revoked" gorm:"default:false;index"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
 type Bucket struct {
-	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Name       string         `json:"name" gorm:"unique;not null"`
-	UserID     uuid.UUID      `json:"user_id" gorm:"type:uuid;not null"`
-	User       User           `json:"user" gorm:"foreignKey:UserID"`
-	Public     bool           `json:"public" gorm:"default:false"`
-	Versioning bool           `json:"versioning" gorm:"default:true"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+	ID         uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name       string           `json:"name" gorm:"unique;not null"`
+	UserID     uuid.UUID        `json:"user_id" gorm:"type:uuid;not null"`
+	User       User             `json:"user" gorm:"foreignKey:UserID"`
+	Public     bool             `json:"public" gorm:"default:false"`
+	Versioning VersioningStatus `json:"versioning" gorm:"type:varchar(16);not null;default:Unversioned"`
+	// ObjectLockEnabled turns on write-once protection for the bucket's
+	// versions; it cannot be turned off again
+	ObjectLockEnabled bool             `json:"object_lock_enabled" gorm:"not null;default:false"`
+	DefaultRetention  DefaultRetention `json:"default_retention" gorm:"embedded;embeddedPrefix:default_retention_"`
+	// Compression is the algorithm new blobs are compressed with when their
+	// content type is worth compressing: empty, zstd or gzip
+	Compression string `json:"compression,omitempty"`
+	// DefaultEncryption is the server-side encryption applied to uploads that
+	// do not ask for any: empty or AES256
+	DefaultEncryption string         `json:"default_encryption,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// VersioningStatus is the versioning state of a bucket. A bucket starts out
+// Unversioned and, once versioning has been enabled, can only move between
+// Enabled and Suspended.
+type VersioningStatus string
+
+const (
+	VersioningUnversioned VersioningStatus = "Unversioned"
+	VersioningEnabled     VersioningStatus = "Enabled"
+	VersioningSuspended   VersioningStatus = "Suspended"
+)
+
+// Object Lock retention modes. GOVERNANCE retention can be lifted by a
+// request that bypasses governance; COMPLIANCE retention cannot be lifted or
+// shortened by anyone.
+const (
+	RetentionGovernance = "GOVERNANCE"
+	RetentionCompliance = "COMPLIANCE"
+)
+
+// DefaultRetention is applied to new versions in a bucket with Object Lock
+// enabled when the upload names no retention of its own. Exactly one of Days
+// and Years is set when Mode is.
+type DefaultRetention struct {
+	Mode  string `json:"mode,omitempty" binding:"omitempty,oneof=GOVERNANCE COMPLIANCE"`
+	Days  int    `json:"days,omitempty" binding:"min=0"`
+	Years int    `json:"years,omitempty" binding:"min=0"`
+}
+
+// ObjectLock is the write-once state of one object version. While the
+// retention period runs or the legal hold is on, the version cannot be
+// deleted or overwritten.
+type ObjectLock struct {
+	RetentionMode string     `json:"retention_mode,omitempty"`
+	RetainUntil   *time.Time `json:"retain_until,omitempty"`
+	LegalHold     bool       `json:"legal_hold" gorm:"not null;default:false"`
+}
+
+// ObjectTags is the key/value tag set of one object version. Unlike metadata,
+// tags can be changed in place and objects can be listed by them.
+type ObjectTags map[string]string
+
+// Object tag limits, as in S3
+const (
+	MaxObjectTags     = 10
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+// UserMetadata is the metadata an uploader attaches to a version through
+// x-amz-meta-* headers. It is stored and returned verbatim, with lower-case
+// keys since header names are case-insensitive.
+type UserMetadata map[string]string
+
+// MaxUserMetadataSize is the most bytes of keys and values a version's user
+// metadata may hold, as in S3.
+const MaxUserMetadataSize = 2048
+
+// UploadInfo is the system metadata recorded with each version: where it
+// came from and who wrote it. It is kept apart from user metadata and never
+// returned to clients.
+type UploadInfo struct {
+	OriginalFilename string     `json:"-"`
+	UploaderID       *uuid.UUID `json:"-" gorm:"type:uuid"`
+	ClientIP         string     `json:"-"`
+	UserAgent        string     `json:"-"`
+}
+
+// Server-side encryption modes
+const (
+	// SSEAlgorithmAES256 is the only algorithm offered, for SSE-S3 and SSE-C
+	SSEAlgorithmAES256 = "AES256"
+	// SSEModeS3 encrypts with a data key wrapped by a server master key
+	SSEModeS3 = "SSE-S3"
+	// SSEModeC encrypts with a data key wrapped by a key the client sends
+	// with every request and the server never stores
+	SSEModeC = "SSE-C"
+)
+
+// ObjectEncryption describes how a version's blob is encrypted at rest. The
+// blob is plaintext when SSE is empty. Otherwise it is encrypted with a
+// per-version data key, stored wrapped by the master key MasterKeyID for
+// SSE-S3 or by the customer key whose MD5 is CustomerKeyMD5 for SSE-C.
+type ObjectEncryption struct {
+	SSE            string `json:"sse,omitempty" gorm:"column:sse"`
+	MasterKeyID    string `json:"-"`
+	WrappedKey     []byte `json:"-"`
+	CustomerKeyMD5 string `json:"sse_customer_key_md5,omitempty"`
 }
 
+// EncryptionRequest asks for a new version to be encrypted at rest. A
+// customer key selects SSE-C, ServerSide set to AES256 selects SSE-S3, and
+// the bucket default applies when neither is given.
+type EncryptionRequest struct {
+	ServerSide  string
+	CustomerKey []byte
+}
+
+// Checksums holds the base64 encoded checksums of a version's content. Only
+// the algorithms given on upload are set.
+type Checksums struct {
+	CRC32  string `json:"checksum_crc32,omitempty"`
+	CRC32C string `json:"checksum_crc32c,omitempty"`
+	SHA1   string `json:"checksum_sha1,omitempty"`
+	SHA256 string `json:"checksum_sha256,omitempty"`
+}
+
+// Object is one version of a key. Size, ETag and Checksums describe the
+// content as uploaded; StoredSize is the size of the blob holding it, once
+// compressed with Compression and encrypted. BlobHash names that blob in the
+// content-addressed store.
 type Object struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Key         string         `json:"key" gorm:"not null"`
-	BucketID    uuid.UUID      `json:"bucket_id" gorm:"type:uuid;not null"`
-	Bucket      Bucket         `json:"bucket" gorm:"foreignKey:BucketID"`
-	VersionID   string         `json:"version_id" gorm:"not null"`
-	Size        int64          `json:"size"`
-	ContentType string         `json:"content_type"`
-	ETag        string         `json:"etag"`
-	StoragePath string         `json:"storage_path"`
-	IsLatest    bool           `json:"is_latest" gorm:"default:true"`
-	Metadata    string         `json:"metadata" gorm:"type:jsonb"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID               uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Key              string    `json:"key" gorm:"not null"`
+	BucketID         uuid.UUID `json:"bucket_id" gorm:"type:uuid;not null"`
+	Bucket           Bucket    `json:"bucket" gorm:"foreignKey:BucketID"`
+	VersionID        string    `json:"version_id" gorm:"not null"`
+	Size             int64     `json:"size"`
+	StoredSize       int64     `json:"stored_size"`
+	ContentType      string    `json:"content_type"`
+	ContentHeaders   `gorm:"embedded"`
+	ETag             string `json:"etag"`
+	Checksums        `gorm:"embedded;embeddedPrefix:checksum_"`
+	ObjectEncryption `gorm:"embedded"`
+	Compression      string `json:"compression,omitempty"`
+	StoragePath      string `json:"storage_path"`
+	BlobHash         string `json:"-" gorm:"size:64;index"`
+	IsLatest         bool   `json:"is_latest" gorm:"default:true"`
+	IsDeleteMarker   bool   `json:"is_delete_marker" gorm:"not null;default:false"`
+	ObjectLock       `gorm:"embedded"`
+	Tags             ObjectTags   `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"`
+	Metadata         UserMetadata `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+	UploadInfo       `gorm:"embedded"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ContentHeaders are the standard HTTP headers given on upload that are
+// stored with the object and returned when it is downloaded.
+type ContentHeaders struct {
+	CacheControl       string `json:"cache_control,omitempty"`
+	ContentDisposition string `json:"content_disposition,omitempty"`
+	ContentEncoding    string `json:"content_encoding,omitempty"`
+}
+
+// NullVersionID is the version ID of objects written while versioning is
+// off. Each such write replaces the previous null version.
+const NullVersionID = "null"
+
+// Blob is a content-addressed blob, keyed by the hex SHA-256 of its stored
+// bytes. Every version stored identically shares it; RefCount counts them,
+// and a blob no version references is garbage collected. Versions written
+// before blobs were shared have no BlobHash and own their blob outright.
+type Blob struct {
+	Hash      string    `json:"hash" gorm:"primaryKey;size:64"`
+	Size      int64     `json:"size"`
+	RefCount  int64     `json:"ref_count" gorm:"not null;default:0;index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BlobInfo describes a blob held by a StorageBackend. Path is relative to the
+// backend root and always uses forward slashes.
+type BlobInfo struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Kinds of ScrubFinding.
+const (
+	// ScrubCorrupt is stored content that no longer matches its hash
+	ScrubCorrupt = "corrupt"
+	// ScrubMissing is a record whose content is gone from storage
+	ScrubMissing = "missing"
+	// ScrubOrphaned is stored content no record points to
+	ScrubOrphaned = "orphaned"
+)
+
+// ScrubReport is what a storage scrub found. The counts are complete, while
+// Findings stops growing at a limit.
+type ScrubReport struct {
+	StartedAt     time.Time      `json:"started_at"`
+	FinishedAt    *time.Time     `json:"finished_at,omitempty"`
+	Repair        bool           `json:"repair"`
+	Error         string         `json:"error,omitempty"`
+	BlobsChecked  int64          `json:"blobs_checked"`
+	BytesChecked  int64          `json:"bytes_checked"`
+	CorruptCount  int64          `json:"corrupt_count"`
+	MissingCount  int64          `json:"missing_count"`
+	OrphanedCount int64          `json:"orphaned_count"`
+	Quarantined   int64          `json:"quarantined"`
+	Findings      []ScrubFinding `json:"findings"`
+}
+
+// ScrubFinding is one problem found by a scrub. Path is the storage path of
+// the content; orphans moved aside by a repairing scrub are Quarantined.
+type ScrubFinding struct {
+	Kind        string `json:"kind"`
+	Path        string `json:"path"`
+	Detail      string `json:"detail,omitempty"`
+	Quarantined bool   `json:"quarantined,omitempty"`
+}
+
+// BucketLifecycle holds the lifecycle rules of a bucket. The rules are
+// always replaced as a whole, so they are stored together in one row.
+type BucketLifecycle struct {
+	BucketID  uuid.UUID       `json:"bucket_id" gorm:"type:uuid;primary_key"`
+	Rules     []LifecycleRule `json:"rules" gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Lifecycle rule statuses
+const (
+	LifecycleEnabled  = "Enabled"
+	LifecycleDisabled = "Disabled"
+)
+
+// MaxLifecycleRules is the most rules a bucket lifecycle may hold.
+const MaxLifecycleRules = 1000
+
+type LifecycleRule struct {
+	ID                             string                          `json:"id" binding:"max=255"`
+	Status                         string                          `json:"status" binding:"required,oneof=Enabled Disabled"`
+	Filter                         LifecycleFilter                 `json:"filter"`
+	Expiration                     *LifecycleExpiration            `json:"expiration,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `json:"noncurrent_version_expiration,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `json:"abort_incomplete_multipart_upload,omitempty"`
+}
+
+// LifecycleFilter selects the objects a rule applies to. All set conditions
+// must hold; a zero size bound is ignored.
+type LifecycleFilter struct {
+	Prefix                string            `json:"prefix,omitempty"`
+	Tags                  map[string]string `json:"tags,omitempty"`
+	ObjectSizeGreaterThan int64             `json:"object_size_greater_than,omitempty" binding:"min=0"`
+	ObjectSizeLessThan    int64             `json:"object_size_less_than,omitempty" binding:"min=0"`
+}
+
+// LifecycleExpiration expires the current version Days after it was written,
+// or removes delete markers that no longer hide any version.
+type LifecycleExpiration struct {
+	Days                      int  `json:"days,omitempty" binding:"min=0"`
+	ExpiredObjectDeleteMarker bool `json:"expired_object_delete_marker,omitempty"`
+}
+
+// NoncurrentVersionExpiration permanently deletes noncurrent versions once
+// they have been noncurrent for NoncurrentDays, keeping the
+// NewerNoncurrentVersions most recent ones regardless of age.
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays          int `json:"noncurrent_days,omitempty" binding:"min=0"`
+	NewerNoncurrentVersions int `json:"newer_noncurrent_versions,omitempty" binding:"min=0"`
+}
+
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `json:"days_after_initiation" binding:"min=1"`
+}
+
+type PutBucketLifecycleRequest struct {
+	Rules []LifecycleRule `json:"rules" binding:"required,min=1,max=1000,dive"`
+}
+
+// MultipartUpload is an in-progress multipart upload. Its ID is the upload ID
+// handed to clients.
+type MultipartUpload struct {
+	ID             uuid.UUID `json:"upload_id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	BucketID       uuid.UUID `json:"bucket_id" gorm:"type:uuid;not null;index"`
+	Bucket         Bucket    `json:"-" gorm:"foreignKey:BucketID"`
+	Key            string    `json:"key" gorm:"not null"`
+	ContentType    string    `json:"content_type"`
+	ContentHeaders `gorm:"embedded"`
+	Metadata       UserMetadata `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+	Tags           ObjectTags   `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"`
+	UploadInfo     `gorm:"embedded"`
+	// ObjectLock is the retention and legal hold requested for the object,
+	// given to it when the upload completes
+	ObjectLock ObjectLock `json:"-" gorm:"embedded"`
+	// Completing is set while a completion assembles the upload, so that a
+	// second one running at the same time is turned away
+	Completing bool `json:"-" gorm:"not null;default:false"`
+	// ObjectEncryption holds the data key that encrypts the parts and the
+	// completed object
+	ObjectEncryption `gorm:"embedded"`
+	CreatedAt        time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// UploadPartOptions holds the digests a part must match, if given.
+type UploadPartOptions struct {
+	ContentMD5 string
+	Checksums  Checksums
+}
+
+type MultipartPart struct {
+	ID          uuid.UUID `json:"-" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UploadID    uuid.UUID `json:"upload_id" gorm:"type:uuid;not null;uniqueIndex:idx_multipart_part"`
+	PartNumber  int       `json:"part_number" gorm:"not null;uniqueIndex:idx_multipart_part"`
+	Size        int64     `json:"size"`
+	ETag        string    `json:"etag"`
+	Checksums   `gorm:"embedded;embeddedPrefix:checksum_"`
+	StoragePath string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 type ObjectVersion struct {
@@ -66,6 +378,56 @@ type CreateBucketRequest struct {
 	Name       string `json:"name" binding:"required"`
 	Public     bool   `json:"public"`
 	Versioning bool   `json:"versioning"`
+	// ObjectLockEnabled creates the bucket with Object Lock, which also
+	// enables versioning
+	ObjectLockEnabled bool              `json:"object_lock_enabled"`
+	DefaultRetention  *DefaultRetention `json:"default_retention,omitempty"`
+	// Compression is the algorithm new blobs are compressed with: zstd or
+	// gzip
+	Compression string `json:"compression,omitempty"`
+}
+
+type BucketEncryptionRequest struct {
+	SSEAlgorithm string `json:"sse_algorithm" binding:"required"`
+}
+
+// BucketCompressionRequest sets the algorithm new blobs are compressed with:
+// zstd or gzip.
+type BucketCompressionRequest struct {
+	Algorithm string `json:"algorithm" binding:"required"`
+}
+
+type ObjectLockConfigurationRequest struct {
+	ObjectLockEnabled bool              `json:"object_lock_enabled"`
+	DefaultRetention  *DefaultRetention `json:"default_retention,omitempty"`
+}
+
+type PutObjectRetentionRequest struct {
+	VersionID   string     `json:"version_id,omitempty"`
+	Mode        string     `json:"mode,omitempty" binding:"omitempty,oneof=GOVERNANCE COMPLIANCE"`
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+	// BypassGovernance allows the bucket owner to shorten or remove
+	// GOVERNANCE retention
+	BypassGovernance bool `json:"bypass_governance,omitempty"`
+}
+
+type PutObjectMetadataRequest struct {
+	VersionID string       `json:"version_id,omitempty"`
+	Metadata  UserMetadata `json:"metadata"`
+}
+
+type PutObjectTaggingRequest struct {
+	VersionID string     `json:"version_id,omitempty"`
+	Tags      ObjectTags `json:"tags"`
+}
+
+type PutObjectLegalHoldRequest struct {
+	VersionID string `json:"version_id,omitempty"`
+	LegalHold bool   `json:"legal_hold"`
+}
+
+type BucketVersioningRequest struct {
+	Status VersioningStatus `json:"status" binding:"required,oneof=Enabled Suspended" example:"Enabled"`
 }
 
 type LoginRequest struct {
@@ -91,11 +453,177 @@ type AuthResponse struct {
 	User         User   `json:"user"`
 }
 
+type CreateAccessKeyResponse struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type PresignRequest struct {
+	Key           string `json:"key" binding:"required"`
+	VersionID     string `json:"version_id"`
+	Method        string `json:"method" binding:"omitempty,oneof=GET PUT" example:"GET"`
+	ExpiresIn     int    `json:"expires_in" example:"3600"`
+	ContentType   string `json:"content_type"`
+	ContentLength int64  `json:"content_length"`
+	AccessKeyID   string `json:"access_key_id"`
+}
+
+type PresignResponse struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// ListObjectsRequest lists the latest object versions in key order. When
+// Delimiter is set, keys that contain it after Prefix are rolled up into a
+// single common prefix, as with S3 "folders".
+type ListObjectsRequest struct {
+	Prefix            string
+	Delimiter         string
+	StartAfter        string
+	ContinuationToken string
+	MaxKeys           int
+	// Tags limits the listing to objects carrying all of these tags
+	Tags ObjectTags
+}
+
 type ListObjectsResponse struct {
-	Objects    []Object `json:"objects"`
-	TotalCount int64    `json:"total_count"`
-	Page       int      `json:"page"`
-	PageSize   int      `json:"page_size"`
+	Objects               []Object `json:"objects"`
+	CommonPrefixes        []string `json:"common_prefixes"`
+	Prefix                string   `json:"prefix"`
+	Delimiter             string   `json:"delimiter,omitempty"`
+	MaxKeys               int      `json:"max_keys"`
+	KeyCount              int      `json:"key_count"`
+	IsTruncated           bool     `json:"is_truncated"`
+	NextContinuationToken string   `json:"next_continuation_token,omitempty"`
+}
+
+type PutObjectOptions struct {
+	ContentType    string
+	ContentHeaders ContentHeaders
+	// ContentMD5 and Checksums are the digests the body must match, if given
+	ContentMD5 string
+	Checksums  Checksums
+	Encryption EncryptionRequest
+	Metadata   UserMetadata
+	Tags       ObjectTags
+	UploadInfo UploadInfo
+	// ObjectLock is the retention and legal hold requested for the new
+	// version; the bucket default retention applies when none is given
+	ObjectLock ObjectLock
+}
+
+type CreateMultipartUploadRequest struct {
+	Key                   string            `json:"key" binding:"required"`
+	ContentType           string            `json:"content_type"`
+	Metadata              map[string]string `json:"metadata"`
+	Tags                  ObjectTags        `json:"tags"`
+	ObjectLockMode        string            `json:"object_lock_mode"`
+	ObjectLockRetainUntil *time.Time        `json:"object_lock_retain_until"`
+	ObjectLockLegalHold   bool              `json:"object_lock_legal_hold"`
+}
+
+type CompletedPart struct {
+	PartNumber int    `json:"part_number" binding:"required"`
+	ETag       string `json:"etag" binding:"required"`
+}
+
+type CompleteMultipartUploadRequest struct {
+	Parts []CompletedPart `json:"parts" binding:"required,min=1,dive"`
+}
+
+// Metadata directives for CopyObject
+const (
+	MetadataDirectiveCopy    = "COPY"
+	MetadataDirectiveReplace = "REPLACE"
+)
+
+// CopyConditions are checked against the source object before copying. A
+// failed condition aborts the copy with ErrPreconditionFailed.
+type CopyConditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+}
+
+type CopyObjectOptions struct {
+	// SourceVersionID selects the version to copy; the latest when empty
+	SourceVersionID string
+	// MetadataDirective is COPY (the default) to keep the source content type,
+	// content headers and metadata, or REPLACE to use ContentType,
+	// ContentHeaders and Metadata instead
+	MetadataDirective string
+	ContentType       string
+	ContentHeaders    ContentHeaders
+	Metadata          UserMetadata
+	// TaggingDirective is COPY (the default) to keep the source tags, or
+	// REPLACE to use Tags instead
+	TaggingDirective string
+	Tags             ObjectTags
+	Conditions       CopyConditions
+	// Encryption applies to the copy; SourceCustomerKey reads an SSE-C source
+	Encryption        EncryptionRequest
+	SourceCustomerKey []byte
+}
+
+type CopyObjectRequest struct {
+	DestinationBucket string            `json:"destination_bucket"`
+	DestinationKey    string            `json:"destination_key" binding:"required"`
+	SourceVersionID   string            `json:"source_version_id"`
+	MetadataDirective string            `json:"metadata_directive" binding:"omitempty,oneof=COPY REPLACE"`
+	ContentType       string            `json:"content_type"`
+	Metadata          map[string]string `json:"metadata"`
+	TaggingDirective  string            `json:"tagging_directive" binding:"omitempty,oneof=COPY REPLACE"`
+	Tags              ObjectTags        `json:"tags"`
+	IfMatch           string            `json:"if_match"`
+	IfNoneMatch       string            `json:"if_none_match"`
+	IfModifiedSince   *time.Time        `json:"if_modified_since"`
+	IfUnmodifiedSince *time.Time        `json:"if_unmodified_since"`
+}
+
+// MaxDeleteObjects is the most keys a single DeleteObjects request may name.
+const MaxDeleteObjects = 1000
+
+type ObjectIdentifier struct {
+	Key       string `json:"key" binding:"required"`
+	VersionID string `json:"version_id,omitempty"`
+}
+
+// ScrubRequest starts a storage scrub. Repair quarantines the orphaned
+// content it finds.
+type ScrubRequest struct {
+	Repair bool `json:"repair"`
+}
+
+type DeleteObjectsRequest struct {
+	Objects []ObjectIdentifier `json:"objects" binding:"required,min=1,max=1000,dive"`
+	// Quiet omits successfully deleted keys from the response
+	Quiet bool `json:"quiet"`
+	// BypassGovernance allows the bucket owner to delete versions under
+	// GOVERNANCE retention
+	BypassGovernance bool `json:"bypass_governance"`
+}
+
+type DeletedObject struct {
+	Key                   string `json:"key"`
+	VersionID             string `json:"version_id,omitempty"`
+	DeleteMarker          bool   `json:"delete_marker,omitempty"`
+	DeleteMarkerVersionID string `json:"delete_marker_version_id,omitempty"`
+}
+
+type DeleteObjectError struct {
+	Key       string `json:"key"`
+	VersionID string `json:"version_id,omitempty"`
+	Message   string `json:"error"`
+	Err       error  `json:"-"`
+}
+
+type DeleteObjectsResponse struct {
+	Deleted []DeletedObject     `json:"deleted"`
+	Errors  []DeleteObjectError `json:"errors"`
 }
 
 type UploadObjectResponse struct {
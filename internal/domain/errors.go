@@ -0,0 +1,79 @@
+package domain
+
+import "errors"
+
+// Lookup and access errors
+var (
+	ErrBucketNotFound      = errors.New("bucket not found")
+	ErrBucketAlreadyExists = errors.New("bucket already exists")
+	ErrObjectNotFound      = errors.New("object not found")
+	ErrDeleteMarker        = errors.New("the requested version is a delete marker")
+	ErrAccessDenied        = errors.New("access denied")
+	ErrAccessKeyNotFound   = errors.New("access key not found")
+	ErrNoActiveAccessKey   = errors.New("no active access key; create one first")
+	ErrInvalidExpiry       = errors.New("expires_in must be between 1 second and 7 days")
+	ErrInvalidContinuation = errors.New("invalid continuation token")
+	ErrPreconditionFailed  = errors.New("precondition failed")
+	ErrCopyToItself        = errors.New("copying an object onto itself requires the REPLACE metadata directive")
+	ErrTooManyKeys         = errors.New("a delete request may name at most 1000 keys")
+	ErrInvalidVersioning   = errors.New("versioning status must be Enabled or Suspended")
+	ErrInvalidCompression  = errors.New("compression algorithm must be zstd or gzip")
+	ErrLifecycleNotFound   = errors.New("lifecycle configuration not found")
+	ErrInvalidLifecycle    = errors.New("invalid lifecycle configuration")
+	ErrInvalidTag          = errors.New("invalid tag set")
+	ErrMetadataTooLarge    = errors.New("user metadata may not exceed 2 KB")
+)
+
+// Upload body errors
+var (
+	ErrInvalidDigest   = errors.New("Content-MD5 must be a base64 encoded MD5 digest")
+	ErrInvalidChecksum = errors.New("x-amz-checksum values must be base64 encoded digests of the algorithm's size")
+	ErrBadDigest       = errors.New("the body does not match the given digest")
+	ErrIncompleteBody  = errors.New("the body is shorter than Content-Length")
+)
+
+// Encryption errors
+var (
+	ErrEncryptionNotConfigured = errors.New("server-side encryption needs a master key; set SSE_MASTER_KEY or SSE_KEYRING_FILE")
+	ErrInvalidEncryption       = errors.New("the only server-side encryption algorithm supported is AES256")
+	ErrInvalidCustomerKey      = errors.New("SSE-C needs algorithm AES256, a base64 encoded 256-bit key and its base64 MD5")
+	ErrCustomerKeyRequired     = errors.New("the object is encrypted with a customer key, which must be given to read it")
+	ErrCustomerKeyMismatch     = errors.New("the customer key does not match the one the object was encrypted with")
+	ErrSSECMultipart           = errors.New("SSE-C is not supported for multipart uploads")
+	ErrMasterKeyNotFound       = errors.New("master key not found in the keyring")
+	ErrDecryptionFailed        = errors.New("the stored object could not be decrypted")
+)
+
+// Object Lock errors
+var (
+	ErrObjectLocked            = errors.New("the object version is protected by Object Lock")
+	ErrObjectLockNotEnabled    = errors.New("the bucket does not have Object Lock enabled")
+	ErrInvalidRetention        = errors.New("invalid retention: mode and a future retain-until date are required together")
+	ErrObjectLockVersioning    = errors.New("Object Lock requires versioning to be enabled on the bucket")
+	ErrInvalidDefaultRetention = errors.New("default retention needs a mode and either days or years")
+)
+
+// Multipart upload errors
+var (
+	ErrUploadNotFound    = errors.New("multipart upload not found")
+	ErrInvalidPartNumber = errors.New("part number must be between 1 and 10000")
+	ErrInvalidPart       = errors.New("one or more of the specified parts could not be found or its ETag does not match")
+	ErrInvalidPartOrder  = errors.New("parts must be listed in ascending order by part number")
+	ErrNoParts           = errors.New("at least one part must be listed to complete an upload")
+	ErrEntityTooSmall    = errors.New("every part except the last must be at least 5 MiB")
+)
+
+// Request signing errors
+var (
+	ErrSignatureDoesNotMatch = errors.New("signature does not match")
+	ErrContentSHA256Mismatch = errors.New("content SHA-256 does not match x-amz-content-sha256")
+	ErrIncompleteChunkedBody = errors.New("incomplete aws-chunked body")
+	ErrDecodedLengthMismatch = errors.New("the aws-chunked body does not match x-amz-decoded-content-length")
+)
+
+// Storage errors
+var (
+	ErrBlobNotFound = errors.New("blob not found")
+	ErrInvalidPath  = errors.New("invalid storage path")
+	ErrScrubRunning = errors.New("a storage scrub is already running")
+)
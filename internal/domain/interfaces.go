@@ -1,8 +1,9 @@
 package domain
 
 import (
+	"context"
 	"io"
-	"mime/multipart"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -22,6 +23,13 @@ type RefreshTokenRepository interface {
 	CleanupExpiredTokens() error
 }
 
+type AccessKeyRepository interface {
+	Create(key *AccessKey) error
+	GetByAccessKeyID(accessKeyID string) (*AccessKey, error)
+	GetByUserID(userID uuid.UUID) ([]AccessKey, error)
+	Revoke(userID uuid.UUID, accessKeyID string) error
+}
+
 type BucketRepository interface {
 	Create(bucket *Bucket) error
 	GetByName(name string) (*Bucket, error)
@@ -31,15 +39,84 @@ type BucketRepository interface {
 	Delete(id uuid.UUID) error
 }
 
+type LifecycleRepository interface {
+	Get(bucketID uuid.UUID) (*BucketLifecycle, error)
+	List() ([]BucketLifecycle, error)
+	// Save creates or replaces the bucket's lifecycle
+	Save(lifecycle *BucketLifecycle) error
+	Delete(bucketID uuid.UUID) error
+}
+
 type ObjectRepository interface {
 	Create(object *Object) error
 	GetByKey(bucketID uuid.UUID, key string) (*Object, error)
 	GetByKeyAndVersion(bucketID uuid.UUID, key, versionID string) (*Object, error)
 	GetVersions(bucketID uuid.UUID, key string) ([]Object, error)
-	List(bucketID uuid.UUID, prefix string, page, pageSize int) ([]Object, int64, error)
+	// List returns up to limit latest versions whose key starts with prefix,
+	// sorts after startAfter and that carry every tag in tags, in byte order
+	List(bucketID uuid.UUID, prefix, startAfter string, tags ObjectTags, limit int) ([]Object, error)
 	Update(object *Object) error
+	// Delete removes a version for good; the row is not kept soft-deleted
 	Delete(id uuid.UUID) error
 	MarkAsNotLatest(bucketID uuid.UUID, key string) error
+	// ListKeys returns up to limit distinct keys that have any version,
+	// current or not, starting with prefix and sorting after startAfter
+	ListKeys(bucketID uuid.UUID, prefix, startAfter string, limit int) ([]string, error)
+	// LockKey serializes writers to a key until the surrounding transaction
+	// ends; it only makes sense within Transaction
+	LockKey(bucketID uuid.UUID, key string) error
+	// ListStaleWrappedKeys returns up to limit SSE-S3 versions whose data key
+	// is wrapped by a master key other than activeKeyID
+	ListStaleWrappedKeys(activeKeyID string, limit int) ([]Object, error)
+	UpdateWrappedKey(id uuid.UUID, masterKeyID string, wrappedKey []byte) error
+	// AcquireBlob adds a reference to the blob hash, recording it with size
+	// when it is not known yet; ReleaseBlob drops one
+	AcquireBlob(hash string, size int64) error
+	ReleaseBlob(hash string) error
+	// ListUnshared returns up to limit versions, ordered by ID and after
+	// afterID, that own their content outright rather than sharing a blob
+	ListUnshared(afterID uuid.UUID, limit int) ([]Object, error)
+	// HasStoragePath reports whether a version owns the content at
+	// storagePath
+	HasStoragePath(storagePath string) (bool, error)
+	// RepairLatest makes the newest version of every key that has no latest
+	// version, or more than one, its only latest version, and returns how
+	// many versions it changed
+	RepairLatest() (int64, error)
+	// Transaction runs fn against a repository bound to a single database
+	// transaction, committed when fn returns nil
+	Transaction(fn func(repo ObjectRepository) error) error
+}
+
+type BlobRepository interface {
+	GetByHash(hash string) (*Blob, error)
+	// List returns up to limit blobs, ordered by hash and after afterHash
+	List(afterHash string, limit int) ([]Blob, error)
+	// ListUnreferenced returns up to limit blobs that no version references
+	ListUnreferenced(limit int) ([]Blob, error)
+	// DeleteUnreferenced removes the blob's record unless it has gained a
+	// reference again
+	DeleteUnreferenced(hash string) error
+}
+
+type MultipartUploadRepository interface {
+	Create(upload *MultipartUpload) error
+	GetByID(id uuid.UUID) (*MultipartUpload, error)
+	ListByBucket(bucketID uuid.UUID) ([]MultipartUpload, error)
+	ListStale(before time.Time) ([]MultipartUpload, error)
+	Delete(id uuid.UUID) error
+	// Claim marks an upload as being completed. It fails with
+	// ErrUploadNotFound when the upload is gone or already claimed.
+	Claim(id uuid.UUID) error
+	// Unclaim lets an upload whose completion failed be completed again
+	Unclaim(id uuid.UUID) error
+	GetPart(uploadID uuid.UUID, partNumber int) (*MultipartPart, error)
+	SavePart(part *MultipartPart) error
+	ListParts(uploadID uuid.UUID) ([]MultipartPart, error)
+	// ListStaleWrappedKeys returns up to limit SSE-S3 uploads whose data key
+	// is wrapped by a master key other than activeKeyID
+	ListStaleWrappedKeys(activeKeyID string, limit int) ([]MultipartUpload, error)
+	UpdateWrappedKey(id uuid.UUID, masterKeyID string, wrappedKey []byte) error
 }
 
 // Use case interfaces
@@ -50,20 +127,144 @@ type AuthUseCase interface {
 	ValidateToken(token string) (*User, error)
 	RevokeRefreshToken(refreshToken string) error
 	RevokeAllUserTokens(userID uuid.UUID) error
+	CreateAccessKey(userID uuid.UUID) (*CreateAccessKeyResponse, error)
+	ListAccessKeys(userID uuid.UUID) ([]AccessKey, error)
+	RevokeAccessKey(userID uuid.UUID, accessKeyID string) error
+	GetAccessKey(accessKeyID string) (*AccessKey, error)
 }
 
 type BucketUseCase interface {
 	CreateBucket(userID uuid.UUID, req *CreateBucketRequest) (*Bucket, error)
 	GetBucket(userID uuid.UUID, name string) (*Bucket, error)
+	// GetOwnedBucket looks up a bucket for a change to it or its objects,
+	// which only the owner may make: public buckets are open to reads only
+	GetOwnedBucket(userID uuid.UUID, name string) (*Bucket, error)
 	ListBuckets(userID uuid.UUID) ([]Bucket, error)
 	DeleteBucket(userID uuid.UUID, name string) error
+	// PutBucketVersioning moves the bucket to Enabled or Suspended; only the
+	// owner may change it
+	PutBucketVersioning(userID uuid.UUID, name string, status VersioningStatus) (*Bucket, error)
+	// PutObjectLockConfiguration enables Object Lock on a versioned bucket
+	// and sets its default retention; only the owner may change it
+	PutObjectLockConfiguration(userID uuid.UUID, name string, req *ObjectLockConfigurationRequest) (*Bucket, error)
+	// PutBucketEncryption sets the default server-side encryption of new
+	// uploads, AES256, or removes it when algorithm is empty; only the owner
+	// may change it
+	PutBucketEncryption(userID uuid.UUID, name, algorithm string) (*Bucket, error)
+	// PutBucketCompression sets the algorithm new blobs are compressed with,
+	// zstd or gzip, or turns compression off when algorithm is empty; only the
+	// owner may change it
+	PutBucketCompression(userID uuid.UUID, name, algorithm string) (*Bucket, error)
+}
+
+type LifecycleUseCase interface {
+	GetBucketLifecycle(userID uuid.UUID, bucketName string) (*BucketLifecycle, error)
+	PutBucketLifecycle(userID uuid.UUID, bucketName string, rules []LifecycleRule) (*BucketLifecycle, error)
+	DeleteBucketLifecycle(userID uuid.UUID, bucketName string) error
+	// ApplyLifecycle evaluates the rules of every bucket once, expiring
+	// objects and aborting uploads that are due
+	ApplyLifecycle() error
 }
 
 type ObjectUseCase interface {
-	UploadObject(bucketID uuid.UUID, key string, file multipart.File, header *multipart.FileHeader, metadata map[string]string) (*UploadObjectResponse, error)
-	GetObject(bucketID uuid.UUID, key string) (*Object, io.ReadCloser, error)
-	GetObjectVersion(bucketID uuid.UUID, key, versionID string) (*Object, io.ReadCloser, error)
-	ListObjects(bucketID uuid.UUID, prefix string, page, pageSize int) (*ListObjectsResponse, error)
+	PutObject(bucketID uuid.UUID, key string, body io.Reader, opts *PutObjectOptions) (*UploadObjectResponse, error)
+	// GetObject and GetObjectVersion open the content, decrypting it if
+	// needed; SSE-C versions need the customerKey they were written with
+	GetObject(bucketID uuid.UUID, key string, customerKey []byte) (*Object, io.ReadSeekCloser, error)
+	GetObjectVersion(bucketID uuid.UUID, key, versionID string, customerKey []byte) (*Object, io.ReadSeekCloser, error)
+	// HeadObject returns the latest version, or versionID when set, without
+	// opening its content
+	HeadObject(bucketID uuid.UUID, key, versionID string) (*Object, error)
+	CopyObject(srcBucketID uuid.UUID, srcKey string, dstBucketID uuid.UUID, dstKey string, opts *CopyObjectOptions) (*UploadObjectResponse, error)
+	ListObjects(bucketID uuid.UUID, req *ListObjectsRequest) (*ListObjectsResponse, error)
 	ListObjectVersions(bucketID uuid.UUID, key string) ([]Object, error)
-	DeleteObject(bucketID uuid.UUID, key string) error
+	// RestoreObjectVersion makes versionID the latest version again by copying
+	// it on top of the history
+	RestoreObjectVersion(bucketID uuid.UUID, key, versionID string) (*UploadObjectResponse, error)
+	// DeleteObject removes versionID permanently, or without one places a
+	// delete marker when the bucket is versioned. Versions under GOVERNANCE
+	// retention are only removed with bypassGovernance.
+	DeleteObject(bucketID uuid.UUID, key, versionID string, bypassGovernance bool) (*DeletedObject, error)
+	// DeleteObjects deletes a batch of keys or versions in one transaction and
+	// reports the outcome per key
+	DeleteObjects(bucketID uuid.UUID, objects []ObjectIdentifier, bypassGovernance bool) (*DeleteObjectsResponse, error)
+	// PutObjectRetention sets the retention of the latest version, or of
+	// versionID when set. Retention can always be extended; shortening or
+	// removing it needs GOVERNANCE mode and bypassGovernance.
+	PutObjectRetention(bucketID uuid.UUID, key, versionID, mode string, retainUntil *time.Time, bypassGovernance bool) (*Object, error)
+	PutObjectLegalHold(bucketID uuid.UUID, key, versionID string, legalHold bool) (*Object, error)
+	// PutObjectTagging replaces the tag set of the latest version, or of
+	// versionID when set; an empty set removes all tags
+	PutObjectTagging(bucketID uuid.UUID, key, versionID string, tags ObjectTags) (*Object, error)
+	// PutObjectMetadata replaces the user metadata of the latest version, or
+	// of versionID when set, without rewriting its content
+	PutObjectMetadata(bucketID uuid.UUID, key, versionID string, metadata UserMetadata) (*Object, error)
+}
+
+type MultipartUseCase interface {
+	CreateMultipartUpload(bucketID uuid.UUID, key string, opts *PutObjectOptions) (*MultipartUpload, error)
+	UploadPart(bucketID, uploadID uuid.UUID, partNumber int, body io.Reader, opts *UploadPartOptions) (*MultipartPart, error)
+	ListParts(bucketID, uploadID uuid.UUID) (*MultipartUpload, []MultipartPart, error)
+	ListMultipartUploads(bucketID uuid.UUID) ([]MultipartUpload, error)
+	CompleteMultipartUpload(bucketID, uploadID uuid.UUID, parts []CompletedPart) (*UploadObjectResponse, error)
+	AbortMultipartUpload(bucketID, uploadID uuid.UUID) error
+	AbortStaleUploads(olderThan time.Duration) error
+}
+
+type KeyRotationUseCase interface {
+	// RewrapKeys re-wraps every SSE-S3 data key that is not wrapped by the
+	// active master key and returns how many were re-wrapped
+	RewrapKeys() (int, error)
+}
+
+type BlobUseCase interface {
+	// CollectGarbage deletes the blobs no version references any more and
+	// returns how many it deleted
+	CollectGarbage() (int, error)
+}
+
+type ScrubUseCase interface {
+	// Scrub checks all stored content against its hashes and the records
+	// pointing to it, quarantining orphaned content when repair is set
+	Scrub(repair bool) (*ScrubReport, error)
+	// StartScrub runs Scrub in the background
+	StartScrub(repair bool) error
+	// Report returns the report of the running scrub, or else of the last
+	// one; nil before the first
+	Report() *ScrubReport
+}
+
+type RecoveryUseCase interface {
+	// Recover cleans up after a crash: it removes content that was never
+	// committed and repairs keys left without a single latest version. It
+	// runs at startup, before any request is served
+	Recover() error
+}
+
+type PresignUseCase interface {
+	PresignObject(userID uuid.UUID, bucket *Bucket, req *PresignRequest) (*PresignResponse, error)
+}
+
+// KeyManager wraps the data keys of SSE-S3 objects with master keys.
+type KeyManager interface {
+	// ActiveKeyID names the master key WrapKey uses
+	ActiveKeyID() string
+	WrapKey(dataKey []byte) (masterKeyID string, wrapped []byte, err error)
+	UnwrapKey(masterKeyID string, wrapped []byte) ([]byte, error)
+}
+
+// Storage interfaces
+type StorageBackend interface {
+	Put(ctx context.Context, path string, r io.Reader) (int64, error)
+	Get(ctx context.Context, path string) (io.ReadCloser, error)
+	// GetRange reads length bytes starting at offset; a negative length
+	// reads to the end of the blob
+	GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+	// Copy duplicates a blob without streaming it through the caller
+	Copy(ctx context.Context, srcPath, dstPath string) (int64, error)
+	// Rename moves a blob to dstPath, replacing any blob already there
+	Rename(ctx context.Context, srcPath, dstPath string) error
+	Delete(ctx context.Context, path string) error
+	Stat(ctx context.Context, path string) (*BlobInfo, error)
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
 }
@@ -0,0 +1,107 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"s3-like/internal/config"
+	"s3-like/internal/domain"
+)
+
+// DefaultMasterKeyID names the master key given directly in the
+// configuration rather than through a keyring file.
+const DefaultMasterKeyID = "default"
+
+// Keyring holds the master keys that wrap the data keys of SSE-S3 objects.
+// New data keys are wrapped with the active key; the others are kept to
+// unwrap existing objects until they have been re-wrapped. A nil Keyring
+// means server-side encryption is not configured.
+type Keyring struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// keyringFile is the format of the keyring file: base64 encoded 256-bit
+// keys by ID, and the ID of the key new objects use.
+//
+//	{"active_key_id": "2026-10", "keys": {"2026-01": "...", "2026-10": "..."}}
+type keyringFile struct {
+	ActiveKeyID string            `json:"active_key_id"`
+	Keys        map[string]string `json:"keys"`
+}
+
+// LoadKeyring loads the keyring file, or else the single master key, named
+// in cfg. It returns nil when neither is set.
+func LoadKeyring(cfg config.EncryptionConfig) (*Keyring, error) {
+	var file keyringFile
+	switch {
+	case cfg.KeyringFile != "":
+		data, err := os.ReadFile(cfg.KeyringFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keyring: %w", err)
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse keyring: %w", err)
+		}
+	case cfg.MasterKey != "":
+		file = keyringFile{
+			ActiveKeyID: DefaultMasterKeyID,
+			Keys:        map[string]string{DefaultMasterKeyID: cfg.MasterKey},
+		}
+	default:
+		return nil, nil
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != KeySize {
+			return nil, fmt.Errorf("master key %q must be a base64 encoded 256-bit key", id)
+		}
+		keys[id] = key
+	}
+	return NewKeyring(file.ActiveKeyID, keys)
+}
+
+func NewKeyring(activeID string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("active master key %q is not in the keyring", activeID)
+	}
+	return &Keyring{activeID: activeID, keys: keys}, nil
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.activeID
+}
+
+func (k *Keyring) WrapKey(dataKey []byte) (string, []byte, error) {
+	if k == nil {
+		return "", nil, domain.ErrEncryptionNotConfigured
+	}
+
+	wrapped, err := WrapKey(k.keys[k.activeID], dataKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return k.activeID, wrapped, nil
+}
+
+func (k *Keyring) UnwrapKey(masterKeyID string, wrapped []byte) ([]byte, error) {
+	if k == nil {
+		return nil, domain.ErrEncryptionNotConfigured
+	}
+
+	masterKey, ok := k.keys[masterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrMasterKeyNotFound, masterKeyID)
+	}
+	dataKey, err := UnwrapKey(masterKey, wrapped)
+	if err != nil {
+		return nil, domain.ErrDecryptionFailed
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,207 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"s3-like/internal/domain"
+)
+
+// Encrypted blobs are a sequence of chunks, each holding up to ChunkSize
+// bytes of plaintext sealed with AES-256-GCM. Every object has its own data
+// key, so the chunk index serves as the nonce. The last chunk is sealed with
+// a different additional data byte, which makes a truncated blob fail to
+// decrypt instead of reading as a shorter object. An empty object is a
+// single empty chunk.
+const (
+	ChunkSize     = 64 << 10
+	chunkOverhead = 16 // GCM tag
+)
+
+// EncryptedSize returns the size of the blob holding size bytes of
+// plaintext.
+func EncryptedSize(size int64) int64 {
+	return size + chunkCount(size)*chunkOverhead
+}
+
+// PlaintextSize is the inverse of EncryptedSize: the plaintext size of a
+// blob of size bytes.
+func PlaintextSize(size int64) int64 {
+	chunks := (size + ChunkSize + chunkOverhead - 1) / (ChunkSize + chunkOverhead)
+	if chunks == 0 {
+		chunks = 1
+	}
+	return size - chunks*chunkOverhead
+}
+
+// ChunkOffset returns the blob offset at which chunk starts.
+func ChunkOffset(chunk int64) int64 {
+	return chunk * (ChunkSize + chunkOverhead)
+}
+
+func chunkCount(size int64) int64 {
+	if size == 0 {
+		return 1
+	}
+	return (size + ChunkSize - 1) / ChunkSize
+}
+
+func chunkNonce(aead cipher.AEAD, chunk int64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], uint64(chunk))
+	return nonce
+}
+
+func chunkAdditionalData(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type encryptReader struct {
+	src    *bufio.Reader
+	aead   cipher.AEAD
+	chunk  int64
+	plain  []byte
+	sealed []byte
+	out    []byte
+	done   bool
+}
+
+// NewEncryptReader returns a reader of the encrypted blob for the plaintext
+// read from r. Errors from r are passed through unchanged.
+func NewEncryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptReader{
+		src:    bufio.NewReader(r),
+		aead:   aead,
+		plain:  make([]byte, ChunkSize),
+		sealed: make([]byte, 0, ChunkSize+chunkOverhead),
+	}, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) sealChunk() error {
+	n, err := fill(r.src, r.plain)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	// A full chunk is the last one only if nothing follows it
+	final := err == io.EOF
+	if !final {
+		if _, err := r.src.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	r.out = r.aead.Seal(r.sealed[:0], chunkNonce(r.aead, r.chunk), r.plain[:n], chunkAdditionalData(final))
+	r.chunk++
+	r.done = final
+	return nil
+}
+
+// fill reads into buf until it is full or r fails, unlike io.ReadFull
+// leaving io.ErrUnexpectedEOF from r distinguishable from a short read.
+func fill(r io.Reader, buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		m, err := r.Read(buf[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+type decryptReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	chunk  int64
+	last   int64
+	size   int64
+	sealed []byte
+	out    []byte
+}
+
+// NewDecryptReader returns a reader of the plaintext of an encrypted blob
+// holding size bytes of plaintext. r must be positioned at the start of
+// chunk first, see ChunkOffset.
+func NewDecryptReader(r io.Reader, key []byte, first, size int64) (io.Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		src:    r,
+		aead:   aead,
+		chunk:  first,
+		last:   chunkCount(size) - 1,
+		size:   size,
+		sealed: make([]byte, ChunkSize+chunkOverhead),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.chunk > r.last {
+			return 0, io.EOF
+		}
+		if err := r.openChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) openChunk() error {
+	sealed := r.sealed
+	final := r.chunk == r.last
+	if final {
+		sealed = sealed[:r.size-r.last*ChunkSize+chunkOverhead]
+	}
+
+	if _, err := io.ReadFull(r.src, sealed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return domain.ErrDecryptionFailed
+		}
+		return err
+	}
+
+	plain, err := r.aead.Open(sealed[:0], chunkNonce(r.aead, r.chunk), sealed, chunkAdditionalData(final))
+	if err != nil {
+		return domain.ErrDecryptionFailed
+	}
+
+	r.out = plain
+	r.chunk++
+	return nil
+}
@@ -0,0 +1,193 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"s3-like/internal/domain"
+	"testing"
+)
+
+var chunkSizes = []struct {
+	name string
+	size int64
+}{
+	{"empty", 0},
+	{"one byte", 1},
+	{"just under a chunk", ChunkSize - 1},
+	{"one chunk", ChunkSize},
+	{"just over a chunk", ChunkSize + 1},
+	{"several chunks", 3*ChunkSize + ChunkSize/2},
+}
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	return key
+}
+
+func testPlaintext(size int64) []byte {
+	data := make([]byte, size)
+	rand.New(rand.NewSource(size)).Read(data)
+	return data
+}
+
+func encrypt(t *testing.T, key, plain []byte) []byte {
+	t.Helper()
+
+	r, err := NewEncryptReader(bytes.NewReader(plain), key)
+	if err != nil {
+		t.Fatalf("NewEncryptReader: %v", err)
+	}
+	blob, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	return blob
+}
+
+func decrypt(key, blob []byte, first, size int64) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(blob), key, first, size)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEncryptedSize(t *testing.T) {
+	for _, tt := range chunkSizes {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.size + chunkCount(tt.size)*chunkOverhead
+			if got := EncryptedSize(tt.size); got != want {
+				t.Errorf("EncryptedSize(%d) = %d, want %d", tt.size, got, want)
+			}
+			if got := PlaintextSize(EncryptedSize(tt.size)); got != tt.size {
+				t.Errorf("PlaintextSize(EncryptedSize(%d)) = %d", tt.size, got)
+			}
+
+			blob := encrypt(t, testKey(t), testPlaintext(tt.size))
+			if int64(len(blob)) != EncryptedSize(tt.size) {
+				t.Errorf("blob is %d bytes, want %d", len(blob), EncryptedSize(tt.size))
+			}
+		})
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	for _, tt := range chunkSizes {
+		t.Run(tt.name, func(t *testing.T) {
+			key := testKey(t)
+			plain := testPlaintext(tt.size)
+			blob := encrypt(t, key, plain)
+
+			if bytes.Contains(blob, plain) && tt.size > 0 {
+				t.Errorf("blob contains the plaintext")
+			}
+
+			got, err := decrypt(key, blob, 0, tt.size)
+			if err != nil {
+				t.Fatalf("decrypt: %v", err)
+			}
+			if !bytes.Equal(got, plain) {
+				t.Errorf("decrypted %d bytes that differ from the %d byte plaintext", len(got), len(plain))
+			}
+		})
+	}
+}
+
+func TestDecryptFromChunk(t *testing.T) {
+	const size = 3*ChunkSize + 100
+	key := testKey(t)
+	plain := testPlaintext(size)
+	blob := encrypt(t, key, plain)
+
+	for first := int64(0); first < chunkCount(size); first++ {
+		got, err := decrypt(key, blob[ChunkOffset(first):], first, size)
+		if err != nil {
+			t.Fatalf("decrypt from chunk %d: %v", first, err)
+		}
+		if !bytes.Equal(got, plain[first*ChunkSize:]) {
+			t.Errorf("decrypt from chunk %d returned the wrong %d bytes", first, len(got))
+		}
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	const size = 2*ChunkSize + 100
+	key := testKey(t)
+	blob := encrypt(t, key, testPlaintext(size))
+
+	flip := func(i int64) []byte {
+		tampered := bytes.Clone(blob)
+		tampered[i] ^= 1
+		return tampered
+	}
+	swapped := bytes.Clone(blob)
+	copy(swapped, blob[ChunkOffset(1):ChunkOffset(2)])
+	copy(swapped[ChunkOffset(1):], blob[:ChunkOffset(1)])
+
+	tests := []struct {
+		name  string
+		key   []byte
+		blob  []byte
+		first int64
+		size  int64
+	}{
+		{name: "wrong key", key: testKey(t), blob: blob, size: size},
+		{name: "flipped first chunk", key: key, blob: flip(10), size: size},
+		{name: "flipped tag", key: key, blob: flip(ChunkOffset(1) - 1), size: size},
+		{name: "flipped last chunk", key: key, blob: flip(int64(len(blob)) - 50), size: size},
+		{name: "chunks swapped", key: key, blob: swapped, size: size},
+		{name: "truncated", key: key, blob: blob[:len(blob)-1], size: size},
+		{name: "last chunk dropped", key: key, blob: blob[:ChunkOffset(2)], size: size},
+		// Dropping whole chunks must not pass for a shorter object
+		{name: "read as a shorter object", key: key, blob: blob[:ChunkOffset(2)], size: 2 * ChunkSize},
+		{name: "wrong first chunk", key: key, blob: blob[ChunkOffset(1):], first: 2, size: size},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decrypt(tt.key, tt.blob, tt.first, tt.size); !errors.Is(err, domain.ErrDecryptionFailed) {
+				t.Errorf("decrypt = %v, want ErrDecryptionFailed", err)
+			}
+		})
+	}
+}
+
+func TestInvalidKeySize(t *testing.T) {
+	for _, size := range []int{0, 16, 31, 33} {
+		key := make([]byte, size)
+		if _, err := NewEncryptReader(bytes.NewReader(nil), key); err == nil {
+			t.Errorf("NewEncryptReader accepted a %d byte key", size)
+		}
+		if _, err := NewDecryptReader(bytes.NewReader(nil), key, 0, 0); err == nil {
+			t.Errorf("NewDecryptReader accepted a %d byte key", size)
+		}
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	kek := testKey(t)
+	key := testKey(t)
+
+	wrapped, err := WrapKey(kek, key)
+	if err != nil {
+		t.Fatalf("WrapKey: %v", err)
+	}
+	got, err := UnwrapKey(kek, wrapped)
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("UnwrapKey = %x, %v, want %x", got, err, key)
+	}
+
+	if _, err := UnwrapKey(testKey(t), wrapped); err == nil {
+		t.Errorf("UnwrapKey succeeded with the wrong key-encryption key")
+	}
+	if _, err := UnwrapKey(kek, wrapped[:4]); err == nil {
+		t.Errorf("UnwrapKey succeeded on a truncated key")
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"expvar"
+	"io"
+	"net/http"
+	"s3-like/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	scrubUseCase domain.ScrubUseCase
+}
+
+func NewAdminHandler(scrubUseCase domain.ScrubUseCase) *AdminHandler {
+	return &AdminHandler{
+		scrubUseCase: scrubUseCase,
+	}
+}
+
+// GetScrubReport godoc
+// @Summary Get the storage scrub report
+// @Description Get the report of the running storage scrub, or else of the last one. A scrub rehashes stored content and looks for records whose content is missing and content no record points to.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.ScrubReport "Scrub report"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not an admin"
+// @Failure 404 {object} map[string]interface{} "No scrub has run yet"
+// @Router /api/v1/admin/scrub [get]
+func (h *AdminHandler) GetScrubReport(c *gin.Context) {
+	report := h.scrubUseCase.Report()
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no scrub has run yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// StartScrub godoc
+// @Summary Start a storage scrub
+// @Description Start a storage scrub in the background; poll GET /api/v1/admin/scrub for its report. With repair set, orphaned content is moved under .quarantine/ rather than only reported.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.ScrubRequest false "Scrub options"
+// @Success 202 {object} map[string]interface{} "Scrub started"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not an admin"
+// @Failure 409 {object} map[string]interface{} "A scrub is already running"
+// @Router /api/v1/admin/scrub [post]
+func (h *AdminHandler) StartScrub(c *gin.Context) {
+	var req domain.ScrubRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.scrubUseCase.StartScrub(req.Repair); err != nil {
+		if errors.Is(err, domain.ErrScrubRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Scrub started", "repair": req.Repair})
+}
+
+// GetMetrics godoc
+// @Summary Get server metrics
+// @Description Get the server's expvar metrics, among them the storage scrub totals under "scrub"
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Metrics"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not an admin"
+// @Router /api/v1/admin/metrics [get]
+func (h *AdminHandler) GetMetrics(c *gin.Context) {
+	expvar.Handler().ServeHTTP(c.Writer, c.Request)
+}
@@ -145,3 +145,75 @@ func (h *AuthHandler) LogoutAll(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logout from all devices successful"})
 }
+
+// CreateAccessKey godoc
+// @Summary Create an access key
+// @Description Create a new S3 access key pair for the authenticated user. The secret is only returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} domain.CreateAccessKeyResponse "Access key created"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/auth/access-keys [post]
+func (h *AuthHandler) CreateAccessKey(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	response, err := h.authUseCase.CreateAccessKey(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create access key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListAccessKeys godoc
+// @Summary List access keys
+// @Description List the S3 access keys of the authenticated user, without their secrets
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "List of access keys"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/auth/access-keys [get]
+func (h *AuthHandler) ListAccessKeys(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	keys, err := h.authUseCase.ListAccessKeys(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_keys": keys,
+		"count":       len(keys),
+	})
+}
+
+// RevokeAccessKey godoc
+// @Summary Revoke an access key
+// @Description Revoke one of the authenticated user's S3 access keys
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param accessKeyId path string true "Access key ID"
+// @Success 204 "Access key revoked"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Access key not found"
+// @Router /api/v1/auth/access-keys/{accessKeyId} [delete]
+func (h *AuthHandler) RevokeAccessKey(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.authUseCase.RevokeAccessKey(userID, c.Param("accessKeyId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
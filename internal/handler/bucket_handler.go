@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"s3-like/internal/domain"
 
@@ -20,7 +21,7 @@ func NewBucketHandler(bucketUseCase domain.BucketUseCase) *BucketHandler {
 
 // CreateBucket godoc
 // @Summary Create a new bucket
-// @Description Create a new storage bucket
+// @Description Create a new storage bucket. Creating it with Object Lock enabled also enables versioning; a default retention applied to new versions can be given at the same time. New blobs can be compressed with zstd or gzip.
 // @Tags buckets
 // @Accept json
 // @Produce json
@@ -123,3 +124,348 @@ func (h *BucketHandler) DeleteBucket(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// GetBucketVersioning godoc
+// @Summary Get bucket versioning
+// @Description Get the versioning status of a bucket: Unversioned, Enabled or Suspended
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} map[string]interface{} "Versioning status"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/versioning [get]
+func (h *BucketHandler) GetBucketVersioning(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket.Name,
+		"status": bucket.Versioning,
+	})
+}
+
+// PutBucketVersioning godoc
+// @Summary Set bucket versioning
+// @Description Enable or suspend versioning on a bucket. While suspended, uploads write a "null" version that replaces the previous null version; existing versions are kept. A bucket cannot return to Unversioned.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.BucketVersioningRequest true "Versioning status"
+// @Success 200 {object} map[string]interface{} "Versioning status"
+// @Failure 400 {object} map[string]interface{} "Invalid versioning status"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Failure 409 {object} map[string]interface{} "Object Lock is enabled on the bucket"
+// @Router /api/v1/buckets/{bucket}/versioning [put]
+func (h *BucketHandler) PutBucketVersioning(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.BucketVersioningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bucket, err := h.bucketUseCase.PutBucketVersioning(userID, bucketName, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBucketNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidVersioning):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrObjectLockVersioning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bucket": bucket.Name,
+		"status": bucket.Versioning,
+	})
+}
+
+// GetObjectLockConfiguration godoc
+// @Summary Get bucket Object Lock configuration
+// @Description Get whether Object Lock is enabled on a bucket and its default retention
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} domain.ObjectLockConfigurationRequest "Object Lock configuration"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/object-lock [get]
+func (h *BucketHandler) GetObjectLockConfiguration(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, bucketObjectLock(bucket))
+}
+
+// PutObjectLockConfiguration godoc
+// @Summary Set bucket Object Lock configuration
+// @Description Enable Object Lock on a bucket with versioning enabled and set the default retention for new versions. Object Lock cannot be disabled again, and versioning can no longer be suspended once it is on. Omitting default_retention removes the default.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.ObjectLockConfigurationRequest true "Object Lock configuration"
+// @Success 200 {object} domain.ObjectLockConfigurationRequest "Object Lock configuration"
+// @Failure 400 {object} map[string]interface{} "Invalid configuration"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Failure 409 {object} map[string]interface{} "Versioning is not enabled on the bucket"
+// @Router /api/v1/buckets/{bucket}/object-lock [put]
+func (h *BucketHandler) PutObjectLockConfiguration(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.ObjectLockConfigurationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bucket, err := h.bucketUseCase.PutObjectLockConfiguration(userID, bucketName, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBucketNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrObjectLockNotEnabled), errors.Is(err, domain.ErrInvalidDefaultRetention):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrObjectLockVersioning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, bucketObjectLock(bucket))
+}
+
+// writeOwnedBucketError writes the response for a bucket the user wanted to
+// change but could not look up with GetOwnedBucket.
+func writeOwnedBucketError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrAccessDenied) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+}
+
+// GetBucketEncryption godoc
+// @Summary Get bucket default encryption
+// @Description Get the server-side encryption applied to uploads that do not ask for any; sse_algorithm is empty when there is none
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} domain.BucketEncryptionRequest "Default encryption"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/encryption [get]
+func (h *BucketHandler) GetBucketEncryption(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.BucketEncryptionRequest{SSEAlgorithm: bucket.DefaultEncryption})
+}
+
+// PutBucketEncryption godoc
+// @Summary Set bucket default encryption
+// @Description Encrypt uploads that do not ask for server-side encryption with SSE-S3. AES256 is the only algorithm supported, and the server needs a master key configured.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.BucketEncryptionRequest true "Default encryption"
+// @Success 200 {object} domain.BucketEncryptionRequest "Default encryption"
+// @Failure 400 {object} map[string]interface{} "Unsupported algorithm, or no master key configured"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/encryption [put]
+func (h *BucketHandler) PutBucketEncryption(c *gin.Context) {
+	var req domain.BucketEncryptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.putBucketEncryption(c, req.SSEAlgorithm)
+}
+
+// DeleteBucketEncryption godoc
+// @Summary Remove bucket default encryption
+// @Description Stop encrypting uploads that do not ask for server-side encryption. Objects already encrypted stay encrypted.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} domain.BucketEncryptionRequest "Default encryption"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/encryption [delete]
+func (h *BucketHandler) DeleteBucketEncryption(c *gin.Context) {
+	h.putBucketEncryption(c, "")
+}
+
+func (h *BucketHandler) putBucketEncryption(c *gin.Context, algorithm string) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.PutBucketEncryption(userID, bucketName, algorithm)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBucketNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidEncryption), errors.Is(err, domain.ErrEncryptionNotConfigured):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.BucketEncryptionRequest{SSEAlgorithm: bucket.DefaultEncryption})
+}
+
+// GetBucketCompression godoc
+// @Summary Get bucket compression
+// @Description Get the algorithm new blobs are compressed with; algorithm is empty when they are stored as uploaded
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} domain.BucketCompressionRequest "Compression"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/compression [get]
+func (h *BucketHandler) GetBucketCompression(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.BucketCompressionRequest{Algorithm: bucket.Compression})
+}
+
+// PutBucketCompression godoc
+// @Summary Set bucket compression
+// @Description Compress new blobs with zstd or gzip. Size and ETag still describe the content as uploaded, and reads decompress it transparently. Content types that are compressed already, and content sent with a Content-Encoding, are stored as uploaded.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.BucketCompressionRequest true "Compression"
+// @Success 200 {object} domain.BucketCompressionRequest "Compression"
+// @Failure 400 {object} map[string]interface{} "Unsupported algorithm"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/compression [put]
+func (h *BucketHandler) PutBucketCompression(c *gin.Context) {
+	var req domain.BucketCompressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.putBucketCompression(c, req.Algorithm)
+}
+
+// DeleteBucketCompression godoc
+// @Summary Turn off bucket compression
+// @Description Store new blobs as uploaded. Blobs already compressed stay compressed.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} domain.BucketCompressionRequest "Compression"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/compression [delete]
+func (h *BucketHandler) DeleteBucketCompression(c *gin.Context) {
+	h.putBucketCompression(c, "")
+}
+
+func (h *BucketHandler) putBucketCompression(c *gin.Context, algorithm string) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.PutBucketCompression(userID, bucketName, algorithm)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBucketNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAccessDenied):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidCompression):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.BucketCompressionRequest{Algorithm: bucket.Compression})
+}
+
+func bucketObjectLock(bucket *domain.Bucket) *domain.ObjectLockConfigurationRequest {
+	config := &domain.ObjectLockConfigurationRequest{ObjectLockEnabled: bucket.ObjectLockEnabled}
+	if bucket.DefaultRetention.Mode != "" {
+		retention := bucket.DefaultRetention
+		config.DefaultRetention = &retention
+	}
+	return config
+}
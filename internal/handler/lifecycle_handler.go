@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"s3-like/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LifecycleHandler struct {
+	lifecycleUseCase domain.LifecycleUseCase
+}
+
+func NewLifecycleHandler(lifecycleUseCase domain.LifecycleUseCase) *LifecycleHandler {
+	return &LifecycleHandler{
+		lifecycleUseCase: lifecycleUseCase,
+	}
+}
+
+// GetBucketLifecycle godoc
+// @Summary Get bucket lifecycle rules
+// @Description Get the lifecycle rules of a bucket
+// @Tags lifecycle
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} domain.BucketLifecycle "Lifecycle rules"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket or lifecycle configuration not found"
+// @Router /api/v1/buckets/{bucket}/lifecycle [get]
+func (h *LifecycleHandler) GetBucketLifecycle(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	lifecycle, err := h.lifecycleUseCase.GetBucketLifecycle(userID, c.Param("bucket"))
+	if err != nil {
+		writeLifecycleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lifecycle)
+}
+
+// PutBucketLifecycle godoc
+// @Summary Set bucket lifecycle rules
+// @Description Replace the lifecycle rules of a bucket. Rules select objects by prefix, tags and size, and can expire current versions after a number of days, permanently delete noncurrent versions by age or beyond a number of newer versions, remove delete markers with no versions left behind them and abort incomplete multipart uploads. Rules are evaluated periodically by a background job.
+// @Tags lifecycle
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.PutBucketLifecycleRequest true "Lifecycle rules"
+// @Success 200 {object} domain.BucketLifecycle "Lifecycle rules saved"
+// @Failure 400 {object} map[string]interface{} "Invalid lifecycle configuration"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/lifecycle [put]
+func (h *LifecycleHandler) PutBucketLifecycle(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req domain.PutBucketLifecycleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lifecycle, err := h.lifecycleUseCase.PutBucketLifecycle(userID, c.Param("bucket"), req.Rules)
+	if err != nil {
+		writeLifecycleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lifecycle)
+}
+
+// DeleteBucketLifecycle godoc
+// @Summary Delete bucket lifecycle rules
+// @Description Remove all lifecycle rules from a bucket
+// @Tags lifecycle
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 204 "Lifecycle rules deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Router /api/v1/buckets/{bucket}/lifecycle [delete]
+func (h *LifecycleHandler) DeleteBucketLifecycle(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.lifecycleUseCase.DeleteBucketLifecycle(userID, c.Param("bucket")); err != nil {
+		writeLifecycleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeLifecycleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrBucketNotFound), errors.Is(err, domain.ErrLifecycleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidLifecycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"s3-like/internal/domain"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MultipartHandler struct {
+	multipartUseCase domain.MultipartUseCase
+	bucketUseCase    domain.BucketUseCase
+}
+
+func NewMultipartHandler(multipartUseCase domain.MultipartUseCase, bucketUseCase domain.BucketUseCase) *MultipartHandler {
+	return &MultipartHandler{
+		multipartUseCase: multipartUseCase,
+		bucketUseCase:    bucketUseCase,
+	}
+}
+
+// CreateMultipartUpload godoc
+// @Summary Start a multipart upload
+// @Description Start a multipart upload for a large object. Upload the parts with the returned upload_id, then complete or abort the upload. The Object Lock retention and legal hold requested here are given to the object when the upload completes.
+// @Tags multipart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.CreateMultipartUploadRequest true "Upload parameters"
+// @Success 201 {object} domain.MultipartUpload "Upload started"
+// @Failure 400 {object} map[string]interface{} "Invalid request, invalid retention or Object Lock not enabled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/uploads [post]
+func (h *MultipartHandler) CreateMultipartUpload(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.CreateMultipartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	encryption, err := encryptionHeaders(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	upload, err := h.multipartUseCase.CreateMultipartUpload(bucket.ID, req.Key, &domain.PutObjectOptions{
+		ContentType: req.ContentType,
+		Encryption:  encryption,
+		Metadata:    req.Metadata,
+		Tags:        req.Tags,
+		UploadInfo:  newUploadInfo(c, ""),
+		ObjectLock: domain.ObjectLock{
+			RetentionMode: req.ObjectLockMode,
+			RetainUntil:   req.ObjectLockRetainUntil,
+			LegalHold:     req.ObjectLockLegalHold,
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrMetadataTooLarge),
+			errors.Is(err, domain.ErrObjectLockNotEnabled), errors.Is(err, domain.ErrInvalidRetention),
+			errors.Is(err, domain.ErrInvalidEncryption), errors.Is(err, domain.ErrSSECMultipart),
+			errors.Is(err, domain.ErrEncryptionNotConfigured):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
+// ListMultipartUploads godoc
+// @Summary List multipart uploads
+// @Description List the multipart uploads in progress in a bucket
+// @Tags multipart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Success 200 {object} map[string]interface{} "List of uploads"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/uploads [get]
+func (h *MultipartHandler) ListMultipartUploads(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		return
+	}
+
+	uploads, err := h.multipartUseCase.ListMultipartUploads(bucket.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"uploads": uploads,
+		"count":   len(uploads),
+		"bucket":  bucketName,
+	})
+}
+
+// UploadPart godoc
+// @Summary Upload a part
+// @Description Upload one part of a multipart upload as the raw request body. Uploading the same part number again replaces the part. The part is rejected if it does not match Content-MD5 or an x-amz-checksum-* header.
+// @Tags multipart
+// @Accept application/octet-stream
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param uploadId path string true "Upload ID"
+// @Param partNumber path int true "Part number (1-10000)"
+// @Param Content-MD5 header string false "Base64 MD5 digest of the part"
+// @Success 200 {object} domain.MultipartPart "Part uploaded"
+// @Failure 400 {object} map[string]interface{} "Invalid part number, or the part does not match its digests"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket or upload not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/uploads/{uploadId}/parts/{partNumber} [put]
+func (h *MultipartHandler) UploadPart(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	uploadID, err := uuid.Parse(c.Param("uploadId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		return
+	}
+
+	partNumber, err := strconv.Atoi(c.Param("partNumber"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidPartNumber.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	part, err := h.multipartUseCase.UploadPart(bucket.ID, uploadID, partNumber, c.Request.Body, &domain.UploadPartOptions{
+		ContentMD5: c.GetHeader("Content-MD5"),
+		Checksums:  checksumHeaders(c),
+	})
+	if err != nil {
+		writeMultipartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, part)
+}
+
+// ListParts godoc
+// @Summary List uploaded parts
+// @Description List the parts uploaded so far for a multipart upload
+// @Tags multipart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param uploadId path string true "Upload ID"
+// @Success 200 {object} map[string]interface{} "Upload and its parts"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Bucket or upload not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/uploads/{uploadId}/parts [get]
+func (h *MultipartHandler) ListParts(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	uploadID, err := uuid.Parse(c.Param("uploadId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		return
+	}
+
+	upload, parts, err := h.multipartUseCase.ListParts(bucket.ID, uploadID)
+	if err != nil {
+		writeMultipartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upload": upload,
+		"parts":  parts,
+		"count":  len(parts),
+	})
+}
+
+// CompleteMultipartUpload godoc
+// @Summary Complete a multipart upload
+// @Description Assemble the listed parts, in ascending part number order, into a new object version
+// @Tags multipart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param uploadId path string true "Upload ID"
+// @Param request body domain.CompleteMultipartUploadRequest true "Parts to assemble"
+// @Success 200 {object} domain.UploadObjectResponse "Object created"
+// @Failure 400 {object} map[string]interface{} "Invalid part list, or the requested retention has lapsed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner, or the version being replaced is locked"
+// @Failure 404 {object} map[string]interface{} "Bucket or upload not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/uploads/{uploadId}/complete [post]
+func (h *MultipartHandler) CompleteMultipartUpload(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	uploadID, err := uuid.Parse(c.Param("uploadId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		return
+	}
+
+	var req domain.CompleteMultipartUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	response, err := h.multipartUseCase.CompleteMultipartUpload(bucket.ID, uploadID, req.Parts)
+	if err != nil {
+		writeMultipartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AbortMultipartUpload godoc
+// @Summary Abort a multipart upload
+// @Description Abort a multipart upload and discard its parts
+// @Tags multipart
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param uploadId path string true "Upload ID"
+// @Success 204 "Upload aborted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket or upload not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/uploads/{uploadId} [delete]
+func (h *MultipartHandler) AbortMultipartUpload(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	uploadID, err := uuid.Parse(c.Param("uploadId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	if err := h.multipartUseCase.AbortMultipartUpload(bucket.ID, uploadID); err != nil {
+		writeMultipartError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeMultipartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUploadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidPartNumber),
+		errors.Is(err, domain.ErrInvalidPart),
+		errors.Is(err, domain.ErrInvalidPartOrder),
+		errors.Is(err, domain.ErrNoParts),
+		errors.Is(err, domain.ErrEntityTooSmall),
+		errors.Is(err, domain.ErrInvalidRetention),
+		errors.Is(err, domain.ErrInvalidDigest),
+		errors.Is(err, domain.ErrInvalidChecksum),
+		errors.Is(err, domain.ErrBadDigest),
+		errors.Is(err, domain.ErrIncompleteBody):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrObjectLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
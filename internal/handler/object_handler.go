@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"crypto/md5"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"s3-like/internal/domain"
 	"s3-like/internal/utils"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -35,13 +40,17 @@ func NewObjectHandler(objectUseCase domain.ObjectUseCase, bucketUseCase domain.B
 // @Param bucket path string true "Bucket name"
 // @Param file formData file true "File to upload"
 // @Param key formData string false "Object key (if not provided, filename will be used)"
-// @Param metadata formData string false "JSON string with custom metadata (e.g., {\"author\":\"John\",\"category\":\"documents\"})"
+// @Param metadata formData string false "JSON string with custom metadata (e.g., {\"author\":\"John\",\"category\":\"documents\"}); x-amz-meta-* headers are accepted too"
 // @Param content-type formData string false "Content type override"
 // @Param description formData string false "File description"
-// @Param tags formData string false "Comma-separated tags"
+// @Param tags formData string false "Tags in URL query format, e.g. project=alpha&team=storage"
+// @Param object_lock_mode formData string false "Object Lock retention mode: GOVERNANCE or COMPLIANCE"
+// @Param object_lock_retain_until formData string false "Retain-until date in RFC 3339 format"
+// @Param object_lock_legal_hold formData bool false "Place a legal hold on the new version"
 // @Success 201 {object} domain.UploadObjectResponse "File uploaded successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid request"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner, or the version being replaced is locked"
 // @Failure 404 {object} map[string]interface{} "Bucket not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/buckets/{bucket}/objects [post]
@@ -50,9 +59,9 @@ func (h *ObjectHandler) UploadObject(c *gin.Context) {
 	bucketName := c.Param("bucket")
 
 	// Get bucket
-	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		writeOwnedBucketError(c, err)
 		return
 	}
 
@@ -70,8 +79,8 @@ func (h *ObjectHandler) UploadObject(c *gin.Context) {
 		key = utils.SanitizeFilename(header.Filename)
 	}
 
-	// Parse metadata from form
-	metadata := make(map[string]string)
+	// User metadata comes from x-amz-meta-* headers and the form fields
+	metadata := userMetadataHeaders(c)
 
 	// Get JSON metadata if provided
 	if metadataJSON := c.PostForm("metadata"); metadataJSON != "" {
@@ -91,168 +100,550 @@ func (h *ObjectHandler) UploadObject(c *gin.Context) {
 		metadata["description"] = description
 	}
 
-	if tags := c.PostForm("tags"); tags != "" {
-		metadata["tags"] = tags
-	}
-
 	if contentTypeOverride := c.PostForm("content-type"); contentTypeOverride != "" {
-		metadata["content_type_override"] = contentTypeOverride
 		// Override the header content type
 		header.Header.Set("Content-Type", contentTypeOverride)
 	}
 
-	// Add user information to metadata
-	if user, exists := c.Get("user"); exists {
-		if userObj, ok := user.(*domain.User); ok {
-			metadata["uploaded_by"] = userObj.Username
-			metadata["uploaded_by_id"] = userObj.ID.String()
+	tags, err := parseTagging(c.PostForm("tags"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lock := domain.ObjectLock{
+		RetentionMode: c.PostForm("object_lock_mode"),
+		LegalHold:     c.PostForm("object_lock_legal_hold") == "true",
+	}
+	if retainUntil := c.PostForm("object_lock_retain_until"); retainUntil != "" {
+		t, err := time.Parse(time.RFC3339, retainUntil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid object_lock_retain_until"})
+			return
 		}
+		lock.RetainUntil = &t
 	}
 
-	// Add request metadata
-	metadata["user_agent"] = c.GetHeader("User-Agent")
-	metadata["client_ip"] = c.ClientIP()
+	encryption, err := encryptionHeaders(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	response, err := h.objectUseCase.UploadObject(bucket.ID, key, file, header, metadata)
+	response, err := h.objectUseCase.PutObject(bucket.ID, key, file, &domain.PutObjectOptions{
+		ContentType: header.Header.Get("Content-Type"),
+		Encryption:  encryption,
+		Metadata:    metadata,
+		Tags:        tags,
+		UploadInfo:  newUploadInfo(c, header.Filename),
+		ObjectLock:  lock,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// PutObject godoc
+// @Summary Upload an object from the request body
+// @Description Stream the raw request body to storage as a new version of the key, without multipart/form-data buffering. Content-Type, Cache-Control, Content-Disposition and Content-Encoding are stored and returned on download, x-amz-meta-* headers become user metadata and x-amz-tagging sets the tags. The upload is rejected if the body does not match Content-MD5 or an x-amz-checksum-* header, or is shorter than Content-Length; the checksums given are stored with the version.
+// @Tags objects
+// @Accept octet-stream
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param Content-MD5 header string false "Base64 MD5 digest of the body"
+// @Param x-amz-checksum-crc32 header string false "Base64 CRC32 checksum of the body"
+// @Param x-amz-checksum-crc32c header string false "Base64 CRC32C checksum of the body"
+// @Param x-amz-checksum-sha1 header string false "Base64 SHA-1 digest of the body"
+// @Param x-amz-checksum-sha256 header string false "Base64 SHA-256 digest of the body"
+// @Param Cache-Control header string false "Cache-Control to return on download"
+// @Param Content-Disposition header string false "Content-Disposition to return on download"
+// @Param Content-Encoding header string false "Content-Encoding of the body, returned on download"
+// @Param x-amz-tagging header string false "Tags in URL query format, e.g. project=alpha&team=storage"
+// @Param body body string true "Object content"
+// @Success 201 {object} domain.UploadObjectResponse "Object uploaded"
+// @Header 201 {string} ETag "Quoted MD5 of the content"
+// @Header 201 {string} X-Object-Version-ID "Version ID of the new object"
+// @Failure 400 {object} map[string]interface{} "Invalid request, or the body does not match its digests or Content-Length"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner, or the version being replaced is locked"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/objects/{key} [put]
+func (h *ObjectHandler) PutObject(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "object key is required"})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
 		return
 	}
 
+	tags, err := parseTagging(c.GetHeader("x-amz-tagging"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	encryption, err := encryptionHeaders(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.objectUseCase.PutObject(bucket.ID, key, c.Request.Body, &domain.PutObjectOptions{
+		ContentType:    c.GetHeader("Content-Type"),
+		ContentHeaders: contentHeaders(c),
+		ContentMD5:     c.GetHeader("Content-MD5"),
+		Checksums:      checksumHeaders(c),
+		Encryption:     encryption,
+		Metadata:       userMetadataHeaders(c),
+		Tags:           tags,
+		UploadInfo:     newUploadInfo(c, ""),
+	})
+	if err != nil {
+		writeUploadError(c, err)
+		return
+	}
+
+	c.Header("ETag", quoteETag(response.Object.ETag))
+	c.Header("X-Object-Version-ID", response.VersionID)
+	setEncryptionHeaders(c, response.Object.ObjectEncryption)
 	c.JSON(http.StatusCreated, response)
 }
 
+func writeUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrObjectLockNotEnabled), errors.Is(err, domain.ErrInvalidRetention),
+		errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrMetadataTooLarge),
+		errors.Is(err, domain.ErrInvalidDigest), errors.Is(err, domain.ErrInvalidChecksum),
+		errors.Is(err, domain.ErrBadDigest), errors.Is(err, domain.ErrIncompleteBody),
+		errors.Is(err, domain.ErrInvalidEncryption), errors.Is(err, domain.ErrInvalidCustomerKey),
+		errors.Is(err, domain.ErrEncryptionNotConfigured):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrObjectLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // GetObject godoc
 // @Summary Download a file
-// @Description Download the latest version of a file from a bucket
+// @Description Download the latest version of a file from a bucket, or the version named by version_id
 // @Tags objects
 // @Accept json
 // @Produce application/octet-stream
 // @Security BearerAuth
 // @Param bucket path string true "Bucket name"
-// @Param key path string true "Object key"
+// @Param key path string true "Object key, may contain slashes"
+// @Param version_id query string false "Version ID (default: latest version)"
+// @Param Range header string false "Byte ranges to return, e.g. bytes=0-1023; several ranges give a multipart/byteranges response"
+// @Param If-Match header string false "Only return the object if its ETag matches"
+// @Param If-None-Match header string false "Return 304 if the object's ETag matches"
+// @Param If-Modified-Since header string false "Return 304 unless the object was modified since this date"
+// @Param If-Unmodified-Since header string false "Only return the object if it was not modified since this date"
+// @Param x-amz-checksum-mode header string false "ENABLED to return the stored x-amz-checksum-* headers; ignored for range reads"
 // @Success 200 {file} file "File content"
+// @Success 206 {file} file "Requested byte ranges"
+// @Success 304 "Not modified"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
-// @Failure 404 {object} map[string]interface{} "Object or bucket not found"
+// @Failure 404 {object} map[string]interface{} "Object, version or bucket not found"
+// @Failure 405 {object} map[string]interface{} "The requested version is a delete marker"
+// @Failure 412 "Precondition failed"
+// @Failure 416 "Requested range not satisfiable"
 // @Router /api/v1/buckets/{bucket}/objects/{key} [get]
 func (h *ObjectHandler) GetObject(c *gin.Context) {
-	userID, userExists := c.Get("user_id")
+	userID := optionalUserID(c)
 	bucketName := c.Param("bucket")
 	key := strings.TrimPrefix(c.Param("key"), "/")
 
-	var userIDuuid uuid.UUID
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		return
+	}
+
+	customerKey, err := customerKeyHeaders(c, "x-amz-server-side-encryption-customer-")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	if userExists {
-		if strID, ok := userID.(string); ok && strID != "" {
-			parsedID, _ := uuid.Parse(strID)
-			userIDuuid = parsedID
+	versionID := c.Query("version_id")
+	object, file, err := h.objectUseCase.GetObjectVersion(bucket.ID, key, versionID, customerKey)
+	if err != nil && writeCustomerKeyError(c, err) {
+		return
+	}
+	if err != nil {
+		setDeleteMarkerHeader(c, err)
+		// A delete marker named by version has no content to return; as
+		// the latest version it just hides the object
+		if versionID != "" && errors.Is(err, domain.ErrDeleteMarker) {
+			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": err.Error()})
+			return
 		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "object not found"})
+		return
 	}
+	defer file.Close()
+
+	setObjectHeaders(c, object)
+
+	// Stream file, honoring Range and conditional request headers
+	serveObjectContent(c, object, file)
+}
+
+// HeadObject godoc
+// @Summary Get object metadata
+// @Description Return the headers of a download (size, ETag, version and X-Object-Meta-* metadata) without the body. Conditional headers are evaluated as for a download.
+// @Tags objects
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param version_id query string false "Version ID (default: latest version)"
+// @Param If-Match header string false "Succeed only if the object's ETag matches"
+// @Param If-None-Match header string false "Return 304 if the object's ETag matches"
+// @Param If-Modified-Since header string false "Return 304 unless the object was modified since this date"
+// @Param If-Unmodified-Since header string false "Succeed only if the object was not modified since this date"
+// @Param x-amz-checksum-mode header string false "ENABLED to return the stored x-amz-checksum-* headers; ignored for range reads"
+// @Success 200 "Object exists"
+// @Success 304 "Not modified"
+// @Failure 401 "Unauthorized"
+// @Failure 404 "Object or bucket not found"
+// @Failure 412 "Precondition failed"
+// @Router /api/v1/buckets/{bucket}/objects/{key} [head]
+func (h *ObjectHandler) HeadObject(c *gin.Context) {
+	userID := optionalUserID(c)
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
 
 	// Get bucket
-	bucket, err := h.bucketUseCase.GetBucket(userIDuuid, bucketName)
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	object, err := h.objectUseCase.HeadObject(bucket.ID, key, c.Query("version_id"))
+	if err != nil {
+		setDeleteMarkerHeader(c, err)
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	setObjectHeaders(c, object)
+	serveObjectContent(c, object, newObjectHead(object))
+}
+
+// ObjectAction dispatches POST /api/v1/buckets/:bucket/objects/*key requests
+// on the action suffix of the path, e.g. {key}/copy, since keys may contain
+// slashes themselves. A POST always names an action, so the action is cut off
+// the end of the path and whatever precedes it is the key; a key that ends in
+// "/copy" itself is copied with {key}/copy/copy.
+func (h *ObjectHandler) ObjectAction(c *gin.Context) {
+	path := c.Param("key")
+
+	switch {
+	case strings.HasSuffix(path, "/copy"):
+		h.CopyObject(c, strings.TrimPrefix(strings.TrimSuffix(path, "/copy"), "/"))
+	case strings.HasSuffix(path, "/restore"):
+		// Keys may contain "/versions/" too; the version ID never has a slash
+		path = strings.TrimSuffix(path, "/restore")
+		i := strings.LastIndex(path, "/versions/")
+		if i < 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown object action"})
+			return
+		}
+		h.RestoreObjectVersion(c, strings.TrimPrefix(path[:i], "/"), path[i+len("/versions/"):])
+	case strings.HasSuffix(path, "/retention"):
+		h.PutObjectRetention(c, strings.TrimPrefix(strings.TrimSuffix(path, "/retention"), "/"))
+	case strings.HasSuffix(path, "/legal-hold"):
+		h.PutObjectLegalHold(c, strings.TrimPrefix(strings.TrimSuffix(path, "/legal-hold"), "/"))
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown object action"})
+	}
+}
+
+// CopyObject godoc
+// @Summary Copy an object
+// @Description Copy the latest version, or a specific version, of an object to a destination bucket and key without downloading it. The metadata directive COPY keeps the source content type and metadata; REPLACE uses the ones in the request. The copy fails with 412 if a condition on the source does not hold.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Source bucket name"
+// @Param key path string true "Source object key, may contain slashes"
+// @Param request body domain.CopyObjectRequest true "Copy parameters"
+// @Success 201 {object} domain.UploadObjectResponse "Object copied"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the owner of the destination bucket"
+// @Failure 404 {object} map[string]interface{} "Object or bucket not found"
+// @Failure 412 {object} map[string]interface{} "Precondition failed"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/objects/{key}/copy [post]
+func (h *ObjectHandler) CopyObject(c *gin.Context, key string) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.CopyObjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get buckets
+	srcBucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
 		return
 	}
 
-	object, file, err := h.objectUseCase.GetObject(bucket.ID, key)
+	dstBucketName := bucketName
+	if req.DestinationBucket != "" {
+		dstBucketName = req.DestinationBucket
+	}
+	dstBucket, err := h.bucketUseCase.GetOwnedBucket(userID, dstBucketName)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "object not found"})
+		if errors.Is(err, domain.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "destination bucket not found"})
 		return
 	}
-	defer file.Close()
 
-	// Set headers
-	c.Header("Content-Type", object.ContentType)
-	c.Header("Content-Length", strconv.FormatInt(object.Size, 10))
-	c.Header("ETag", object.ETag)
-	c.Header("Content-Disposition", "attachment; filename=\""+object.Key+"\"")
-	c.Header("X-Object-Version-ID", object.VersionID)
+	encryption, err := encryptionHeaders(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sourceCustomerKey, err := customerKeyHeaders(c, "x-amz-copy-source-server-side-encryption-customer-")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Add metadata to headers if available
-	if object.Metadata != "" {
-		var metadata map[string]any
-		if err := json.Unmarshal([]byte(object.Metadata), &metadata); err == nil {
-			for k, v := range metadata {
-				headerKey := "X-Object-Meta-" + strings.ReplaceAll(k, "_", "-")
-				c.Header(headerKey, fmt.Sprintf("%v", v))
-			}
+	response, err := h.objectUseCase.CopyObject(srcBucket.ID, key, dstBucket.ID, req.DestinationKey, &domain.CopyObjectOptions{
+		SourceVersionID:   req.SourceVersionID,
+		MetadataDirective: req.MetadataDirective,
+		ContentType:       req.ContentType,
+		Metadata:          req.Metadata,
+		TaggingDirective:  req.TaggingDirective,
+		Tags:              req.Tags,
+		Encryption:        encryption,
+		SourceCustomerKey: sourceCustomerKey,
+		Conditions: domain.CopyConditions{
+			IfMatch:           req.IfMatch,
+			IfNoneMatch:       req.IfNoneMatch,
+			IfModifiedSince:   req.IfModifiedSince,
+			IfUnmodifiedSince: req.IfUnmodifiedSince,
+		},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrObjectNotFound), errors.Is(err, domain.ErrBlobNotFound), errors.Is(err, domain.ErrDeleteMarker):
+			c.JSON(http.StatusNotFound, gin.H{"error": "object not found"})
+		case errors.Is(err, domain.ErrPreconditionFailed):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrCopyToItself), errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrMetadataTooLarge),
+			errors.Is(err, domain.ErrInvalidEncryption), errors.Is(err, domain.ErrInvalidCustomerKey),
+			errors.Is(err, domain.ErrEncryptionNotConfigured), errors.Is(err, domain.ErrCustomerKeyRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrCustomerKeyMismatch):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
+		return
 	}
 
-	// Stream file
-	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, file, nil)
+	c.JSON(http.StatusCreated, response)
 }
 
-// GetObjectVersion godoc
-// @Summary Download a specific version of a file
-// @Description Download a specific version of a file from a bucket
+// RestoreObjectVersion godoc
+// @Summary Restore an object version
+// @Description Make a previous version of an object the latest again. The version is copied into a new latest version, so the history is kept and the restore can itself be undone. Restoring the current version is a no-op.
 // @Tags objects
 // @Accept json
-// @Produce application/octet-stream
+// @Produce json
 // @Security BearerAuth
 // @Param bucket path string true "Bucket name"
-// @Param key path string true "Object key"
-// @Param version path string true "Version ID"
-// @Success 200 {file} file "File content"
+// @Param key path string true "Object key, may contain slashes"
+// @Param version path string true "Version ID to restore"
+// @Success 201 {object} domain.UploadObjectResponse "Version restored"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
 // @Failure 404 {object} map[string]interface{} "Object version or bucket not found"
-// @Router /api/v1/buckets/{bucket}/objects/{key}/versions/{version} [get]
-func (h *ObjectHandler) GetObjectVersion(c *gin.Context) {
+// @Failure 405 {object} map[string]interface{} "The version is a delete marker"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/objects/{key}/versions/{version}/restore [post]
+func (h *ObjectHandler) RestoreObjectVersion(c *gin.Context, key, versionID string) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 	bucketName := c.Param("bucket")
-	key := strings.TrimPrefix(c.Param("key"), "/")
-	versionID := c.Param("version")
 
 	// Get bucket
-	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		writeOwnedBucketError(c, err)
 		return
 	}
 
-	object, file, err := h.objectUseCase.GetObjectVersion(bucket.ID, key, versionID)
+	response, err := h.objectUseCase.RestoreObjectVersion(bucket.ID, key, versionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "object version not found"})
+		switch {
+		case errors.Is(err, domain.ErrObjectNotFound), errors.Is(err, domain.ErrBlobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "object version not found"})
+		case errors.Is(err, domain.ErrDeleteMarker):
+			c.JSON(http.StatusMethodNotAllowed, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
-	defer file.Close()
 
-	// Set headers
-	c.Header("Content-Type", object.ContentType)
-	c.Header("Content-Length", strconv.FormatInt(object.Size, 10))
-	c.Header("ETag", object.ETag)
-	c.Header("Content-Disposition", "attachment; filename=\""+object.Key+"\"")
-	c.Header("X-Object-Version-ID", object.VersionID)
+	c.JSON(http.StatusCreated, response)
+}
 
-	// Add metadata to headers if available
-	if object.Metadata != "" {
-		var metadata map[string]any
-		if err := json.Unmarshal([]byte(object.Metadata), &metadata); err == nil {
-			for k, v := range metadata {
-				headerKey := "X-Object-Meta-" + strings.ReplaceAll(k, "_", "-")
-				c.Header(headerKey, fmt.Sprintf("%v", v))
-			}
-		}
+// PutObjectRetention godoc
+// @Summary Set object retention
+// @Description Set the Object Lock retention of the latest version of an object, or of version_id. Retention can always be extended or moved from GOVERNANCE to COMPLIANCE; shortening or removing it (empty mode and retain_until) is only possible in GOVERNANCE mode with bypass_governance, which only the bucket owner may use.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param request body domain.PutObjectRetentionRequest true "Retention"
+// @Success 200 {object} domain.Object "Object version with its new retention"
+// @Failure 400 {object} map[string]interface{} "Invalid retention or Object Lock not enabled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner, or the retention cannot be weakened"
+// @Failure 404 {object} map[string]interface{} "Object version or bucket not found"
+// @Failure 405 {object} map[string]interface{} "The version is a delete marker"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/objects/{key}/retention [post]
+func (h *ObjectHandler) PutObjectRetention(c *gin.Context, key string) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.PutObjectRetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	bypassGovernance, err := governanceBypass(bucket, userID, req.BypassGovernance)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	object, err := h.objectUseCase.PutObjectRetention(bucket.ID, key, req.VersionID, req.Mode, req.RetainUntil, bypassGovernance)
+	if err != nil {
+		writeObjectLockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, object)
+}
+
+// PutObjectLegalHold godoc
+// @Summary Set object legal hold
+// @Description Place or remove a legal hold on the latest version of an object, or on version_id. While the hold is on the version cannot be deleted or overwritten, whatever its retention.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param request body domain.PutObjectLegalHoldRequest true "Legal hold"
+// @Success 200 {object} domain.Object "Object version with its new legal hold"
+// @Failure 400 {object} map[string]interface{} "Object Lock not enabled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Object version or bucket not found"
+// @Failure 405 {object} map[string]interface{} "The version is a delete marker"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/objects/{key}/legal-hold [post]
+func (h *ObjectHandler) PutObjectLegalHold(c *gin.Context, key string) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.PutObjectLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	object, err := h.objectUseCase.PutObjectLegalHold(bucket.ID, key, req.VersionID, req.LegalHold)
+	if err != nil {
+		writeObjectLockError(c, err)
+		return
 	}
 
-	// Stream file
-	c.DataFromReader(http.StatusOK, object.Size, object.ContentType, file, nil)
+	c.JSON(http.StatusOK, object)
+}
+
+func writeObjectLockError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrObjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "object version not found"})
+	case errors.Is(err, domain.ErrDeleteMarker):
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrObjectLockNotEnabled), errors.Is(err, domain.ErrInvalidRetention):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrObjectLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
 // ListObjects godoc
 // @Summary List objects in a bucket
-// @Description Get a paginated list of objects in a bucket
+// @Description List the latest object versions in key order. With a delimiter, keys containing it after the prefix are rolled up into common_prefixes, like folders. Pass next_continuation_token back as continuation_token to fetch the next page.
 // @Tags objects
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param bucket path string true "Bucket name"
 // @Param prefix query string false "Object key prefix filter"
-// @Param page query int false "Page number (default: 1)"
-// @Param page_size query int false "Page size (default: 50, max: 1000)"
+// @Param delimiter query string false "Character used to group keys into common prefixes, usually /"
+// @Param start_after query string false "Only list keys after this key"
+// @Param continuation_token query string false "Token from a previous truncated response"
+// @Param max_keys query int false "Maximum number of objects and common prefixes (default: 50, max: 1000)"
+// @Param tag query []string false "Only list objects carrying this tag, as key=value; repeat to require several" collectionFormat(multi)
 // @Success 200 {object} domain.ListObjectsResponse "List of objects"
+// @Failure 400 {object} map[string]interface{} "Invalid continuation token or tag filter"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 404 {object} map[string]interface{} "Bucket not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -260,14 +651,16 @@ func (h *ObjectHandler) GetObjectVersion(c *gin.Context) {
 func (h *ObjectHandler) ListObjects(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 	bucketName := c.Param("bucket")
-	prefix := c.Query("prefix")
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	maxKeys, err := strconv.Atoi(c.DefaultQuery("max_keys", "50"))
+	if err != nil || maxKeys < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_keys must be a non-negative integer"})
+		return
+	}
 
 	// Limit page size
-	if pageSize > 1000 {
-		pageSize = 1000
+	if maxKeys > 1000 {
+		maxKeys = 1000
 	}
 
 	// Get bucket
@@ -277,8 +670,32 @@ func (h *ObjectHandler) ListObjects(c *gin.Context) {
 		return
 	}
 
-	response, err := h.objectUseCase.ListObjects(bucket.ID, prefix, page, pageSize)
+	var tags domain.ObjectTags
+	for _, filter := range c.QueryArray("tag") {
+		key, value, ok := strings.Cut(filter, "=")
+		if !ok || key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "tag filters must have the form key=value"})
+			return
+		}
+		if tags == nil {
+			tags = make(domain.ObjectTags)
+		}
+		tags[key] = value
+	}
+
+	response, err := h.objectUseCase.ListObjects(bucket.ID, &domain.ListObjectsRequest{
+		Prefix:            c.Query("prefix"),
+		Delimiter:         c.Query("delimiter"),
+		StartAfter:        c.Query("start_after"),
+		ContinuationToken: c.Query("continuation_token"),
+		MaxKeys:           maxKeys,
+		Tags:              tags,
+	})
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidContinuation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -286,6 +703,187 @@ func (h *ObjectHandler) ListObjects(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetObjectTagging godoc
+// @Summary Get object tags
+// @Description Get the tag set of the latest version of an object, or of version_id
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param version_id query string false "Version to read the tags of"
+// @Success 200 {object} domain.PutObjectTaggingRequest "Tag set"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Object or bucket not found"
+// @Failure 405 {object} map[string]interface{} "The version is a delete marker"
+// @Router /api/v1/buckets/{bucket}/tagging/{key} [get]
+func (h *ObjectHandler) GetObjectTagging(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		return
+	}
+
+	object, err := h.objectUseCase.HeadObject(bucket.ID, key, c.Query("version_id"))
+	if err != nil {
+		writeObjectUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, objectTagging(object))
+}
+
+// PutObjectTagging godoc
+// @Summary Set object tags
+// @Description Replace the tag set of the latest version of an object, or of version_id, without rewriting its content. At most 10 tags, with keys of up to 128 and values of up to 256 characters; keys may not start with aws:.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param request body domain.PutObjectTaggingRequest true "Tag set"
+// @Success 200 {object} domain.PutObjectTaggingRequest "Tag set"
+// @Failure 400 {object} map[string]interface{} "Invalid tag set"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Object or bucket not found"
+// @Failure 405 {object} map[string]interface{} "The version is a delete marker"
+// @Router /api/v1/buckets/{bucket}/tagging/{key} [put]
+func (h *ObjectHandler) PutObjectTagging(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	var req domain.PutObjectTaggingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	object, err := h.objectUseCase.PutObjectTagging(bucket.ID, key, req.VersionID, req.Tags)
+	if err != nil {
+		writeObjectUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, objectTagging(object))
+}
+
+// DeleteObjectTagging godoc
+// @Summary Delete object tags
+// @Description Remove all tags from the latest version of an object, or from version_id
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param version_id query string false "Version to remove the tags from"
+// @Success 204 "Tags deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Object or bucket not found"
+// @Failure 405 {object} map[string]interface{} "The version is a delete marker"
+// @Router /api/v1/buckets/{bucket}/tagging/{key} [delete]
+func (h *ObjectHandler) DeleteObjectTagging(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	if _, err := h.objectUseCase.PutObjectTagging(bucket.ID, key, c.Query("version_id"), nil); err != nil {
+		writeObjectUpdateError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PutObjectMetadata godoc
+// @Summary Replace object metadata
+// @Description Replace the user metadata of the latest version of an object, or of version_id, in place. The content is not rewritten, so the ETag does not change. Keys are stored in lower case and returned as x-amz-meta-* headers; keys and values may total at most 2 KB.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param key path string true "Object key, may contain slashes"
+// @Param request body domain.PutObjectMetadataRequest true "User metadata"
+// @Success 200 {object} domain.Object "Object version with its new metadata"
+// @Failure 400 {object} map[string]interface{} "Metadata too large"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Object or bucket not found"
+// @Failure 405 {object} map[string]interface{} "The version is a delete marker"
+// @Router /api/v1/buckets/{bucket}/metadata/{key} [put]
+func (h *ObjectHandler) PutObjectMetadata(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	var req domain.PutObjectMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	object, err := h.objectUseCase.PutObjectMetadata(bucket.ID, key, req.VersionID, req.Metadata)
+	if err != nil {
+		writeObjectUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, object)
+}
+
+func objectTagging(object *domain.Object) *domain.PutObjectTaggingRequest {
+	tags := object.Tags
+	if tags == nil {
+		tags = domain.ObjectTags{}
+	}
+	return &domain.PutObjectTaggingRequest{VersionID: object.VersionID, Tags: tags}
+}
+
+func writeObjectUpdateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrObjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "object not found"})
+	case errors.Is(err, domain.ErrDeleteMarker):
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrMetadataTooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // ListObjectVersions godoc
 // @Summary List all versions of an object
 // @Description Get all versions of a specific object
@@ -294,12 +892,12 @@ func (h *ObjectHandler) ListObjects(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param bucket path string true "Bucket name"
-// @Param key path string true "Object key"
+// @Param key path string true "Object key, may contain slashes"
 // @Success 200 {object} map[string]interface{} "List of object versions"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 404 {object} map[string]interface{} "Bucket not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
-// @Router /api/v1/buckets/{bucket}/objects/{key}/versions [get]
+// @Router /api/v1/buckets/{bucket}/versions/{key} [get]
 func (h *ObjectHandler) ListObjectVersions(c *gin.Context) {
 	userID := c.MustGet("user_id").(uuid.UUID)
 	bucketName := c.Param("bucket")
@@ -328,15 +926,20 @@ func (h *ObjectHandler) ListObjectVersions(c *gin.Context) {
 
 // DeleteObject godoc
 // @Summary Delete an object
-// @Description Delete the latest version of an object from a bucket
+// @Description Delete an object from a bucket. In a versioned bucket this places a delete marker on top of the version history unless version_id names a version to remove permanently; the previous version then becomes the latest. In an unversioned bucket the object is removed.
 // @Tags objects
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param bucket path string true "Bucket name"
-// @Param key path string true "Object key"
+// @Param key path string true "Object key, may contain slashes"
+// @Param version_id query string false "Version to delete permanently"
+// @Param bypass_governance query bool false "Delete a version under GOVERNANCE retention; bucket owner only"
 // @Success 204 "Object deleted successfully"
+// @Header 204 {string} X-Delete-Marker "true when a delete marker was created or removed"
+// @Header 204 {string} X-Object-Version-ID "Version ID of the new delete marker, or of the deleted version"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner, or the version is locked"
 // @Failure 404 {object} map[string]interface{} "Object or bucket not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /api/v1/buckets/{bucket}/objects/{key} [delete]
@@ -346,16 +949,308 @@ func (h *ObjectHandler) DeleteObject(c *gin.Context) {
 	key := strings.TrimPrefix(c.Param("key"), "/")
 
 	// Get bucket
-	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		writeOwnedBucketError(c, err)
 		return
 	}
 
-	if err := h.objectUseCase.DeleteObject(bucket.ID, key); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	bypassGovernance, err := governanceBypass(bucket, userID, c.Query("bypass_governance") == "true")
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleted, err := h.objectUseCase.DeleteObject(bucket.ID, key, c.Query("version_id"), bypassGovernance)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrObjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "object not found"})
+		case errors.Is(err, domain.ErrObjectLocked):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
+	if deleted.DeleteMarker {
+		c.Header("X-Delete-Marker", "true")
+	}
+	if deleted.DeleteMarkerVersionID != "" {
+		c.Header("X-Object-Version-ID", deleted.DeleteMarkerVersionID)
+	} else if deleted.VersionID != "" {
+		c.Header("X-Object-Version-ID", deleted.VersionID)
+	}
 	c.Status(http.StatusNoContent)
 }
+
+// governanceBypass checks a request to bypass GOVERNANCE retention; only the
+// bucket owner holds that permission.
+func governanceBypass(bucket *domain.Bucket, userID uuid.UUID, requested bool) (bool, error) {
+	if requested && bucket.UserID != userID {
+		return false, domain.ErrAccessDenied
+	}
+	return requested, nil
+}
+
+// setDeleteMarkerHeader flags responses for reads that hit a delete marker.
+func setDeleteMarkerHeader(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrDeleteMarker) {
+		c.Header("X-Delete-Marker", "true")
+	}
+}
+
+// serveObjectContent writes the object body through http.ServeContent, which
+// answers Range requests with 206 (multipart/byteranges for several ranges)
+// and evaluates If-Match, If-None-Match, If-Modified-Since and
+// If-Unmodified-Since against the object's ETag and modification time.
+func serveObjectContent(c *gin.Context, object *domain.Object, content io.ReadSeeker) {
+	c.Header("ETag", quoteETag(object.ETag))
+	c.Header("Content-Type", object.ContentType)
+	http.ServeContent(c.Writer, c.Request, "", object.UpdatedAt, content)
+}
+
+// DeleteObjects godoc
+// @Summary Delete several objects
+// @Description Delete up to 1000 objects, or specific versions, in one request. Deletion runs in a single transaction and the outcome is reported per key; keys that do not exist count as deleted, and versions protected by Object Lock are reported as errors. In quiet mode only errors are returned.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.DeleteObjectsRequest true "Objects to delete"
+// @Success 200 {object} domain.DeleteObjectsResponse "Per-key results"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the bucket owner"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/delete [post]
+func (h *ObjectHandler) DeleteObjects(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.DeleteObjectsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeOwnedBucketError(c, err)
+		return
+	}
+
+	bypassGovernance, err := governanceBypass(bucket, userID, req.BypassGovernance)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.objectUseCase.DeleteObjects(bucket.ID, req.Objects, bypassGovernance)
+	if err != nil {
+		if errors.Is(err, domain.ErrTooManyKeys) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Quiet {
+		response.Deleted = []domain.DeletedObject{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// setObjectHeaders sets the headers shared by downloads and HEAD requests.
+func setObjectHeaders(c *gin.Context, object *domain.Object) {
+	c.Header("Content-Disposition", "attachment; filename=\""+object.Key+"\"")
+	c.Header("X-Object-Version-ID", object.VersionID)
+	setContentHeaders(c, object.ContentHeaders)
+	setEncryptionHeaders(c, object.ObjectEncryption)
+	setUserMetadataHeaders(c, object.Metadata)
+	if checksumModeEnabled(c) {
+		setChecksumHeaders(c, object.Checksums)
+	}
+}
+
+// contentHeaders reads the standard headers stored with an upload. The
+// aws-chunked coding only describes the transfer and is dropped.
+func contentHeaders(c *gin.Context) domain.ContentHeaders {
+	var encodings []string
+	for _, encoding := range strings.Split(c.GetHeader("Content-Encoding"), ",") {
+		if encoding = strings.TrimSpace(encoding); encoding != "" && encoding != "aws-chunked" {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	return domain.ContentHeaders{
+		CacheControl:       c.GetHeader("Cache-Control"),
+		ContentDisposition: c.GetHeader("Content-Disposition"),
+		ContentEncoding:    strings.Join(encodings, ","),
+	}
+}
+
+// checksumHeaders reads the x-amz-checksum-* headers of an upload.
+func checksumHeaders(c *gin.Context) domain.Checksums {
+	return domain.Checksums{
+		CRC32:  c.GetHeader("x-amz-checksum-crc32"),
+		CRC32C: c.GetHeader("x-amz-checksum-crc32c"),
+		SHA1:   c.GetHeader("x-amz-checksum-sha1"),
+		SHA256: c.GetHeader("x-amz-checksum-sha256"),
+	}
+}
+
+// checksumModeEnabled reports whether a read asked for the stored
+// checksums. They cover the whole object, so range reads never get them.
+func checksumModeEnabled(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader("x-amz-checksum-mode"), "ENABLED") && c.GetHeader("Range") == ""
+}
+
+func setChecksumHeaders(c *gin.Context, checksums domain.Checksums) {
+	for name, value := range map[string]string{
+		"x-amz-checksum-crc32":  checksums.CRC32,
+		"x-amz-checksum-crc32c": checksums.CRC32C,
+		"x-amz-checksum-sha1":   checksums.SHA1,
+		"x-amz-checksum-sha256": checksums.SHA256,
+	} {
+		if value != "" {
+			c.Header(name, value)
+		}
+	}
+}
+
+// encryptionHeaders reads the server-side encryption an upload asks for:
+// x-amz-server-side-encryption for SSE-S3, or the SSE-C key headers.
+func encryptionHeaders(c *gin.Context) (domain.EncryptionRequest, error) {
+	customerKey, err := customerKeyHeaders(c, "x-amz-server-side-encryption-customer-")
+	if err != nil {
+		return domain.EncryptionRequest{}, err
+	}
+
+	return domain.EncryptionRequest{
+		ServerSide:  c.GetHeader("x-amz-server-side-encryption"),
+		CustomerKey: customerKey,
+	}, nil
+}
+
+// customerKeyHeaders reads an SSE-C key from the algorithm, key and key-MD5
+// headers starting with prefix. It returns nil when none of them is set.
+func customerKeyHeaders(c *gin.Context, prefix string) ([]byte, error) {
+	algorithm := c.GetHeader(prefix + "algorithm")
+	encoded := c.GetHeader(prefix + "key")
+	keyMD5 := c.GetHeader(prefix + "key-MD5")
+	if algorithm == "" && encoded == "" && keyMD5 == "" {
+		return nil, nil
+	}
+
+	if algorithm != domain.SSEAlgorithmAES256 {
+		return nil, domain.ErrInvalidCustomerKey
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, domain.ErrInvalidCustomerKey
+	}
+	digest := md5.Sum(key)
+	if keyMD5 != base64.StdEncoding.EncodeToString(digest[:]) {
+		return nil, domain.ErrInvalidCustomerKey
+	}
+	return key, nil
+}
+
+// writeCustomerKeyError reports a read of an SSE-C version without its key,
+// or with the wrong one. It returns false for any other error.
+func writeCustomerKeyError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrCustomerKeyRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCustomerKeyMismatch):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// setEncryptionHeaders describes how the version is encrypted at rest.
+func setEncryptionHeaders(c *gin.Context, enc domain.ObjectEncryption) {
+	switch enc.SSE {
+	case domain.SSEModeS3:
+		c.Header("x-amz-server-side-encryption", domain.SSEAlgorithmAES256)
+	case domain.SSEModeC:
+		c.Header("x-amz-server-side-encryption-customer-algorithm", domain.SSEAlgorithmAES256)
+		c.Header("x-amz-server-side-encryption-customer-key-MD5", enc.CustomerKeyMD5)
+	}
+}
+
+// setContentHeaders returns the stored content headers that were given,
+// replacing any default already set.
+func setContentHeaders(c *gin.Context, headers domain.ContentHeaders) {
+	for name, value := range map[string]string{
+		"Cache-Control":       headers.CacheControl,
+		"Content-Disposition": headers.ContentDisposition,
+		"Content-Encoding":    headers.ContentEncoding,
+	} {
+		if value != "" {
+			c.Header(name, value)
+		}
+	}
+}
+
+// userMetadataHeaders collects the x-amz-meta-* request headers.
+func userMetadataHeaders(c *gin.Context) domain.UserMetadata {
+	metadata := make(domain.UserMetadata)
+	for name, values := range c.Request.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-meta-") && len(values) > 0 {
+			metadata[strings.TrimPrefix(lower, "x-amz-meta-")] = values[0]
+		}
+	}
+	return metadata
+}
+
+// setUserMetadataHeaders returns user metadata as it was given, one
+// x-amz-meta-* header per key.
+func setUserMetadataHeaders(c *gin.Context, metadata domain.UserMetadata) {
+	for k, v := range metadata {
+		c.Header("x-amz-meta-"+k, v)
+	}
+}
+
+// newUploadInfo records where an upload came from. The uploader is unset
+// for anonymous requests.
+func newUploadInfo(c *gin.Context, filename string) domain.UploadInfo {
+	info := domain.UploadInfo{
+		OriginalFilename: filename,
+		ClientIP:         c.ClientIP(),
+		UserAgent:        c.GetHeader("User-Agent"),
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(uuid.UUID); ok {
+			info.UploaderID = &id
+		}
+	}
+	return info
+}
+
+// newObjectHead stands in for the body on HEAD requests. http.ServeContent
+// only seeks it to learn the size and never reads from it on HEAD.
+func newObjectHead(object *domain.Object) io.ReadSeeker {
+	return io.NewSectionReader(strings.NewReader(""), 0, object.Size)
+}
+
+// optionalUserID returns the authenticated user, or uuid.Nil for anonymous
+// requests on routes that also serve public buckets.
+func optionalUserID(c *gin.Context) uuid.UUID {
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(uuid.UUID); ok {
+			return id
+		}
+	}
+	return uuid.Nil
+}
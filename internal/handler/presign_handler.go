@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"s3-like/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PresignHandler struct {
+	presignUseCase domain.PresignUseCase
+	bucketUseCase  domain.BucketUseCase
+}
+
+func NewPresignHandler(presignUseCase domain.PresignUseCase, bucketUseCase domain.BucketUseCase) *PresignHandler {
+	return &PresignHandler{
+		presignUseCase: presignUseCase,
+		bucketUseCase:  bucketUseCase,
+	}
+}
+
+// PresignObject godoc
+// @Summary Create a presigned URL
+// @Description Issue a time-limited SigV4 presigned URL on the S3 API for downloading (GET) or uploading (PUT) an object without credentials. Requires an active access key.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bucket path string true "Bucket name"
+// @Param request body domain.PresignRequest true "Presign parameters"
+// @Success 200 {object} domain.PresignResponse "Presigned URL"
+// @Failure 400 {object} map[string]interface{} "Invalid request or no active access key"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "PUT on a bucket the user does not own"
+// @Failure 404 {object} map[string]interface{} "Bucket not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /api/v1/buckets/{bucket}/presign [post]
+func (h *PresignHandler) PresignObject(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	var req domain.PresignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get bucket
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "bucket not found"})
+		return
+	}
+
+	// A public bucket may be read by anyone, but only its owner may upload
+	if req.Method == http.MethodPut && bucket.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrAccessDenied.Error()})
+		return
+	}
+
+	response, err := h.presignUseCase.PresignObject(userID, bucket, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidExpiry),
+			errors.Is(err, domain.ErrNoActiveAccessKey),
+			errors.Is(err, domain.ErrAccessKeyNotFound):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
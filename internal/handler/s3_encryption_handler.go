@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetBucketEncryption handles GET /{bucket}?encryption
+func (h *S3Handler) GetBucketEncryption(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	if bucket.DefaultEncryption == "" {
+		writeS3Error(c, s3ErrNoEncryptionConfiguration)
+		return
+	}
+
+	c.XML(http.StatusOK, serverSideEncryptionConfiguration{
+		Xmlns: s3XMLNamespace,
+		Rules: []serverSideEncryptionRule{{
+			ApplyServerSideEncryptionByDefault: s3EncryptionByDefault{SSEAlgorithm: bucket.DefaultEncryption},
+		}},
+	})
+}
+
+// PutBucketEncryption handles PUT /{bucket}?encryption
+func (h *S3Handler) PutBucketEncryption(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	body, ok := readS3Body(c)
+	if !ok {
+		return
+	}
+
+	var req serverSideEncryptionConfiguration
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Rules) != 1 {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	algorithm := req.Rules[0].ApplyServerSideEncryptionByDefault.SSEAlgorithm
+	if algorithm == "" {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	if _, err := h.bucketUseCase.PutBucketEncryption(userID, c.Param("bucket"), algorithm); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteBucketEncryption handles DELETE /{bucket}?encryption
+func (h *S3Handler) DeleteBucketEncryption(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if _, err := h.bucketUseCase.PutBucketEncryption(userID, c.Param("bucket"), ""); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
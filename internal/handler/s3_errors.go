@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"s3-like/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// s3Error is an error as reported on the S3 wire protocol.
+type s3Error struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+var (
+	s3ErrAccessDenied                  = s3Error{"AccessDenied", "Access Denied", http.StatusForbidden}
+	s3ErrBadDigest                     = s3Error{"BadDigest", "The Content-MD5 you specified did not match what we received.", http.StatusBadRequest}
+	s3ErrBucketAlreadyExists           = s3Error{"BucketAlreadyExists", "The requested bucket name is not available.", http.StatusConflict}
+	s3ErrBucketAlreadyOwnedByYou       = s3Error{"BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.", http.StatusConflict}
+	s3ErrContentSHA256Mismatch         = s3Error{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+	s3ErrCopyToItself                  = s3Error{"InvalidRequest", "This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata, storage class, website redirect location or encryption attributes.", http.StatusBadRequest}
+	s3ErrCustomerKeyRequired           = s3Error{"InvalidRequest", "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.", http.StatusBadRequest}
+	s3ErrEncryptionNotConfigured       = s3Error{"InvalidRequest", "Server Side Encryption with a server-managed key is not configured on this server.", http.StatusBadRequest}
+	s3ErrEntityTooSmall                = s3Error{"EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.", http.StatusBadRequest}
+	s3ErrIllegalVersioning             = s3Error{"IllegalVersioningConfigurationException", "The versioning configuration specified in the request is invalid.", http.StatusBadRequest}
+	s3ErrIncompleteBody                = s3Error{"IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.", http.StatusBadRequest}
+	s3ErrInternalError                 = s3Error{"InternalError", "We encountered an internal error. Please try again.", http.StatusInternalServerError}
+	s3ErrInvalidArgument               = s3Error{"InvalidArgument", "Invalid Argument", http.StatusBadRequest}
+	s3ErrInvalidBucketState            = s3Error{"InvalidBucketState", "Object Lock configuration cannot be enabled on existing buckets without versioning enabled.", http.StatusConflict}
+	s3ErrInvalidChecksum               = s3Error{"InvalidRequest", "Value for x-amz-checksum header is invalid.", http.StatusBadRequest}
+	s3ErrInvalidCustomerKey            = s3Error{"InvalidArgument", "The secret key was invalid for the specified algorithm.", http.StatusBadRequest}
+	s3ErrInvalidDigest                 = s3Error{"InvalidDigest", "The Content-MD5 you specified is not valid.", http.StatusBadRequest}
+	s3ErrInvalidEncryption             = s3Error{"InvalidArgument", "The encryption method specified is not supported.", http.StatusBadRequest}
+	s3ErrInvalidLifecycle              = s3Error{"InvalidArgument", "The lifecycle configuration is invalid.", http.StatusBadRequest}
+	s3ErrInvalidPart                   = s3Error{"InvalidPart", "One or more of the specified parts could not be found. The part might not have been uploaded, or the specified entity tag might not have matched the part's entity tag.", http.StatusBadRequest}
+	s3ErrInvalidPartOrder              = s3Error{"InvalidPartOrder", "The list of parts was not in ascending order. The parts list must be specified in order by part number.", http.StatusBadRequest}
+	s3ErrInvalidTag                    = s3Error{"InvalidTag", "The tag set you have provided is invalid.", http.StatusBadRequest}
+	s3ErrInvalidRetention              = s3Error{"InvalidArgument", "The retention mode or retain-until date is invalid.", http.StatusBadRequest}
+	s3ErrMalformedXML                  = s3Error{"MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.", http.StatusBadRequest}
+	s3ErrMetadataTooLarge              = s3Error{"MetadataTooLarge", "Your metadata headers exceed the maximum allowed metadata size.", http.StatusBadRequest}
+	s3ErrMethodNotAllowed              = s3Error{"MethodNotAllowed", "The specified method is not allowed against this resource.", http.StatusMethodNotAllowed}
+	s3ErrNoEncryptionConfiguration     = s3Error{"ServerSideEncryptionConfigurationNotFoundError", "The server side encryption configuration was not found", http.StatusNotFound}
+	s3ErrNoObjectLockConfiguration     = s3Error{"ObjectLockConfigurationNotFoundError", "Object Lock configuration does not exist for this bucket", http.StatusNotFound}
+	s3ErrNoSuchBucket                  = s3Error{"NoSuchBucket", "The specified bucket does not exist", http.StatusNotFound}
+	s3ErrNoSuchKey                     = s3Error{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	s3ErrNoSuchLifecycle               = s3Error{"NoSuchLifecycleConfiguration", "The lifecycle configuration does not exist.", http.StatusNotFound}
+	s3ErrNoSuchObjectLockConfiguration = s3Error{"NoSuchObjectLockConfiguration", "The specified object does not have a ObjectLock configuration", http.StatusNotFound}
+	s3ErrNoSuchUpload                  = s3Error{"NoSuchUpload", "The specified multipart upload does not exist. The upload ID might be invalid, or the multipart upload might have been aborted or completed.", http.StatusNotFound}
+	s3ErrNotImplemented                = s3Error{"NotImplemented", "A header you provided implies functionality that is not implemented", http.StatusNotImplemented}
+	s3ErrObjectLockNotEnabled          = s3Error{"InvalidRequest", "Bucket is missing Object Lock Configuration", http.StatusBadRequest}
+	s3ErrObjectLocked                  = s3Error{"AccessDenied", "Access Denied because object protected by object lock.", http.StatusForbidden}
+	s3ErrPreconditionFailed            = s3Error{"PreconditionFailed", "At least one of the preconditions you specified did not hold.", http.StatusPreconditionFailed}
+	s3ErrSignatureDoesNotMatch         = s3Error{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided. Check your key and signing method.", http.StatusForbidden}
+)
+
+// toS3Error maps a use case error onto the closest S3 error code.
+func toS3Error(err error) s3Error {
+	switch {
+	case errors.Is(err, domain.ErrBucketNotFound):
+		return s3ErrNoSuchBucket
+	case errors.Is(err, domain.ErrObjectNotFound), errors.Is(err, domain.ErrBlobNotFound), errors.Is(err, domain.ErrDeleteMarker):
+		return s3ErrNoSuchKey
+	case errors.Is(err, domain.ErrBucketAlreadyExists):
+		return s3ErrBucketAlreadyExists
+	case errors.Is(err, domain.ErrInvalidContinuation):
+		return s3ErrInvalidArgument
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return s3ErrPreconditionFailed
+	case errors.Is(err, domain.ErrCopyToItself):
+		return s3ErrCopyToItself
+	case errors.Is(err, domain.ErrTooManyKeys), errors.Is(err, domain.ErrNoParts):
+		return s3ErrMalformedXML
+	case errors.Is(err, domain.ErrInvalidVersioning):
+		return s3ErrIllegalVersioning
+	case errors.Is(err, domain.ErrLifecycleNotFound):
+		return s3ErrNoSuchLifecycle
+	case errors.Is(err, domain.ErrInvalidLifecycle):
+		return s3ErrInvalidLifecycle
+	case errors.Is(err, domain.ErrInvalidTag):
+		return s3ErrInvalidTag
+	case errors.Is(err, domain.ErrMetadataTooLarge):
+		return s3ErrMetadataTooLarge
+	case errors.Is(err, domain.ErrObjectLocked):
+		return s3ErrObjectLocked
+	case errors.Is(err, domain.ErrObjectLockNotEnabled):
+		return s3ErrObjectLockNotEnabled
+	case errors.Is(err, domain.ErrInvalidRetention), errors.Is(err, domain.ErrInvalidDefaultRetention):
+		return s3ErrInvalidRetention
+	case errors.Is(err, domain.ErrObjectLockVersioning):
+		return s3ErrInvalidBucketState
+	case errors.Is(err, domain.ErrUploadNotFound):
+		return s3ErrNoSuchUpload
+	case errors.Is(err, domain.ErrInvalidPartNumber):
+		return s3ErrInvalidArgument
+	case errors.Is(err, domain.ErrInvalidPart):
+		return s3ErrInvalidPart
+	case errors.Is(err, domain.ErrInvalidPartOrder):
+		return s3ErrInvalidPartOrder
+	case errors.Is(err, domain.ErrEntityTooSmall):
+		return s3ErrEntityTooSmall
+	case errors.Is(err, domain.ErrAccessDenied):
+		return s3ErrAccessDenied
+	case errors.Is(err, domain.ErrSignatureDoesNotMatch):
+		return s3ErrSignatureDoesNotMatch
+	case errors.Is(err, domain.ErrContentSHA256Mismatch):
+		return s3ErrContentSHA256Mismatch
+	case errors.Is(err, domain.ErrIncompleteChunkedBody), errors.Is(err, domain.ErrDecodedLengthMismatch), errors.Is(err, domain.ErrIncompleteBody):
+		return s3ErrIncompleteBody
+	case errors.Is(err, domain.ErrInvalidDigest):
+		return s3ErrInvalidDigest
+	case errors.Is(err, domain.ErrInvalidChecksum):
+		return s3ErrInvalidChecksum
+	case errors.Is(err, domain.ErrBadDigest):
+		return s3ErrBadDigest
+	case errors.Is(err, domain.ErrEncryptionNotConfigured):
+		return s3ErrEncryptionNotConfigured
+	case errors.Is(err, domain.ErrInvalidEncryption):
+		return s3ErrInvalidEncryption
+	case errors.Is(err, domain.ErrInvalidCustomerKey):
+		return s3ErrInvalidCustomerKey
+	case errors.Is(err, domain.ErrCustomerKeyRequired):
+		return s3ErrCustomerKeyRequired
+	case errors.Is(err, domain.ErrCustomerKeyMismatch):
+		return s3ErrAccessDenied
+	case errors.Is(err, domain.ErrSSECMultipart):
+		return s3ErrNotImplemented
+	default:
+		return s3ErrInternalError
+	}
+}
+
+func writeS3Error(c *gin.Context, s3err s3Error) {
+	requestID := uuid.New().String()
+	c.Header("x-amz-request-id", requestID)
+
+	// HEAD responses must not carry a body
+	if c.Request.Method == http.MethodHead {
+		c.AbortWithStatus(s3err.StatusCode)
+		return
+	}
+
+	c.XML(s3err.StatusCode, s3ErrorResponse{
+		Code:      s3err.Code,
+		Message:   s3err.Message,
+		Resource:  c.Request.URL.Path,
+		RequestID: requestID,
+	})
+	c.Abort()
+}
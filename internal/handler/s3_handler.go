@@ -0,0 +1,616 @@
+package handler
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"s3-like/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// S3Handler serves the S3 REST API with path-style addressing
+// (/{bucket} and /{bucket}/{key...}) on top of the same use cases as the
+// JSON API.
+type S3Handler struct {
+	bucketUseCase    domain.BucketUseCase
+	objectUseCase    domain.ObjectUseCase
+	multipartUseCase domain.MultipartUseCase
+	lifecycleUseCase domain.LifecycleUseCase
+}
+
+func NewS3Handler(bucketUseCase domain.BucketUseCase, objectUseCase domain.ObjectUseCase, multipartUseCase domain.MultipartUseCase, lifecycleUseCase domain.LifecycleUseCase) *S3Handler {
+	return &S3Handler{
+		bucketUseCase:    bucketUseCase,
+		objectUseCase:    objectUseCase,
+		multipartUseCase: multipartUseCase,
+		lifecycleUseCase: lifecycleUseCase,
+	}
+}
+
+// ListBuckets handles GET /
+func (h *S3Handler) ListBuckets(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	buckets, err := h.bucketUseCase.ListBuckets(userID)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	result := listAllMyBucketsResult{
+		Xmlns: s3XMLNamespace,
+		Owner: s3Owner{ID: userID.String()},
+	}
+	for _, bucket := range buckets {
+		result.Buckets = append(result.Buckets, s3Bucket{
+			Name:         bucket.Name,
+			CreationDate: bucket.CreatedAt.UTC(),
+		})
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+// CreateBucket handles PUT /{bucket}
+func (h *S3Handler) CreateBucket(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	if existing, err := h.bucketUseCase.GetBucket(userID, bucketName); err == nil && existing.UserID == userID {
+		writeS3Error(c, s3ErrBucketAlreadyOwnedByYou)
+		return
+	}
+
+	req := &domain.CreateBucketRequest{
+		Name:              bucketName,
+		ObjectLockEnabled: strings.EqualFold(c.GetHeader("x-amz-bucket-object-lock-enabled"), "true"),
+	}
+	if _, err := h.bucketUseCase.CreateBucket(userID, req); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Header("Location", "/"+bucketName)
+	c.Status(http.StatusOK)
+}
+
+// HeadBucket handles HEAD /{bucket}
+func (h *S3Handler) HeadBucket(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if _, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket")); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteBucket handles DELETE /{bucket}
+func (h *S3Handler) DeleteBucket(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.bucketUseCase.DeleteBucket(userID, c.Param("bucket")); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetBucketVersioning handles GET /{bucket}?versioning
+func (h *S3Handler) GetBucketVersioning(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	// A bucket that was never versioned reports no status at all
+	result := versioningConfiguration{Xmlns: s3XMLNamespace}
+	if bucket.Versioning != domain.VersioningUnversioned {
+		result.Status = string(bucket.Versioning)
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+// PutBucketVersioning handles PUT /{bucket}?versioning
+func (h *S3Handler) PutBucketVersioning(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req versioningConfiguration
+	if err := xml.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	if _, err := h.bucketUseCase.PutBucketVersioning(userID, c.Param("bucket"), domain.VersioningStatus(req.Status)); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ListObjects handles GET /{bucket}?list-type=2
+func (h *S3Handler) ListObjects(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	maxKeys := 1000
+	if v := c.Query("max-keys"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			writeS3Error(c, s3ErrInvalidArgument)
+			return
+		}
+		maxKeys = min(parsed, 1000)
+	}
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	response, err := h.objectUseCase.ListObjects(bucket.ID, &domain.ListObjectsRequest{
+		Prefix:            c.Query("prefix"),
+		Delimiter:         c.Query("delimiter"),
+		StartAfter:        c.Query("start-after"),
+		ContinuationToken: c.Query("continuation-token"),
+		MaxKeys:           maxKeys,
+	})
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	result := listBucketResultV2{
+		Xmlns:                 s3XMLNamespace,
+		Name:                  bucketName,
+		Prefix:                response.Prefix,
+		Delimiter:             response.Delimiter,
+		StartAfter:            c.Query("start-after"),
+		MaxKeys:               response.MaxKeys,
+		KeyCount:              response.KeyCount,
+		IsTruncated:           response.IsTruncated,
+		ContinuationToken:     c.Query("continuation-token"),
+		NextContinuationToken: response.NextContinuationToken,
+	}
+	for _, object := range response.Objects {
+		result.Contents = append(result.Contents, s3Object{
+			Key:          object.Key,
+			LastModified: object.UpdatedAt.UTC(),
+			ETag:         quoteETag(object.ETag),
+			Size:         object.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+	for _, prefix := range response.CommonPrefixes {
+		result.CommonPrefixes = append(result.CommonPrefixes, s3CommonPrefix{Prefix: prefix})
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+// PutObject handles PUT /{bucket}/{key}
+func (h *S3Handler) PutObject(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	lock, ok := s3ObjectLock(c)
+	if !ok {
+		writeS3Error(c, s3ErrInvalidRetention)
+		return
+	}
+
+	tags, err := parseTagging(c.GetHeader("x-amz-tagging"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	encryption, err := encryptionHeaders(c)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	response, err := h.objectUseCase.PutObject(bucket.ID, s3ObjectKey(c), c.Request.Body, &domain.PutObjectOptions{
+		ContentType:    c.GetHeader("Content-Type"),
+		ContentHeaders: contentHeaders(c),
+		ContentMD5:     c.GetHeader("Content-MD5"),
+		Checksums:      checksumHeaders(c),
+		Encryption:     encryption,
+		Metadata:       userMetadataHeaders(c),
+		Tags:           tags,
+		UploadInfo:     newUploadInfo(c, ""),
+		ObjectLock:     lock,
+	})
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Header("ETag", quoteETag(response.Object.ETag))
+	c.Header("x-amz-version-id", response.VersionID)
+	setChecksumHeaders(c, response.Object.Checksums)
+	setEncryptionHeaders(c, response.Object.ObjectEncryption)
+	c.Status(http.StatusOK)
+}
+
+// CopyObject handles PUT /{bucket}/{key} with x-amz-copy-source
+func (h *S3Handler) CopyObject(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	srcBucketName, srcKey, srcVersionID, ok := parseCopySource(c.GetHeader("x-amz-copy-source"))
+	if !ok {
+		writeS3Error(c, s3ErrInvalidArgument)
+		return
+	}
+
+	directive := c.GetHeader("x-amz-metadata-directive")
+	if directive == "" {
+		directive = domain.MetadataDirectiveCopy
+	}
+	if directive != domain.MetadataDirectiveCopy && directive != domain.MetadataDirectiveReplace {
+		writeS3Error(c, s3ErrInvalidArgument)
+		return
+	}
+
+	taggingDirective := c.GetHeader("x-amz-tagging-directive")
+	if taggingDirective == "" {
+		taggingDirective = domain.MetadataDirectiveCopy
+	}
+	if taggingDirective != domain.MetadataDirectiveCopy && taggingDirective != domain.MetadataDirectiveReplace {
+		writeS3Error(c, s3ErrInvalidArgument)
+		return
+	}
+	tags, err := parseTagging(c.GetHeader("x-amz-tagging"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	conditions := domain.CopyConditions{
+		IfMatch:     c.GetHeader("x-amz-copy-source-if-match"),
+		IfNoneMatch: c.GetHeader("x-amz-copy-source-if-none-match"),
+	}
+	for header, target := range map[string]**time.Time{
+		"x-amz-copy-source-if-modified-since":   &conditions.IfModifiedSince,
+		"x-amz-copy-source-if-unmodified-since": &conditions.IfUnmodifiedSince,
+	} {
+		if v := c.GetHeader(header); v != "" {
+			t, err := http.ParseTime(v)
+			if err != nil {
+				writeS3Error(c, s3ErrInvalidArgument)
+				return
+			}
+			*target = &t
+		}
+	}
+
+	encryption, err := encryptionHeaders(c)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+	sourceCustomerKey, err := customerKeyHeaders(c, "x-amz-copy-source-server-side-encryption-customer-")
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	srcBucket, err := h.bucketUseCase.GetBucket(userID, srcBucketName)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	dstBucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	response, err := h.objectUseCase.CopyObject(srcBucket.ID, srcKey, dstBucket.ID, s3ObjectKey(c), &domain.CopyObjectOptions{
+		SourceVersionID:   srcVersionID,
+		MetadataDirective: directive,
+		ContentType:       c.GetHeader("Content-Type"),
+		ContentHeaders:    contentHeaders(c),
+		Metadata:          userMetadataHeaders(c),
+		TaggingDirective:  taggingDirective,
+		Tags:              tags,
+		Conditions:        conditions,
+		Encryption:        encryption,
+		SourceCustomerKey: sourceCustomerKey,
+	})
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	if srcVersionID != "" {
+		c.Header("x-amz-copy-source-version-id", srcVersionID)
+	}
+	c.Header("x-amz-version-id", response.VersionID)
+	setEncryptionHeaders(c, response.Object.ObjectEncryption)
+	c.XML(http.StatusOK, copyObjectResult{
+		Xmlns:        s3XMLNamespace,
+		ETag:         quoteETag(response.Object.ETag),
+		LastModified: response.Object.UpdatedAt.UTC(),
+	})
+}
+
+// GetObject handles GET /{bucket}/{key}
+func (h *S3Handler) GetObject(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	customerKey, err := customerKeyHeaders(c, "x-amz-server-side-encryption-customer-")
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	object, file, err := h.openObject(bucket.ID, s3ObjectKey(c), c.Query("versionId"), customerKey)
+	if err != nil {
+		writeObjectReadError(c, object, err)
+		return
+	}
+	defer file.Close()
+
+	setS3ObjectHeaders(c, object)
+	serveObjectContent(c, object, file)
+}
+
+// HeadObject handles HEAD /{bucket}/{key}
+func (h *S3Handler) HeadObject(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	object, err := h.objectUseCase.HeadObject(bucket.ID, s3ObjectKey(c), c.Query("versionId"))
+	if err != nil {
+		writeObjectReadError(c, object, err)
+		return
+	}
+
+	setS3ObjectHeaders(c, object)
+	serveObjectContent(c, object, newObjectHead(object))
+}
+
+// DeleteObject handles DELETE /{bucket}/{key}
+func (h *S3Handler) DeleteObject(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	bypassGovernance, err := governanceBypass(bucket, userID, strings.EqualFold(c.GetHeader("x-amz-bypass-governance-retention"), "true"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	versionID := c.Query("versionId")
+	deleted, err := h.objectUseCase.DeleteObject(bucket.ID, s3ObjectKey(c), versionID, bypassGovernance)
+	if err != nil {
+		// Deleting a key that does not exist is not an error in S3
+		if !errors.Is(err, domain.ErrObjectNotFound) {
+			writeS3Error(c, toS3Error(err))
+			return
+		}
+		deleted = &domain.DeletedObject{VersionID: versionID}
+	}
+
+	if deleted.DeleteMarker {
+		c.Header("x-amz-delete-marker", "true")
+	}
+	if deleted.DeleteMarkerVersionID != "" {
+		c.Header("x-amz-version-id", deleted.DeleteMarkerVersionID)
+	} else if deleted.VersionID != "" {
+		c.Header("x-amz-version-id", deleted.VersionID)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteObjects handles POST /{bucket}?delete
+func (h *S3Handler) DeleteObjects(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	body, ok := readS3Body(c)
+	if !ok {
+		return
+	}
+
+	var req deleteObjectsRequest
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Objects) == 0 || len(req.Objects) > domain.MaxDeleteObjects {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	objects := make([]domain.ObjectIdentifier, 0, len(req.Objects))
+	for _, object := range req.Objects {
+		objects = append(objects, domain.ObjectIdentifier{Key: object.Key, VersionID: object.VersionID})
+	}
+
+	bypassGovernance, err := governanceBypass(bucket, userID, strings.EqualFold(c.GetHeader("x-amz-bypass-governance-retention"), "true"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	response, err := h.objectUseCase.DeleteObjects(bucket.ID, objects, bypassGovernance)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	result := deleteResult{Xmlns: s3XMLNamespace}
+	if !req.Quiet {
+		for _, deleted := range response.Deleted {
+			result.Deleted = append(result.Deleted, s3DeletedObject{
+				Key:                   deleted.Key,
+				VersionID:             deleted.VersionID,
+				DeleteMarker:          deleted.DeleteMarker,
+				DeleteMarkerVersionID: deleted.DeleteMarkerVersionID,
+			})
+		}
+	}
+	for _, failed := range response.Errors {
+		s3err := toS3Error(failed.Err)
+		result.Errors = append(result.Errors, s3DeleteError{
+			Key:       failed.Key,
+			VersionID: failed.VersionID,
+			Code:      s3err.Code,
+			Message:   s3err.Message,
+		})
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+// readS3Body reads a small request body and checks it against Content-MD5
+// when the header is present. On failure the error response has been
+// written.
+func readS3Body(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return nil, false
+	}
+
+	if contentMD5 := c.GetHeader("Content-MD5"); contentMD5 != "" {
+		sum := md5.Sum(body)
+		if contentMD5 != base64.StdEncoding.EncodeToString(sum[:]) {
+			writeS3Error(c, s3ErrBadDigest)
+			return nil, false
+		}
+	}
+
+	return body, true
+}
+
+// BucketRoute dispatches /{bucket}/ with an empty key to the bucket handlers,
+// everything else to the object handlers.
+func (h *S3Handler) BucketRoute(bucketHandler, objectHandler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s3ObjectKey(c) == "" {
+			bucketHandler(c)
+			return
+		}
+		objectHandler(c)
+	}
+}
+
+// openObject opens a specific version when versionID is set, the latest
+// version otherwise.
+func (h *S3Handler) openObject(bucketID uuid.UUID, key, versionID string, customerKey []byte) (*domain.Object, io.ReadSeekCloser, error) {
+	if versionID != "" {
+		return h.objectUseCase.GetObjectVersion(bucketID, key, versionID, customerKey)
+	}
+	return h.objectUseCase.GetObject(bucketID, key, customerKey)
+}
+
+// writeObjectReadError reports a failed GET or HEAD. A delete marker is
+// flagged with x-amz-delete-marker; addressing one by version ID is not
+// allowed, while hitting it as the latest version means the key is gone.
+func writeObjectReadError(c *gin.Context, object *domain.Object, err error) {
+	if !errors.Is(err, domain.ErrDeleteMarker) {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Header("x-amz-delete-marker", "true")
+	c.Header("x-amz-version-id", object.VersionID)
+	if c.Query("versionId") != "" {
+		c.Header("Last-Modified", object.UpdatedAt.UTC().Format(http.TimeFormat))
+		writeS3Error(c, s3ErrMethodNotAllowed)
+		return
+	}
+	writeS3Error(c, s3ErrNoSuchKey)
+}
+
+// parseCopySource splits an x-amz-copy-source value, "[/]bucket/key" with an
+// optional "?versionId=" and URL-encoded key, into its parts.
+func parseCopySource(source string) (bucket, key, versionID string, ok bool) {
+	source, query, _ := strings.Cut(source, "?")
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", "", "", false
+		}
+		versionID = values.Get("versionId")
+	}
+
+	source, err := url.PathUnescape(strings.TrimPrefix(source, "/"))
+	if err != nil {
+		return "", "", "", false
+	}
+
+	bucket, key, found := strings.Cut(source, "/")
+	if !found || bucket == "" || key == "" {
+		return "", "", "", false
+	}
+	return bucket, key, versionID, true
+}
+
+func s3ObjectKey(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("key"), "/")
+}
+
+func setS3ObjectHeaders(c *gin.Context, object *domain.Object) {
+	c.Header("ETag", quoteETag(object.ETag))
+	c.Header("Last-Modified", object.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.Header("x-amz-version-id", object.VersionID)
+	setContentHeaders(c, object.ContentHeaders)
+	setEncryptionHeaders(c, object.ObjectEncryption)
+	setUserMetadataHeaders(c, object.Metadata)
+	if checksumModeEnabled(c) {
+		setChecksumHeaders(c, object.Checksums)
+	}
+	if len(object.Tags) > 0 {
+		c.Header("x-amz-tagging-count", strconv.Itoa(len(object.Tags)))
+	}
+	setS3ObjectLockHeaders(c, object)
+}
+
+func quoteETag(etag string) string {
+	return "\"" + etag + "\""
+}
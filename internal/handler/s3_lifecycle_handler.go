@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"s3-like/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetBucketLifecycle handles GET /{bucket}?lifecycle
+func (h *S3Handler) GetBucketLifecycle(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	lifecycle, err := h.lifecycleUseCase.GetBucketLifecycle(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	result := lifecycleConfiguration{Xmlns: s3XMLNamespace}
+	for _, rule := range lifecycle.Rules {
+		result.Rules = append(result.Rules, toS3LifecycleRule(rule))
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+// PutBucketLifecycle handles PUT /{bucket}?lifecycle
+func (h *S3Handler) PutBucketLifecycle(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	body, ok := readS3Body(c)
+	if !ok {
+		return
+	}
+
+	var req lifecycleConfiguration
+	if err := xml.Unmarshal(body, &req); err != nil || len(req.Rules) == 0 {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	rules := make([]domain.LifecycleRule, 0, len(req.Rules))
+	for _, rule := range req.Rules {
+		rules = append(rules, fromS3LifecycleRule(rule))
+	}
+
+	if _, err := h.lifecycleUseCase.PutBucketLifecycle(userID, c.Param("bucket"), rules); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DeleteBucketLifecycle handles DELETE /{bucket}?lifecycle
+func (h *S3Handler) DeleteBucketLifecycle(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.lifecycleUseCase.DeleteBucketLifecycle(userID, c.Param("bucket")); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func fromS3LifecycleRule(rule s3LifecycleRule) domain.LifecycleRule {
+	result := domain.LifecycleRule{
+		ID:     rule.ID,
+		Status: rule.Status,
+	}
+
+	switch {
+	case rule.Filter != nil && rule.Filter.And != nil:
+		and := rule.Filter.And
+		result.Filter = domain.LifecycleFilter{
+			Prefix:                and.Prefix,
+			ObjectSizeGreaterThan: and.ObjectSizeGreaterThan,
+			ObjectSizeLessThan:    and.ObjectSizeLessThan,
+		}
+		for _, tag := range and.Tags {
+			if result.Filter.Tags == nil {
+				result.Filter.Tags = make(map[string]string)
+			}
+			result.Filter.Tags[tag.Key] = tag.Value
+		}
+	case rule.Filter != nil:
+		result.Filter = domain.LifecycleFilter{
+			Prefix:                rule.Filter.Prefix,
+			ObjectSizeGreaterThan: rule.Filter.ObjectSizeGreaterThan,
+			ObjectSizeLessThan:    rule.Filter.ObjectSizeLessThan,
+		}
+		if rule.Filter.Tag != nil {
+			result.Filter.Tags = map[string]string{rule.Filter.Tag.Key: rule.Filter.Tag.Value}
+		}
+	case rule.Prefix != nil:
+		result.Filter.Prefix = *rule.Prefix
+	}
+
+	if exp := rule.Expiration; exp != nil {
+		result.Expiration = &domain.LifecycleExpiration{
+			Days:                      exp.Days,
+			ExpiredObjectDeleteMarker: exp.ExpiredObjectDeleteMarker,
+		}
+	}
+	if nve := rule.NoncurrentVersionExpiration; nve != nil {
+		result.NoncurrentVersionExpiration = &domain.NoncurrentVersionExpiration{
+			NoncurrentDays:          nve.NoncurrentDays,
+			NewerNoncurrentVersions: nve.NewerNoncurrentVersions,
+		}
+	}
+	if abort := rule.AbortIncompleteMultipartUpload; abort != nil {
+		result.AbortIncompleteMultipartUpload = &domain.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: abort.DaysAfterInitiation,
+		}
+	}
+
+	return result
+}
+
+// toS3LifecycleRule writes a rule back with a Filter element, combining the
+// conditions under And when there is more than one.
+func toS3LifecycleRule(rule domain.LifecycleRule) s3LifecycleRule {
+	result := s3LifecycleRule{
+		ID:     rule.ID,
+		Status: rule.Status,
+		Filter: &s3LifecycleFilter{},
+	}
+
+	filter := rule.Filter
+	tags := toS3Tags(filter.Tags)
+
+	conditions := len(tags)
+	for _, set := range []bool{filter.Prefix != "", filter.ObjectSizeGreaterThan > 0, filter.ObjectSizeLessThan > 0} {
+		if set {
+			conditions++
+		}
+	}
+
+	if conditions > 1 {
+		result.Filter.And = &s3LifecycleFilterAnd{
+			Prefix:                filter.Prefix,
+			Tags:                  tags,
+			ObjectSizeGreaterThan: filter.ObjectSizeGreaterThan,
+			ObjectSizeLessThan:    filter.ObjectSizeLessThan,
+		}
+	} else {
+		result.Filter.Prefix = filter.Prefix
+		result.Filter.ObjectSizeGreaterThan = filter.ObjectSizeGreaterThan
+		result.Filter.ObjectSizeLessThan = filter.ObjectSizeLessThan
+		if len(tags) == 1 {
+			result.Filter.Tag = &tags[0]
+		}
+	}
+
+	if exp := rule.Expiration; exp != nil {
+		result.Expiration = &s3LifecycleExpiration{
+			Days:                      exp.Days,
+			ExpiredObjectDeleteMarker: exp.ExpiredObjectDeleteMarker,
+		}
+	}
+	if nve := rule.NoncurrentVersionExpiration; nve != nil {
+		result.NoncurrentVersionExpiration = &s3NoncurrentVersionExpiration{
+			NoncurrentDays:          nve.NoncurrentDays,
+			NewerNoncurrentVersions: nve.NewerNoncurrentVersions,
+		}
+	}
+	if abort := rule.AbortIncompleteMultipartUpload; abort != nil {
+		result.AbortIncompleteMultipartUpload = &s3AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: abort.DaysAfterInitiation,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"s3-like/internal/domain"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateMultipartUpload handles POST /{bucket}/{key}?uploads
+func (h *S3Handler) CreateMultipartUpload(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	tags, err := parseTagging(c.GetHeader("x-amz-tagging"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	lock, ok := s3ObjectLock(c)
+	if !ok {
+		writeS3Error(c, s3ErrInvalidRetention)
+		return
+	}
+
+	encryption, err := encryptionHeaders(c)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	key := s3ObjectKey(c)
+	upload, err := h.multipartUseCase.CreateMultipartUpload(bucket.ID, key, &domain.PutObjectOptions{
+		ContentType:    c.GetHeader("Content-Type"),
+		ContentHeaders: contentHeaders(c),
+		Encryption:     encryption,
+		Metadata:       userMetadataHeaders(c),
+		Tags:           tags,
+		UploadInfo:     newUploadInfo(c, ""),
+		ObjectLock:     lock,
+	})
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	setEncryptionHeaders(c, upload.ObjectEncryption)
+	c.XML(http.StatusOK, initiateMultipartUploadResult{
+		Xmlns:    s3XMLNamespace,
+		Bucket:   bucketName,
+		Key:      key,
+		UploadID: upload.ID.String(),
+	})
+}
+
+// UploadPart handles PUT /{bucket}/{key}?partNumber=N&uploadId=ID
+func (h *S3Handler) UploadPart(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	uploadID, ok := s3UploadID(c)
+	if !ok {
+		writeS3Error(c, s3ErrNoSuchUpload)
+		return
+	}
+
+	partNumber, err := strconv.Atoi(c.Query("partNumber"))
+	if err != nil {
+		writeS3Error(c, s3ErrInvalidArgument)
+		return
+	}
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	part, err := h.multipartUseCase.UploadPart(bucket.ID, uploadID, partNumber, c.Request.Body, &domain.UploadPartOptions{
+		ContentMD5: c.GetHeader("Content-MD5"),
+		Checksums:  checksumHeaders(c),
+	})
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Header("ETag", quoteETag(part.ETag))
+	setChecksumHeaders(c, part.Checksums)
+	c.Status(http.StatusOK)
+}
+
+// ListParts handles GET /{bucket}/{key}?uploadId=ID
+func (h *S3Handler) ListParts(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	uploadID, ok := s3UploadID(c)
+	if !ok {
+		writeS3Error(c, s3ErrNoSuchUpload)
+		return
+	}
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	upload, parts, err := h.multipartUseCase.ListParts(bucket.ID, uploadID)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	result := listPartsResult{
+		Xmlns:        s3XMLNamespace,
+		Bucket:       bucketName,
+		Key:          upload.Key,
+		UploadID:     upload.ID.String(),
+		StorageClass: "STANDARD",
+		MaxParts:     len(parts),
+	}
+	for _, part := range parts {
+		result.Parts = append(result.Parts, s3Part{
+			PartNumber:   part.PartNumber,
+			LastModified: part.UpdatedAt.UTC(),
+			ETag:         quoteETag(part.ETag),
+			Size:         part.Size,
+		})
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+// CompleteMultipartUpload handles POST /{bucket}/{key}?uploadId=ID
+func (h *S3Handler) CompleteMultipartUpload(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	uploadID, ok := s3UploadID(c)
+	if !ok {
+		writeS3Error(c, s3ErrNoSuchUpload)
+		return
+	}
+
+	var req completeMultipartUpload
+	if err := xml.NewDecoder(c.Request.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, bucketName)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	parts := make([]domain.CompletedPart, 0, len(req.Parts))
+	for _, part := range req.Parts {
+		parts = append(parts, domain.CompletedPart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+
+	response, err := h.multipartUseCase.CompleteMultipartUpload(bucket.ID, uploadID, parts)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Header("x-amz-version-id", response.VersionID)
+	c.XML(http.StatusOK, completeMultipartUploadResult{
+		Xmlns:    s3XMLNamespace,
+		Location: "/" + bucketName + "/" + response.Object.Key,
+		Bucket:   bucketName,
+		Key:      response.Object.Key,
+		ETag:     quoteETag(response.Object.ETag),
+	})
+}
+
+// AbortMultipartUpload handles DELETE /{bucket}/{key}?uploadId=ID
+func (h *S3Handler) AbortMultipartUpload(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	uploadID, ok := s3UploadID(c)
+	if !ok {
+		writeS3Error(c, s3ErrNoSuchUpload)
+		return
+	}
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	if err := h.multipartUseCase.AbortMultipartUpload(bucket.ID, uploadID); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMultipartUploads handles GET /{bucket}?uploads
+func (h *S3Handler) ListMultipartUploads(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	bucketName := c.Param("bucket")
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, bucketName)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	uploads, err := h.multipartUseCase.ListMultipartUploads(bucket.ID)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	result := listMultipartUploadsResult{
+		Xmlns:      s3XMLNamespace,
+		Bucket:     bucketName,
+		MaxUploads: len(uploads),
+	}
+	for _, upload := range uploads {
+		result.Uploads = append(result.Uploads, s3Upload{
+			Key:          upload.Key,
+			UploadID:     upload.ID.String(),
+			Initiated:    upload.CreatedAt.UTC(),
+			StorageClass: "STANDARD",
+		})
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+func s3UploadID(c *gin.Context) (uuid.UUID, bool) {
+	uploadID, err := uuid.Parse(c.Query("uploadId"))
+	return uploadID, err == nil
+}
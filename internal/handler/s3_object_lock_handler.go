@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"s3-like/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetObjectLockConfiguration handles GET /{bucket}?object-lock
+func (h *S3Handler) GetObjectLockConfiguration(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	if !bucket.ObjectLockEnabled {
+		writeS3Error(c, s3ErrNoObjectLockConfiguration)
+		return
+	}
+
+	result := objectLockConfiguration{Xmlns: s3XMLNamespace, ObjectLockEnabled: "Enabled"}
+	if retention := bucket.DefaultRetention; retention.Mode != "" {
+		result.Rule = &objectLockRule{DefaultRetention: s3DefaultRetention{
+			Mode:  retention.Mode,
+			Days:  retention.Days,
+			Years: retention.Years,
+		}}
+	}
+
+	c.XML(http.StatusOK, result)
+}
+
+// PutObjectLockConfiguration handles PUT /{bucket}?object-lock
+func (h *S3Handler) PutObjectLockConfiguration(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	body, ok := readS3Body(c)
+	if !ok {
+		return
+	}
+
+	var req objectLockConfiguration
+	if err := xml.Unmarshal(body, &req); err != nil {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	config := &domain.ObjectLockConfigurationRequest{ObjectLockEnabled: req.ObjectLockEnabled == "Enabled"}
+	if req.Rule != nil {
+		config.DefaultRetention = &domain.DefaultRetention{
+			Mode:  req.Rule.DefaultRetention.Mode,
+			Days:  req.Rule.DefaultRetention.Days,
+			Years: req.Rule.DefaultRetention.Years,
+		}
+	}
+
+	if _, err := h.bucketUseCase.PutObjectLockConfiguration(userID, c.Param("bucket"), config); err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetObjectRetention handles GET /{bucket}/{key}?retention
+func (h *S3Handler) GetObjectRetention(c *gin.Context) {
+	object, ok := h.lockedObject(c)
+	if !ok {
+		return
+	}
+
+	if object.RetentionMode == "" {
+		writeS3Error(c, s3ErrNoSuchObjectLockConfiguration)
+		return
+	}
+
+	c.XML(http.StatusOK, s3Retention{
+		Xmlns:           s3XMLNamespace,
+		Mode:            object.RetentionMode,
+		RetainUntilDate: object.RetainUntil,
+	})
+}
+
+// PutObjectRetention handles PUT /{bucket}/{key}?retention. An empty
+// Retention element removes the retention.
+func (h *S3Handler) PutObjectRetention(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	body, ok := readS3Body(c)
+	if !ok {
+		return
+	}
+
+	var req s3Retention
+	if err := xml.Unmarshal(body, &req); err != nil {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	bypassGovernance, err := governanceBypass(bucket, userID, strings.EqualFold(c.GetHeader("x-amz-bypass-governance-retention"), "true"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	object, err := h.objectUseCase.PutObjectRetention(bucket.ID, s3ObjectKey(c), c.Query("versionId"), req.Mode, req.RetainUntilDate, bypassGovernance)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Header("x-amz-version-id", object.VersionID)
+	c.Status(http.StatusOK)
+}
+
+// GetObjectLegalHold handles GET /{bucket}/{key}?legal-hold
+func (h *S3Handler) GetObjectLegalHold(c *gin.Context) {
+	object, ok := h.lockedObject(c)
+	if !ok {
+		return
+	}
+
+	c.XML(http.StatusOK, s3LegalHold{Xmlns: s3XMLNamespace, Status: legalHoldStatus(object.LegalHold)})
+}
+
+// PutObjectLegalHold handles PUT /{bucket}/{key}?legal-hold
+func (h *S3Handler) PutObjectLegalHold(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	body, ok := readS3Body(c)
+	if !ok {
+		return
+	}
+
+	var req s3LegalHold
+	if err := xml.Unmarshal(body, &req); err != nil || (req.Status != "ON" && req.Status != "OFF") {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	object, err := h.objectUseCase.PutObjectLegalHold(bucket.ID, s3ObjectKey(c), c.Query("versionId"), req.Status == "ON")
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	c.Header("x-amz-version-id", object.VersionID)
+	c.Status(http.StatusOK)
+}
+
+// lockedObject looks up the version addressed by the request in a bucket
+// with Object Lock enabled, writing the error response when there is none.
+func (h *S3Handler) lockedObject(c *gin.Context) (*domain.Object, bool) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return nil, false
+	}
+
+	if !bucket.ObjectLockEnabled {
+		writeS3Error(c, s3ErrObjectLockNotEnabled)
+		return nil, false
+	}
+
+	object, err := h.objectUseCase.HeadObject(bucket.ID, s3ObjectKey(c), c.Query("versionId"))
+	if err != nil {
+		writeObjectReadError(c, object, err)
+		return nil, false
+	}
+
+	return object, true
+}
+
+// s3ObjectLock reads the Object Lock headers of an upload.
+func s3ObjectLock(c *gin.Context) (domain.ObjectLock, bool) {
+	lock := domain.ObjectLock{
+		RetentionMode: c.GetHeader("x-amz-object-lock-mode"),
+		LegalHold:     c.GetHeader("x-amz-object-lock-legal-hold") == "ON",
+	}
+
+	if retainUntil := c.GetHeader("x-amz-object-lock-retain-until-date"); retainUntil != "" {
+		t, err := time.Parse(time.RFC3339, retainUntil)
+		if err != nil {
+			return domain.ObjectLock{}, false
+		}
+		lock.RetainUntil = &t
+	}
+
+	return lock, true
+}
+
+// setS3ObjectLockHeaders reports the Object Lock state of a version on GET
+// and HEAD.
+func setS3ObjectLockHeaders(c *gin.Context, object *domain.Object) {
+	if object.RetentionMode != "" && object.RetainUntil != nil {
+		c.Header("x-amz-object-lock-mode", object.RetentionMode)
+		c.Header("x-amz-object-lock-retain-until-date", object.RetainUntil.UTC().Format(time.RFC3339))
+	}
+	if object.LegalHold {
+		c.Header("x-amz-object-lock-legal-hold", legalHoldStatus(object.LegalHold))
+	}
+}
+
+func legalHoldStatus(legalHold bool) string {
+	if legalHold {
+		return "ON"
+	}
+	return "OFF"
+}
@@ -0,0 +1,131 @@
+package handler
+
+import "github.com/gin-gonic/gin"
+
+// S3 multiplexes several operations onto the same method and path, told
+// apart by subresource query parameters such as ?uploads or ?uploadId. The
+// entrypoints below pick the operation for each method.
+
+// BucketPut handles PUT /{bucket}
+func (h *S3Handler) BucketPut(c *gin.Context) {
+	switch {
+	case hasQuery(c, "versioning"):
+		h.PutBucketVersioning(c)
+	case hasQuery(c, "lifecycle"):
+		h.PutBucketLifecycle(c)
+	case hasQuery(c, "object-lock"):
+		h.PutObjectLockConfiguration(c)
+	case hasQuery(c, "encryption"):
+		h.PutBucketEncryption(c)
+	default:
+		h.CreateBucket(c)
+	}
+}
+
+// BucketGet handles GET /{bucket}
+func (h *S3Handler) BucketGet(c *gin.Context) {
+	switch {
+	case hasQuery(c, "uploads"):
+		h.ListMultipartUploads(c)
+	case hasQuery(c, "versioning"):
+		h.GetBucketVersioning(c)
+	case hasQuery(c, "lifecycle"):
+		h.GetBucketLifecycle(c)
+	case hasQuery(c, "object-lock"):
+		h.GetObjectLockConfiguration(c)
+	case hasQuery(c, "encryption"):
+		h.GetBucketEncryption(c)
+	default:
+		h.ListObjects(c)
+	}
+}
+
+// BucketPost handles POST /{bucket}
+func (h *S3Handler) BucketPost(c *gin.Context) {
+	switch {
+	case hasQuery(c, "delete"):
+		h.DeleteObjects(c)
+	default:
+		writeS3Error(c, s3ErrMethodNotAllowed)
+	}
+}
+
+// BucketDelete handles DELETE /{bucket}
+func (h *S3Handler) BucketDelete(c *gin.Context) {
+	switch {
+	case hasQuery(c, "lifecycle"):
+		h.DeleteBucketLifecycle(c)
+	case hasQuery(c, "encryption"):
+		h.DeleteBucketEncryption(c)
+	default:
+		h.DeleteBucket(c)
+	}
+}
+
+// ObjectGet handles GET /{bucket}/{key}
+func (h *S3Handler) ObjectGet(c *gin.Context) {
+	switch {
+	case hasQuery(c, "uploadId"):
+		h.ListParts(c)
+	case hasQuery(c, "tagging"):
+		h.GetObjectTagging(c)
+	case hasQuery(c, "retention"):
+		h.GetObjectRetention(c)
+	case hasQuery(c, "legal-hold"):
+		h.GetObjectLegalHold(c)
+	default:
+		h.GetObject(c)
+	}
+}
+
+// ObjectPut handles PUT /{bucket}/{key}
+func (h *S3Handler) ObjectPut(c *gin.Context) {
+	switch {
+	case hasQuery(c, "uploadId") && c.GetHeader("x-amz-copy-source") != "":
+		// UploadPartCopy
+		writeS3Error(c, s3ErrNotImplemented)
+	case hasQuery(c, "uploadId"):
+		h.UploadPart(c)
+	case hasQuery(c, "tagging"):
+		h.PutObjectTagging(c)
+	case hasQuery(c, "retention"):
+		h.PutObjectRetention(c)
+	case hasQuery(c, "legal-hold"):
+		h.PutObjectLegalHold(c)
+	case c.GetHeader("x-amz-copy-source") != "":
+		h.CopyObject(c)
+	default:
+		h.PutObject(c)
+	}
+}
+
+// ObjectPost handles POST /{bucket}/{key}
+func (h *S3Handler) ObjectPost(c *gin.Context) {
+	switch {
+	case hasQuery(c, "uploads"):
+		h.CreateMultipartUpload(c)
+	case hasQuery(c, "uploadId"):
+		h.CompleteMultipartUpload(c)
+	default:
+		writeS3Error(c, s3ErrMethodNotAllowed)
+	}
+}
+
+// ObjectDelete handles DELETE /{bucket}/{key}
+func (h *S3Handler) ObjectDelete(c *gin.Context) {
+	switch {
+	case hasQuery(c, "uploadId"):
+		h.AbortMultipartUpload(c)
+	case hasQuery(c, "tagging"):
+		h.DeleteObjectTagging(c)
+	default:
+		h.DeleteObject(c)
+	}
+}
+
+// hasQuery reports whether the query parameter is present, even when it has
+// no value (as in ?uploads).
+func hasQuery(c *gin.Context, name string) bool {
+	_, ok := c.GetQuery(name)
+	return ok
+}
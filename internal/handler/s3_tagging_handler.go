@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"s3-like/internal/domain"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetObjectTagging handles GET /{bucket}/{key}?tagging
+func (h *S3Handler) GetObjectTagging(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	bucket, err := h.bucketUseCase.GetBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return
+	}
+
+	object, err := h.objectUseCase.HeadObject(bucket.ID, s3ObjectKey(c), c.Query("versionId"))
+	if err != nil {
+		writeObjectReadError(c, object, err)
+		return
+	}
+
+	result := tagging{Xmlns: s3XMLNamespace, TagSet: toS3Tags(object.Tags)}
+
+	c.Header("x-amz-version-id", object.VersionID)
+	c.XML(http.StatusOK, result)
+}
+
+// PutObjectTagging handles PUT /{bucket}/{key}?tagging
+func (h *S3Handler) PutObjectTagging(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	body, ok := readS3Body(c)
+	if !ok {
+		return
+	}
+
+	var req tagging
+	if err := xml.Unmarshal(body, &req); err != nil {
+		writeS3Error(c, s3ErrMalformedXML)
+		return
+	}
+
+	tags := make(domain.ObjectTags, len(req.TagSet))
+	for _, tag := range req.TagSet {
+		if _, ok := tags[tag.Key]; ok {
+			writeS3Error(c, s3ErrInvalidTag)
+			return
+		}
+		tags[tag.Key] = tag.Value
+	}
+
+	object, ok := h.putObjectTagging(c, userID, tags)
+	if !ok {
+		return
+	}
+
+	c.Header("x-amz-version-id", object.VersionID)
+	c.Status(http.StatusOK)
+}
+
+// DeleteObjectTagging handles DELETE /{bucket}/{key}?tagging
+func (h *S3Handler) DeleteObjectTagging(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	object, ok := h.putObjectTagging(c, userID, nil)
+	if !ok {
+		return
+	}
+
+	c.Header("x-amz-version-id", object.VersionID)
+	c.Status(http.StatusNoContent)
+}
+
+// putObjectTagging replaces the tag set of the version addressed by the
+// request, writing the error response when it fails.
+func (h *S3Handler) putObjectTagging(c *gin.Context, userID uuid.UUID, tags domain.ObjectTags) (*domain.Object, bool) {
+	bucket, err := h.bucketUseCase.GetOwnedBucket(userID, c.Param("bucket"))
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return nil, false
+	}
+
+	object, err := h.objectUseCase.PutObjectTagging(bucket.ID, s3ObjectKey(c), c.Query("versionId"), tags)
+	if err != nil {
+		writeS3Error(c, toS3Error(err))
+		return nil, false
+	}
+
+	return object, true
+}
+
+// parseTagging reads a tag set in the URL query format of the x-amz-tagging
+// header, e.g. "project=alpha&team=storage".
+func parseTagging(value string) (domain.ObjectTags, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	values, err := url.ParseQuery(value)
+	if err != nil {
+		return nil, domain.ErrInvalidTag
+	}
+
+	tags := make(domain.ObjectTags, len(values))
+	for key, v := range values {
+		if len(v) != 1 {
+			return nil, domain.ErrInvalidTag
+		}
+		tags[key] = v[0]
+	}
+	return tags, nil
+}
+
+func toS3Tags(tags domain.ObjectTags) []s3Tag {
+	result := make([]s3Tag, 0, len(tags))
+	for key, value := range tags {
+		result = append(result, s3Tag{Key: key, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"net/http"
+	"s3-like/internal/domain"
 	"s3-like/internal/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func JWTAuth(jwtSecret string) gin.HandlerFunc {
@@ -30,3 +32,36 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalJWTAuth sets user_id when a valid bearer token is sent and lets
+// anonymous requests through, for routes that also serve public buckets.
+func OptionalJWTAuth(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		JWTAuth(jwtSecret)(c)
+	}
+}
+
+// RequireAdmin lets through only the users named in admins. It runs after
+// JWTAuth; with no admins configured, nobody is let through.
+func RequireAdmin(userRepo domain.UserRepository, admins []string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(admins))
+	for _, username := range admins {
+		allowed[username] = true
+	}
+
+	return func(c *gin.Context) {
+		user, err := userRepo.GetByID(c.MustGet("user_id").(uuid.UUID))
+		if err != nil || !allowed[user.Username] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
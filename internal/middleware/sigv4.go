@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/xml"
+	"net/http"
+	"s3-like/internal/domain"
+	"s3-like/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxClockSkew is how far a signed request's timestamp may drift from ours.
+const maxClockSkew = 15 * time.Minute
+
+type s3AuthError struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// sigV4Request holds the signature details of a request, whether they came
+// from the Authorization header or from a presigned URL's query string.
+type sigV4Request struct {
+	credential    utils.SigV4Credential
+	signedHeaders []string
+	signature     string
+	requestTime   time.Time
+	payloadHash   string
+	excludeQuery  []string
+}
+
+type sigV4Error struct {
+	status  int
+	code    string
+	message string
+}
+
+var errSigV4AccessDenied = &sigV4Error{http.StatusForbidden, "AccessDenied", "Access Denied"}
+
+// SigV4Auth authenticates S3 requests signed with AWS Signature Version 4
+// using the caller's access key pair, and sets the same user_id context key
+// as JWTAuth. Both the Authorization header and presigned URLs are accepted.
+func SigV4Auth(authUseCase domain.AuthUseCase, region string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req *sigV4Request
+		var authErr *sigV4Error
+		switch {
+		case c.Query(utils.SigV4QueryAlgorithm) != "":
+			req, authErr = parsePresignedRequest(c.Request)
+		case c.GetHeader("Authorization") != "":
+			req, authErr = parseSignedRequest(c.Request)
+		default:
+			authErr = errSigV4AccessDenied
+		}
+		if authErr != nil {
+			abortS3(c, authErr.status, authErr.code, authErr.message)
+			return
+		}
+
+		credential := req.credential
+		if credential.Region != region || credential.Service != "s3" {
+			abortS3(c, http.StatusBadRequest, "AuthorizationHeaderMalformed",
+				"the region or service in the credential scope is wrong; expecting '"+region+"/s3'")
+			return
+		}
+		if req.requestTime.UTC().Format(utils.SigV4DateFormat) != credential.Date {
+			abortS3(c, http.StatusBadRequest, "AuthorizationHeaderMalformed", "the credential date does not match the request date")
+			return
+		}
+
+		accessKey, err := authUseCase.GetAccessKey(credential.AccessKeyID)
+		if err != nil {
+			abortS3(c, http.StatusForbidden, "InvalidAccessKeyId", "The AWS Access Key Id you provided does not exist in our records.")
+			return
+		}
+
+		signingKey := utils.SigV4SigningKey(accessKey.SecretAccessKey, credential)
+		canonicalRequest := utils.SigV4CanonicalRequest(c.Request, req.signedHeaders, req.payloadHash, req.excludeQuery...)
+		expected := utils.SigV4Signature(signingKey, utils.SigV4StringToSign(req.requestTime, credential.Scope(), canonicalRequest))
+		if !hmac.Equal([]byte(expected), []byte(req.signature)) {
+			abortS3(c, http.StatusForbidden, "SignatureDoesNotMatch",
+				"The request signature we calculated does not match the signature you provided. Check your key and signing method.")
+			return
+		}
+
+		switch req.payloadHash {
+		case utils.UnsignedPayload:
+		case utils.StreamingPayload:
+			decoded, err := strconv.ParseInt(c.GetHeader("X-Amz-Decoded-Content-Length"), 10, 64)
+			if err != nil || decoded < 0 {
+				decoded = -1
+			} else {
+				c.Request.ContentLength = decoded
+			}
+			c.Request.Body = utils.NewSigV4ChunkedReader(c.Request.Body, signingKey, req.requestTime, credential.Scope(), req.signature, decoded)
+			stripAWSChunkedEncoding(c.Request)
+		default:
+			if _, err := hex.DecodeString(req.payloadHash); err != nil || len(req.payloadHash) != 64 {
+				abortS3(c, http.StatusBadRequest, "InvalidArgument", "x-amz-content-sha256 must be UNSIGNED-PAYLOAD, STREAMING-AWS4-HMAC-SHA256-PAYLOAD, or a valid sha256 value.")
+				return
+			}
+			c.Request.Body = utils.NewSHA256VerifyingReader(c.Request.Body, req.payloadHash)
+		}
+
+		c.Set("user_id", accessKey.UserID)
+		c.Next()
+	}
+}
+
+func parseSignedRequest(r *http.Request) (*sigV4Request, *sigV4Error) {
+	auth, err := utils.ParseSigV4Authorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, &sigV4Error{http.StatusBadRequest, "AuthorizationHeaderMalformed", err.Error()}
+	}
+
+	requestTime, ok := sigV4RequestTime(r)
+	if !ok {
+		return nil, &sigV4Error{http.StatusForbidden, "AccessDenied", "AWS authentication requires a valid Date or x-amz-date header"}
+	}
+	dateHeader := "date"
+	if r.Header.Get("X-Amz-Date") != "" {
+		dateHeader = "x-amz-date"
+	}
+	if !utils.SigV4SignsHeader(auth.SignedHeaders, dateHeader) {
+		return nil, &sigV4Error{http.StatusBadRequest, "AuthorizationHeaderMalformed", "the " + dateHeader + " header must be signed"}
+	}
+	if skew := time.Since(requestTime); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, &sigV4Error{http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large."}
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		return nil, &sigV4Error{http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: x-amz-content-sha256"}
+	}
+
+	return &sigV4Request{
+		credential:    auth.Credential,
+		signedHeaders: auth.SignedHeaders,
+		signature:     auth.Signature,
+		requestTime:   requestTime,
+		payloadHash:   payloadHash,
+	}, nil
+}
+
+func parsePresignedRequest(r *http.Request) (*sigV4Request, *sigV4Error) {
+	query := r.URL.Query()
+	malformed := &sigV4Error{http.StatusBadRequest, "AuthorizationQueryParametersError", "Query-string authentication version 4 requires the X-Amz-Algorithm, X-Amz-Credential, X-Amz-Signature, X-Amz-Date, X-Amz-SignedHeaders, and X-Amz-Expires parameters."}
+
+	if query.Get(utils.SigV4QueryAlgorithm) != utils.SigV4Algorithm {
+		return nil, malformed
+	}
+
+	credential, err := utils.ParseSigV4Credential(query.Get(utils.SigV4QueryCredential))
+	if err != nil {
+		return nil, malformed
+	}
+
+	requestTime, err := time.Parse(utils.SigV4TimeFormat, query.Get(utils.SigV4QueryDate))
+	if err != nil {
+		return nil, malformed
+	}
+
+	expiresSeconds, err := strconv.ParseInt(query.Get(utils.SigV4QueryExpires), 10, 64)
+	if err != nil || expiresSeconds < 1 || time.Duration(expiresSeconds)*time.Second > utils.SigV4MaxPresignExpiry {
+		return nil, malformed
+	}
+
+	signedHeaders := strings.Split(query.Get(utils.SigV4QuerySignedHeaders), ";")
+	signature := query.Get(utils.SigV4QuerySignature)
+	if !utils.SigV4SignsHeader(signedHeaders, "host") || signature == "" {
+		return nil, malformed
+	}
+
+	now := time.Now()
+	if requestTime.After(now.Add(maxClockSkew)) {
+		return nil, &sigV4Error{http.StatusForbidden, "AccessDenied", "Request is not valid yet"}
+	}
+	if now.After(requestTime.Add(time.Duration(expiresSeconds) * time.Second)) {
+		return nil, &sigV4Error{http.StatusForbidden, "AccessDenied", "Request has expired"}
+	}
+
+	payloadHash := utils.UnsignedPayload
+	if v := query.Get("X-Amz-Content-Sha256"); v != "" {
+		payloadHash = v
+	}
+
+	return &sigV4Request{
+		credential:    *credential,
+		signedHeaders: signedHeaders,
+		signature:     signature,
+		requestTime:   requestTime,
+		payloadHash:   payloadHash,
+		excludeQuery:  []string{utils.SigV4QuerySignature},
+	}, nil
+}
+
+func sigV4RequestTime(r *http.Request) (time.Time, bool) {
+	if v := r.Header.Get("X-Amz-Date"); v != "" {
+		t, err := time.Parse(utils.SigV4TimeFormat, v)
+		return t, err == nil
+	}
+	if v := r.Header.Get("Date"); v != "" {
+		t, err := http.ParseTime(v)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
+
+// stripAWSChunkedEncoding removes the aws-chunked transport coding so it is
+// not mistaken for the object's own Content-Encoding.
+func stripAWSChunkedEncoding(r *http.Request) {
+	var encodings []string
+	for _, enc := range strings.Split(r.Header.Get("Content-Encoding"), ",") {
+		if enc = strings.TrimSpace(enc); enc != "" && enc != "aws-chunked" {
+			encodings = append(encodings, enc)
+		}
+	}
+
+	if len(encodings) == 0 {
+		r.Header.Del("Content-Encoding")
+		return
+	}
+	r.Header.Set("Content-Encoding", strings.Join(encodings, ","))
+}
+
+func abortS3(c *gin.Context, status int, code, message string) {
+	requestID := uuid.New().String()
+	c.Header("x-amz-request-id", requestID)
+
+	if c.Request.Method == http.MethodHead {
+		c.AbortWithStatus(status)
+		return
+	}
+
+	c.XML(status, s3AuthError{Code: code, Message: message, RequestID: requestID})
+	c.Abort()
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"s3-like/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type accessKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessKeyRepository(db *gorm.DB) domain.AccessKeyRepository {
+	return &accessKeyRepository{db: db}
+}
+
+func (r *accessKeyRepository) Create(key *domain.AccessKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *accessKeyRepository) GetByAccessKeyID(accessKeyID string) (*domain.AccessKey, error) {
+	var key domain.AccessKey
+	err := r.db.Where("access_key_id = ? AND is_revoked = false", accessKeyID).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrAccessKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *accessKeyRepository) GetByUserID(userID uuid.UUID) ([]domain.AccessKey, error) {
+	var keys []domain.AccessKey
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *accessKeyRepository) Revoke(userID uuid.UUID, accessKeyID string) error {
+	result := r.db.Model(&domain.AccessKey{}).
+		Where("user_id = ? AND access_key_id = ? AND is_revoked = false", userID, accessKeyID).
+		Update("is_revoked", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAccessKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+	"s3-like/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type blobRepository struct {
+	db *gorm.DB
+}
+
+func NewBlobRepository(db *gorm.DB) domain.BlobRepository {
+	return &blobRepository{db: db}
+}
+
+func (r *blobRepository) GetByHash(hash string) (*domain.Blob, error) {
+	var blob domain.Blob
+	err := r.db.Where("hash = ?", hash).First(&blob).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+func (r *blobRepository) List(afterHash string, limit int) ([]domain.Blob, error) {
+	var blobs []domain.Blob
+	err := r.db.Where("hash > ?", afterHash).Order("hash").Limit(limit).Find(&blobs).Error
+	return blobs, err
+}
+
+func (r *blobRepository) ListUnreferenced(limit int) ([]domain.Blob, error) {
+	var blobs []domain.Blob
+	err := r.db.Where("ref_count <= 0").Order("hash").Limit(limit).Find(&blobs).Error
+	return blobs, err
+}
+
+func (r *blobRepository) DeleteUnreferenced(hash string) error {
+	return r.db.Where("hash = ? AND ref_count <= 0", hash).Delete(&domain.Blob{}).Error
+}
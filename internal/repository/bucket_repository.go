@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"s3-like/internal/domain"
 
 	"github.com/google/uuid"
@@ -22,6 +23,9 @@ func (r *bucketRepository) Create(bucket *domain.Bucket) error {
 func (r *bucketRepository) GetByName(name string) (*domain.Bucket, error) {
 	var bucket domain.Bucket
 	err := r.db.Preload("User").Where("name = ?", name).First(&bucket).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrBucketNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +35,9 @@ func (r *bucketRepository) GetByName(name string) (*domain.Bucket, error) {
 func (r *bucketRepository) GetByID(id uuid.UUID) (*domain.Bucket, error) {
 	var bucket domain.Bucket
 	err := r.db.Preload("User").Where("id = ?", id).First(&bucket).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrBucketNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"s3-like/internal/domain"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type lifecycleRepository struct {
+	db *gorm.DB
+}
+
+func NewLifecycleRepository(db *gorm.DB) domain.LifecycleRepository {
+	return &lifecycleRepository{db: db}
+}
+
+func (r *lifecycleRepository) Get(bucketID uuid.UUID) (*domain.BucketLifecycle, error) {
+	var lifecycle domain.BucketLifecycle
+	err := r.db.Where("bucket_id = ?", bucketID).First(&lifecycle).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrLifecycleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lifecycle, nil
+}
+
+func (r *lifecycleRepository) List() ([]domain.BucketLifecycle, error) {
+	var lifecycles []domain.BucketLifecycle
+	err := r.db.Order("bucket_id").Find(&lifecycles).Error
+	return lifecycles, err
+}
+
+func (r *lifecycleRepository) Save(lifecycle *domain.BucketLifecycle) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bucket_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rules", "updated_at"}),
+	}).Create(lifecycle).Error
+}
+
+func (r *lifecycleRepository) Delete(bucketID uuid.UUID) error {
+	return r.db.Where("bucket_id = ?", bucketID).Delete(&domain.BucketLifecycle{}).Error
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"errors"
+	"s3-like/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type multipartUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewMultipartUploadRepository(db *gorm.DB) domain.MultipartUploadRepository {
+	return &multipartUploadRepository{db: db}
+}
+
+func (r *multipartUploadRepository) Create(upload *domain.MultipartUpload) error {
+	return r.db.Create(upload).Error
+}
+
+func (r *multipartUploadRepository) GetByID(id uuid.UUID) (*domain.MultipartUpload, error) {
+	var upload domain.MultipartUpload
+	err := r.db.Where("id = ?", id).First(&upload).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *multipartUploadRepository) ListByBucket(bucketID uuid.UUID) ([]domain.MultipartUpload, error) {
+	var uploads []domain.MultipartUpload
+	err := r.db.Where("bucket_id = ?", bucketID).Order("key, created_at").Find(&uploads).Error
+	return uploads, err
+}
+
+func (r *multipartUploadRepository) ListStale(before time.Time) ([]domain.MultipartUpload, error) {
+	var uploads []domain.MultipartUpload
+	err := r.db.Where("created_at < ?", before).Find(&uploads).Error
+	return uploads, err
+}
+
+func (r *multipartUploadRepository) ListStaleWrappedKeys(activeKeyID string, limit int) ([]domain.MultipartUpload, error) {
+	var uploads []domain.MultipartUpload
+	err := r.db.Where("sse = ? AND master_key_id <> ?", domain.SSEModeS3, activeKeyID).
+		Order("id").Limit(limit).Find(&uploads).Error
+	return uploads, err
+}
+
+func (r *multipartUploadRepository) UpdateWrappedKey(id uuid.UUID, masterKeyID string, wrappedKey []byte) error {
+	return r.db.Model(&domain.MultipartUpload{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"master_key_id": masterKeyID,
+		"wrapped_key":   wrappedKey,
+	}).Error
+}
+
+func (r *multipartUploadRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", id).Delete(&domain.MultipartPart{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.MultipartUpload{}, id).Error
+	})
+}
+
+func (r *multipartUploadRepository) Claim(id uuid.UUID) error {
+	result := r.db.Model(&domain.MultipartUpload{}).
+		Where("id = ? AND NOT completing", id).
+		Update("completing", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUploadNotFound
+	}
+	return nil
+}
+
+func (r *multipartUploadRepository) Unclaim(id uuid.UUID) error {
+	return r.db.Model(&domain.MultipartUpload{}).Where("id = ?", id).Update("completing", false).Error
+}
+
+func (r *multipartUploadRepository) GetPart(uploadID uuid.UUID, partNumber int) (*domain.MultipartPart, error) {
+	var part domain.MultipartPart
+	err := r.db.Where("upload_id = ? AND part_number = ?", uploadID, partNumber).First(&part).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvalidPart
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &part, nil
+}
+
+// SavePart inserts a part, replacing any earlier upload of the same part number.
+func (r *multipartUploadRepository) SavePart(part *domain.MultipartPart) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "upload_id"}, {Name: "part_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "e_tag", "storage_path", "updated_at"}),
+	}).Create(part).Error
+}
+
+func (r *multipartUploadRepository) ListParts(uploadID uuid.UUID) ([]domain.MultipartPart, error) {
+	var parts []domain.MultipartPart
+	err := r.db.Where("upload_id = ?", uploadID).Order("part_number").Find(&parts).Error
+	return parts, err
+}
@@ -1,10 +1,15 @@
 package repository
 
 import (
+	"encoding/json"
+	"errors"
 	"s3-like/internal/domain"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type objectRepository struct {
@@ -22,6 +27,9 @@ func (r *objectRepository) Create(object *domain.Object) error {
 func (r *objectRepository) GetByKey(bucketID uuid.UUID, key string) (*domain.Object, error) {
 	var object domain.Object
 	err := r.db.Where("bucket_id = ? AND key = ? AND is_latest = true", bucketID, key).First(&object).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrObjectNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -31,6 +39,9 @@ func (r *objectRepository) GetByKey(bucketID uuid.UUID, key string) (*domain.Obj
 func (r *objectRepository) GetByKeyAndVersion(bucketID uuid.UUID, key, versionID string) (*domain.Object, error) {
 	var object domain.Object
 	err := r.db.Where("bucket_id = ? AND key = ? AND version_id = ?", bucketID, key, versionID).First(&object).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrObjectNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -44,23 +55,120 @@ func (r *objectRepository) GetVersions(bucketID uuid.UUID, key string) ([]domain
 	return objects, err
 }
 
-func (r *objectRepository) List(bucketID uuid.UUID, prefix string, page, pageSize int) ([]domain.Object, int64, error) {
+func (r *objectRepository) List(bucketID uuid.UUID, prefix, startAfter string, tags domain.ObjectTags, limit int) ([]domain.Object, error) {
 	var objects []domain.Object
-	var total int64
 
-	query := r.db.Where("bucket_id = ? AND is_latest = true", bucketID)
+	// Keys are compared with the "C" collation so the order is plain byte
+	// order, matching S3 and the continuation tokens built from keys
+	query := r.db.Where("bucket_id = ? AND is_latest = true AND is_delete_marker = false", bucketID)
 	if prefix != "" {
-		query = query.Where("key LIKE ?", prefix+"%")
+		query = query.Where("key LIKE ?", escapeLike(prefix)+"%")
+	}
+	if startAfter != "" {
+		query = query.Where(`key COLLATE "C" > ?`, startAfter)
 	}
+	if len(tags) > 0 {
+		// jsonb containment: the object's tags include every requested pair
+		filter, err := json.Marshal(tags)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("tags @> ?::jsonb", string(filter))
+	}
+
+	err := query.Order(`key COLLATE "C"`).Limit(limit).Find(&objects).Error
+	return objects, err
+}
+
+func (r *objectRepository) ListKeys(bucketID uuid.UUID, prefix, startAfter string, limit int) ([]string, error) {
+	var keys []string
+
+	query := r.db.Model(&domain.Object{}).Where("bucket_id = ?", bucketID)
+	if prefix != "" {
+		query = query.Where("key LIKE ?", escapeLike(prefix)+"%")
+	}
+	if startAfter != "" {
+		query = query.Where(`key COLLATE "C" > ?`, startAfter)
+	}
+
+	err := query.Group("key").Order(`key COLLATE "C"`).Limit(limit).Pluck("key", &keys).Error
+	return keys, err
+}
+
+func (r *objectRepository) ListStaleWrappedKeys(activeKeyID string, limit int) ([]domain.Object, error) {
+	var objects []domain.Object
+	err := r.db.Where("sse = ? AND master_key_id <> ?", domain.SSEModeS3, activeKeyID).
+		Order("id").Limit(limit).Find(&objects).Error
+	return objects, err
+}
 
-	// Count total
-	query.Model(&domain.Object{}).Count(&total)
+// UpdateWrappedKey leaves updated_at alone: the content has not changed.
+func (r *objectRepository) UpdateWrappedKey(id uuid.UUID, masterKeyID string, wrappedKey []byte) error {
+	return r.db.Model(&domain.Object{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"master_key_id": masterKeyID,
+		"wrapped_key":   wrappedKey,
+	}).Error
+}
 
-	// Get paginated results
-	offset := (page - 1) * pageSize
-	err := query.Offset(offset).Limit(pageSize).Order("key").Find(&objects).Error
+func (r *objectRepository) AcquireBlob(hash string, size int64) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"ref_count":  gorm.Expr("blobs.ref_count + 1"),
+			"updated_at": time.Now(),
+		}),
+	}).Create(&domain.Blob{Hash: hash, Size: size, RefCount: 1}).Error
+}
+
+func (r *objectRepository) ReleaseBlob(hash string) error {
+	return r.db.Model(&domain.Blob{}).Where("hash = ?", hash).
+		Update("ref_count", gorm.Expr("ref_count - 1")).Error
+}
+
+func (r *objectRepository) ListUnshared(afterID uuid.UUID, limit int) ([]domain.Object, error) {
+	var objects []domain.Object
+	err := r.db.Where("COALESCE(blob_hash, '') = '' AND storage_path <> '' AND id > ?", afterID).
+		Order("id").Limit(limit).Find(&objects).Error
+	return objects, err
+}
 
-	return objects, total, err
+func (r *objectRepository) HasStoragePath(storagePath string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Object{}).Where("storage_path = ?", storagePath).Limit(1).Count(&count).Error
+	return count > 0, err
+}
+
+// RepairLatest treats the newest version, by creation time and then ID, as
+// the one that should be latest.
+func (r *objectRepository) RepairLatest() (int64, error) {
+	var repaired int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		demoted := tx.Exec(`UPDATE objects o SET is_latest = false
+			WHERE o.is_latest AND o.deleted_at IS NULL AND EXISTS (
+				SELECT 1 FROM objects n
+				WHERE n.bucket_id = o.bucket_id AND n.key = o.key
+					AND n.is_latest AND n.deleted_at IS NULL
+					AND (n.created_at, n.id) > (o.created_at, o.id))`)
+		if demoted.Error != nil {
+			return demoted.Error
+		}
+
+		promoted := tx.Exec(`UPDATE objects SET is_latest = true
+			WHERE id IN (
+				SELECT DISTINCT ON (o.bucket_id, o.key) o.id FROM objects o
+				WHERE o.deleted_at IS NULL AND NOT EXISTS (
+					SELECT 1 FROM objects l
+					WHERE l.bucket_id = o.bucket_id AND l.key = o.key
+						AND l.is_latest AND l.deleted_at IS NULL)
+				ORDER BY o.bucket_id, o.key, o.created_at DESC, o.id DESC)`)
+		if promoted.Error != nil {
+			return promoted.Error
+		}
+
+		repaired = demoted.RowsAffected + promoted.RowsAffected
+		return nil
+	})
+	return repaired, err
 }
 
 func (r *objectRepository) Update(object *domain.Object) error {
@@ -68,7 +176,7 @@ func (r *objectRepository) Update(object *domain.Object) error {
 }
 
 func (r *objectRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&domain.Object{}, id).Error
+	return r.db.Unscoped().Delete(&domain.Object{}, id).Error
 }
 
 func (r *objectRepository) MarkAsNotLatest(bucketID uuid.UUID, key string) error {
@@ -76,3 +184,20 @@ func (r *objectRepository) MarkAsNotLatest(bucketID uuid.UUID, key string) error
 		Where("bucket_id = ? AND key = ?", bucketID, key).
 		Update("is_latest", false).Error
 }
+
+// LockKey takes a transaction-scoped advisory lock on the key, so concurrent
+// writers demote and insert the latest version one at a time.
+func (r *objectRepository) LockKey(bucketID uuid.UUID, key string) error {
+	return r.db.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", bucketID.String()+"/"+key).Error
+}
+
+func (r *objectRepository) Transaction(fn func(repo domain.ObjectRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&objectRepository{db: tx})
+	})
+}
+
+// escapeLike escapes the LIKE wildcards so s matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder keeps the statements gorm would have sent to the database.
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+func TestObjectDeleteRemovesRow(t *testing.T) {
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	if err := NewObjectRepository(db).Delete(uuid.New()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// A soft delete would be an UPDATE setting deleted_at, leaving the row
+	// behind for every later query to skip
+	if len(recorder.statements) != 1 || !strings.HasPrefix(recorder.statements[0], `DELETE FROM "objects"`) {
+		t.Errorf("Delete ran %q, want a DELETE FROM objects", recorder.statements)
+	}
+}
@@ -0,0 +1,760 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"path/filepath"
+	"s3-like/internal/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+// Shard files are a header, then for each stripe the CRC-32C of the shard's
+// chunk followed by the chunk, then a trailer. The header carries an ID
+// shared by every shard of one write, so a shard left behind by an earlier
+// blob at the same path is never mixed into a later one. The trailer holds
+// the blob size, which is only known once the whole blob has been written,
+// and a checksum over it and the header.
+const (
+	shardMagic        = "S3EC"
+	shardHeaderSize   = 28
+	shardTrailerSize  = 12
+	shardChecksumSize = 4
+	// shardBlockSize is how much of each shard one stripe holds, so a stripe
+	// covers DataShards times as much of the blob
+	shardBlockSize = 256 << 10
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	errDamagedShard = errors.New("damaged shard")
+	errTooFewShards = errors.New("too few intact shards to reconstruct the blob")
+)
+
+// erasureBackend stripes every blob across several directories, one per
+// disk, with Reed-Solomon parity: any DataShards of the DataShards +
+// ParityShards shards are enough to read it back. Shard i of a blob is kept
+// on disk i at the blob's path, and each disk is a filesystem backend, so
+// every shard file is written atomically.
+type erasureBackend struct {
+	disks        []domain.StorageBackend
+	dataShards   int
+	parityShards int
+	enc          reedsolomon.Encoder
+}
+
+// NewErasureBackend opens a backend over one directory per shard, so
+// dataShards+parityShards of them.
+func NewErasureBackend(disks []string, dataShards, parityShards int) (domain.StorageBackend, error) {
+	if dataShards < 1 || parityShards < 1 {
+		return nil, fmt.Errorf("erasure coding needs at least one data and one parity shard")
+	}
+	if len(disks) != dataShards+parityShards {
+		return nil, fmt.Errorf("erasure coding %d+%d needs %d disks, got %d", dataShards, parityShards, dataShards+parityShards, len(disks))
+	}
+
+	enc, err := reedsolomon.New(dataShards, parityShards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create erasure encoder: %w", err)
+	}
+
+	b := &erasureBackend{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		enc:          enc,
+	}
+
+	seen := make(map[string]bool, len(disks))
+	for _, dir := range disks {
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			return nil, fmt.Errorf("disk %s is listed more than once", dir)
+		}
+		seen[dir] = true
+
+		disk, err := NewFilesystemBackend(dir)
+		if err != nil {
+			return nil, err
+		}
+		b.disks = append(b.disks, disk)
+	}
+
+	return b, nil
+}
+
+// Put streams r into a shard file on every disk. A disk may fail while the
+// others succeed, so the write only has to reach writeQuorum of them; the
+// shards it missed are rebuilt by a heal.
+func (b *erasureBackend) Put(ctx context.Context, p string, r io.Reader) (int64, error) {
+	header := shardHeader{
+		dataShards:   b.dataShards,
+		parityShards: b.parityShards,
+		blockSize:    shardBlockSize,
+	}
+	if _, err := rand.Read(header.writeID[:]); err != nil {
+		return 0, fmt.Errorf("failed to generate write ID: %w", err)
+	}
+
+	w := b.newShardWriters(ctx, p, b.allShards())
+	size, err := b.encode(&contextReader{ctx: ctx, r: r}, header, w)
+	errs := w.close(err)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	written := 0
+	var writeErr error
+	for _, err := range errs {
+		if err == nil {
+			written++
+		} else if writeErr == nil || errors.Is(err, domain.ErrInvalidPath) {
+			writeErr = err
+		}
+	}
+	if written < b.writeQuorum() {
+		b.Delete(ctx, p)
+		if errors.Is(writeErr, domain.ErrInvalidPath) {
+			return 0, writeErr
+		}
+		return 0, fmt.Errorf("only %d of %d shards were written: %w", written, len(b.disks), writeErr)
+	}
+
+	return size, nil
+}
+
+func (b *erasureBackend) Get(ctx context.Context, p string) (io.ReadCloser, error) {
+	return b.GetRange(ctx, p, 0, -1)
+}
+
+// GetRange only reads the stripes that hold the range.
+func (b *erasureBackend) GetRange(ctx context.Context, p string, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset %d", offset)
+	}
+
+	set, err := b.inspect(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	stripeSize := set.layout.stripeSize()
+	r := b.newReader(ctx, p, set, offset/stripeSize)
+	r.skip = offset % stripeSize
+	if length < 0 {
+		return r, nil
+	}
+
+	return &limitedReadCloser{Reader: io.LimitReader(r, length), Closer: r}, nil
+}
+
+// Copy decodes the blob and encodes it anew, which also restores any shard
+// of the source that was missing or damaged.
+func (b *erasureBackend) Copy(ctx context.Context, srcPath, dstPath string) (int64, error) {
+	src, err := b.Get(ctx, srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	return b.Put(ctx, dstPath, src)
+}
+
+// Rename moves each intact shard on its own disk. Disks without one have any
+// shard at either path removed, so nothing stale is left at dstPath.
+func (b *erasureBackend) Rename(ctx context.Context, srcPath, dstPath string) error {
+	set, err := b.inspect(ctx, srcPath)
+	if err != nil {
+		return err
+	}
+
+	moved := 0
+	var renameErr error
+	for i, disk := range b.disks {
+		if set.present[i] {
+			err := disk.Rename(ctx, srcPath, dstPath)
+			if err == nil {
+				moved++
+				continue
+			}
+			// Every disk rejects the path alike, so nothing has moved yet
+			if errors.Is(err, domain.ErrInvalidPath) {
+				return err
+			}
+			if renameErr == nil {
+				renameErr = err
+			}
+		}
+		disk.Delete(ctx, srcPath)
+		disk.Delete(ctx, dstPath)
+	}
+
+	if moved < b.dataShards {
+		return fmt.Errorf("only %d of %d shards were moved: %w", moved, set.count, renameErr)
+	}
+	return nil
+}
+
+func (b *erasureBackend) Delete(ctx context.Context, p string) error {
+	var deleteErr error
+	for _, disk := range b.disks {
+		if err := disk.Delete(ctx, p); err != nil && deleteErr == nil {
+			deleteErr = err
+		}
+	}
+	return deleteErr
+}
+
+func (b *erasureBackend) Stat(ctx context.Context, p string) (*domain.BlobInfo, error) {
+	set, err := b.inspect(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.BlobInfo{
+		Path:    p,
+		Size:    set.layout.size,
+		ModTime: set.modTime,
+	}, nil
+}
+
+// List returns the blobs that can be read back. Shards of a blob with too
+// few of them left to read are skipped; a heal reports them.
+func (b *erasureBackend) List(ctx context.Context, prefix string) ([]domain.BlobInfo, error) {
+	paths, err := b.shardPaths(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var blobs []domain.BlobInfo
+	for _, p := range paths {
+		info, err := b.Stat(ctx, p)
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, *info)
+	}
+
+	return blobs, nil
+}
+
+// shardPaths returns every path with a shard on any disk, sorted. A disk
+// that cannot be listed is passed over as long as enough others can be.
+func (b *erasureBackend) shardPaths(ctx context.Context, prefix string) ([]string, error) {
+	seen := map[string]bool{}
+	listed := 0
+	var listErr error
+	for _, disk := range b.disks {
+		infos, err := disk.List(ctx, prefix)
+		if err != nil {
+			if listErr == nil {
+				listErr = err
+			}
+			continue
+		}
+		listed++
+		for _, info := range infos {
+			seen[info.Path] = true
+		}
+	}
+	if listed < b.dataShards {
+		return nil, listErr
+	}
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// writeQuorum is how many shards a write has to land: one more than reading
+// it back takes, so losing one more disk does not lose the blob.
+func (b *erasureBackend) writeQuorum() int {
+	return b.dataShards + 1
+}
+
+func (b *erasureBackend) allShards() []int {
+	shards := make([]int, len(b.disks))
+	for i := range shards {
+		shards[i] = i
+	}
+	return shards
+}
+
+// encode splits r into stripes, computes their parity and writes each shard's
+// chunks to w, framed by the header and trailer. It returns the blob size.
+func (b *erasureBackend) encode(r io.Reader, header shardHeader, w *shardWriters) (int64, error) {
+	headers := make([][]byte, len(b.disks))
+	for i := range b.disks {
+		h := header
+		h.index = i
+		headers[i] = h.marshal()
+		w.write(i, headers[i])
+	}
+
+	stripe := make([]byte, header.blockSize*int64(b.dataShards))
+	parity := make([][]byte, b.parityShards)
+	for i := range parity {
+		parity[i] = make([]byte, header.blockSize)
+	}
+	shards := make([][]byte, len(b.disks))
+
+	var size int64
+	for {
+		n, err := readStripe(r, stripe)
+		if n > 0 {
+			// The last stripe is split evenly, its final chunk padded with zeros
+			chunk := (n + b.dataShards - 1) / b.dataShards
+			clear(stripe[n : chunk*b.dataShards])
+			for i := 0; i < b.dataShards; i++ {
+				shards[i] = stripe[i*chunk : (i+1)*chunk]
+			}
+			for i := range parity {
+				shards[b.dataShards+i] = parity[i][:chunk]
+			}
+			if err := b.enc.Encode(shards); err != nil {
+				return size, fmt.Errorf("failed to compute parity: %w", err)
+			}
+
+			for i, shard := range shards {
+				w.write(i, chunkChecksum(shard), shard)
+			}
+			size += int64(n)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return size, err
+		}
+	}
+
+	for i := range b.disks {
+		w.write(i, shardTrailer(headers[i], size))
+	}
+	return size, nil
+}
+
+// readStripe fills buf from r. Unlike io.ReadFull it reports a short final
+// stripe with io.EOF, and passes any other error from r through unchanged.
+func readStripe(r io.Reader, buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		read, err := r.Read(buf[n:])
+		n += read
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// shardSet is the intact shards of the latest complete write of a blob.
+type shardSet struct {
+	header  shardHeader
+	layout  shardLayout
+	present []bool
+	count   int
+	modTime time.Time
+}
+
+// inspect finds the shards of the blob at p. Shards are grouped by write,
+// and the largest group is the blob; it needs at least DataShards shards to
+// be readable, and the blob is reported missing otherwise.
+func (b *erasureBackend) inspect(ctx context.Context, p string) (*shardSet, error) {
+	type writeKey struct {
+		writeID [16]byte
+		size    int64
+	}
+
+	sets := map[writeKey]*shardSet{}
+	var best *shardSet
+	for i, disk := range b.disks {
+		meta, err := b.readShardMeta(ctx, disk, i, p)
+		if errors.Is(err, domain.ErrInvalidPath) {
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+
+		key := writeKey{writeID: meta.header.writeID, size: meta.size}
+		set, ok := sets[key]
+		if !ok {
+			set = &shardSet{
+				header:  meta.header,
+				layout:  shardLayout{size: meta.size, blockSize: meta.header.blockSize, dataShards: b.dataShards},
+				present: make([]bool, len(b.disks)),
+			}
+			sets[key] = set
+		}
+		set.present[i] = true
+		set.count++
+		if meta.modTime.After(set.modTime) {
+			set.modTime = meta.modTime
+		}
+
+		if best == nil || set.count > best.count {
+			best = set
+		}
+	}
+
+	if best == nil || best.count < b.dataShards {
+		return nil, domain.ErrBlobNotFound
+	}
+	return best, nil
+}
+
+type shardMeta struct {
+	header  shardHeader
+	size    int64
+	modTime time.Time
+}
+
+// readShardMeta reads and checks the header and trailer of the shard file at
+// p on disk index.
+func (b *erasureBackend) readShardMeta(ctx context.Context, disk domain.StorageBackend, index int, p string) (*shardMeta, error) {
+	info, err := disk.Stat(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size < shardHeaderSize+shardTrailerSize {
+		return nil, errDamagedShard
+	}
+
+	head, err := readShardRange(ctx, disk, p, 0, shardHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := readShardRange(ctx, disk, p, info.Size-shardTrailerSize, shardTrailerSize)
+	if err != nil {
+		return nil, err
+	}
+
+	header, ok := parseShardHeader(head)
+	if !ok || header.index != index || header.dataShards != b.dataShards || header.parityShards != b.parityShards {
+		return nil, errDamagedShard
+	}
+	size := int64(binary.BigEndian.Uint64(tail))
+	if size < 0 || !bytes.Equal(shardTrailer(head, size), tail) {
+		return nil, errDamagedShard
+	}
+
+	layout := shardLayout{size: size, blockSize: header.blockSize, dataShards: b.dataShards}
+	if layout.fileSize() != info.Size {
+		return nil, errDamagedShard
+	}
+
+	return &shardMeta{header: header, size: size, modTime: info.ModTime}, nil
+}
+
+func readShardRange(ctx context.Context, disk domain.StorageBackend, p string, offset, length int64) ([]byte, error) {
+	r, err := disk.GetRange(ctx, p, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errDamagedShard
+	}
+	return buf, nil
+}
+
+// erasureReader decodes a blob stripe by stripe. A shard that cannot be read
+// or fails its checksum is dropped for the rest of the blob and rebuilt from
+// the others on the fly.
+type erasureReader struct {
+	b      *erasureBackend
+	layout shardLayout
+	shards []io.ReadCloser
+	// lost marks the shards that are missing or were dropped
+	lost   []bool
+	bufs   [][]byte
+	view   [][]byte
+	stripe int64
+	out    []byte
+	// pending is the decoded part of the current stripe not yet returned
+	pending []byte
+	// skip is how much of the first stripe comes before the range read
+	skip int64
+}
+
+func (b *erasureBackend) newReader(ctx context.Context, p string, set *shardSet, stripe int64) *erasureReader {
+	r := &erasureReader{
+		b:      b,
+		layout: set.layout,
+		shards: make([]io.ReadCloser, len(b.disks)),
+		lost:   make([]bool, len(b.disks)),
+		bufs:   make([][]byte, len(b.disks)),
+		view:   make([][]byte, len(b.disks)),
+		stripe: stripe,
+	}
+
+	for i, disk := range b.disks {
+		r.bufs[i] = make([]byte, shardChecksumSize+set.layout.blockSize)
+		if !set.present[i] {
+			r.lost[i] = true
+			continue
+		}
+
+		shard, err := disk.GetRange(ctx, p, set.layout.stripeOffset(stripe), -1)
+		if err != nil {
+			r.lost[i] = true
+			continue
+		}
+		r.shards[i] = shard
+	}
+
+	return r
+}
+
+// nextStripe reads the next stripe and returns all its shards, with the
+// lost data shards rebuilt. Lost parity shards are only rebuilt as well when
+// parity is set.
+func (r *erasureReader) nextStripe(parity bool) ([][]byte, error) {
+	if r.stripe >= r.layout.stripes() {
+		return nil, io.EOF
+	}
+
+	chunk := r.layout.chunkSize(r.stripe)
+	intact := 0
+	for i := range r.view {
+		if shard := r.shards[i]; shard != nil {
+			buf := r.bufs[i][:shardChecksumSize+chunk]
+			_, err := io.ReadFull(shard, buf)
+			if err == nil && binary.BigEndian.Uint32(buf) == crc32.Checksum(buf[shardChecksumSize:], castagnoli) {
+				r.view[i] = buf[shardChecksumSize:]
+				intact++
+				continue
+			}
+			shard.Close()
+			r.shards[i] = nil
+			r.lost[i] = true
+		}
+		// An empty slice marks the shard for reconstruction into its buffer
+		r.view[i] = r.bufs[i][shardChecksumSize:shardChecksumSize]
+	}
+
+	if intact < r.b.dataShards {
+		return nil, errTooFewShards
+	}
+	if intact < len(r.view) {
+		var err error
+		if parity {
+			err = r.b.enc.Reconstruct(r.view)
+		} else {
+			err = r.b.enc.ReconstructData(r.view)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconstruct stripe: %w", err)
+		}
+	}
+
+	r.stripe++
+	return r.view, nil
+}
+
+func (r *erasureReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		stripe := r.stripe
+		shards, err := r.nextStripe(false)
+		if err != nil {
+			return 0, err
+		}
+
+		r.out = r.out[:0]
+		for _, shard := range shards[:r.b.dataShards] {
+			r.out = append(r.out, shard...)
+		}
+		r.out = r.out[:r.layout.stripeLen(stripe)]
+
+		skip := min(r.skip, int64(len(r.out)))
+		r.pending = r.out[skip:]
+		r.skip = 0
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *erasureReader) Close() error {
+	for i, shard := range r.shards {
+		if shard != nil {
+			shard.Close()
+			r.shards[i] = nil
+		}
+	}
+	return nil
+}
+
+// shardWriters streams shard files to their disks, each through the disk's
+// Put so it is written atomically.
+type shardWriters struct {
+	pipes []*io.PipeWriter
+	// broken marks the shards whose disk stopped reading
+	broken []bool
+	errs   []error
+	wg     sync.WaitGroup
+}
+
+func (b *erasureBackend) newShardWriters(ctx context.Context, p string, shards []int) *shardWriters {
+	w := &shardWriters{
+		pipes:  make([]*io.PipeWriter, len(b.disks)),
+		broken: make([]bool, len(b.disks)),
+		errs:   make([]error, len(b.disks)),
+	}
+
+	for _, i := range shards {
+		pr, pw := io.Pipe()
+		w.pipes[i] = pw
+		w.wg.Add(1)
+		go func(i int, disk domain.StorageBackend) {
+			defer w.wg.Done()
+			_, err := disk.Put(ctx, p, pr)
+			// Unblocks any write still waiting when the disk gave up early
+			pr.CloseWithError(err)
+			w.errs[i] = err
+		}(i, b.disks[i])
+	}
+
+	return w
+}
+
+// write sends chunks to shard i, unless it is not being written or its disk
+// has failed.
+func (w *shardWriters) write(i int, chunks ...[]byte) {
+	if w.pipes[i] == nil || w.broken[i] {
+		return
+	}
+	for _, chunk := range chunks {
+		if _, err := w.pipes[i].Write(chunk); err != nil {
+			w.broken[i] = true
+			return
+		}
+	}
+}
+
+// close ends every shard file, abandoning them all when abort is set, and
+// returns how the write to each disk ended.
+func (w *shardWriters) close(abort error) []error {
+	for _, pw := range w.pipes {
+		if pw == nil {
+			continue
+		}
+		if abort != nil {
+			pw.CloseWithError(abort)
+		} else {
+			pw.Close()
+		}
+	}
+	w.wg.Wait()
+
+	return w.errs
+}
+
+// shardHeader opens every shard file.
+type shardHeader struct {
+	dataShards   int
+	parityShards int
+	index        int
+	blockSize    int64
+	writeID      [16]byte
+}
+
+func (h shardHeader) marshal() []byte {
+	buf := make([]byte, shardHeaderSize)
+	copy(buf, shardMagic)
+	buf[4] = byte(h.dataShards)
+	buf[5] = byte(h.parityShards)
+	buf[6] = byte(h.index)
+	binary.BigEndian.PutUint32(buf[8:12], uint32(h.blockSize))
+	copy(buf[12:], h.writeID[:])
+	return buf
+}
+
+func parseShardHeader(buf []byte) (shardHeader, bool) {
+	if len(buf) != shardHeaderSize || string(buf[:4]) != shardMagic {
+		return shardHeader{}, false
+	}
+
+	h := shardHeader{
+		dataShards:   int(buf[4]),
+		parityShards: int(buf[5]),
+		index:        int(buf[6]),
+		blockSize:    int64(binary.BigEndian.Uint32(buf[8:12])),
+	}
+	copy(h.writeID[:], buf[12:])
+	return h, h.blockSize > 0
+}
+
+// shardTrailer closes a shard file with the blob size and a checksum over it
+// and the shard's header.
+func shardTrailer(header []byte, size int64) []byte {
+	buf := make([]byte, shardTrailerSize)
+	binary.BigEndian.PutUint64(buf, uint64(size))
+	checksum := crc32.Update(crc32.Checksum(header, castagnoli), castagnoli, buf[:8])
+	binary.BigEndian.PutUint32(buf[8:], checksum)
+	return buf
+}
+
+func chunkChecksum(chunk []byte) []byte {
+	buf := make([]byte, shardChecksumSize)
+	binary.BigEndian.PutUint32(buf, crc32.Checksum(chunk, castagnoli))
+	return buf
+}
+
+// shardLayout locates the stripes of a blob of size bytes within its shard
+// files.
+type shardLayout struct {
+	size       int64
+	blockSize  int64
+	dataShards int
+}
+
+func (l shardLayout) stripeSize() int64 {
+	return l.blockSize * int64(l.dataShards)
+}
+
+func (l shardLayout) stripes() int64 {
+	return (l.size + l.stripeSize() - 1) / l.stripeSize()
+}
+
+// stripeLen is how many bytes of the blob the stripe holds.
+func (l shardLayout) stripeLen(stripe int64) int64 {
+	return min(l.stripeSize(), l.size-stripe*l.stripeSize())
+}
+
+// chunkSize is how many bytes of the stripe each shard holds.
+func (l shardLayout) chunkSize(stripe int64) int64 {
+	return (l.stripeLen(stripe) + int64(l.dataShards) - 1) / int64(l.dataShards)
+}
+
+// stripeOffset is where the stripe starts within a shard file.
+func (l shardLayout) stripeOffset(stripe int64) int64 {
+	return shardHeaderSize + stripe*(shardChecksumSize+l.blockSize)
+}
+
+func (l shardLayout) fileSize() int64 {
+	stripes := l.stripes()
+	if stripes == 0 {
+		return shardHeaderSize + shardTrailerSize
+	}
+	return l.stripeOffset(stripes-1) + shardChecksumSize + l.chunkSize(stripes-1) + shardTrailerSize
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"s3-like/internal/domain"
+)
+
+// Heal reads every blob back in full and rewrites the shards that are
+// missing, damaged or left over from an earlier write, rebuilding them from
+// the intact ones. After a disk is replaced with an empty one, a heal
+// restores all of its shards.
+func (b *erasureBackend) Heal(ctx context.Context) (*HealReport, error) {
+	report := &HealReport{}
+	paths, err := b.shardPaths(ctx, "")
+	if err != nil {
+		return report, err
+	}
+
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		report.Checked++
+		rebuilt, err := b.healBlob(ctx, p)
+		if errors.Is(err, domain.ErrBlobNotFound) || errors.Is(err, errTooFewShards) {
+			report.Unrecoverable = append(report.Unrecoverable, p)
+			continue
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to heal %s: %w", p, err)
+		}
+		if rebuilt > 0 {
+			report.Healed++
+			report.Shards += rebuilt
+		}
+	}
+
+	return report, nil
+}
+
+// healBlob rewrites the shards of the blob at p that are not intact, and
+// returns how many it rewrote.
+func (b *erasureBackend) healBlob(ctx context.Context, p string) (int, error) {
+	set, err := b.inspect(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+
+	// Damage past the header only shows when the stripes are read
+	lost, err := b.findLostShards(ctx, p, set)
+	if err != nil || len(lost) == 0 {
+		return 0, err
+	}
+
+	r := b.newReader(ctx, p, set, 0)
+	defer r.Close()
+
+	w := b.newShardWriters(ctx, p, lost)
+	headers := make([][]byte, len(b.disks))
+	for _, i := range lost {
+		h := set.header
+		h.index = i
+		headers[i] = h.marshal()
+		w.write(i, headers[i])
+	}
+
+	for {
+		shards, err := r.nextStripe(true)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			w.close(err)
+			return 0, err
+		}
+		for _, i := range lost {
+			w.write(i, chunkChecksum(shards[i]), shards[i])
+		}
+	}
+
+	for _, i := range lost {
+		w.write(i, shardTrailer(headers[i], set.layout.size))
+	}
+	errs := w.close(nil)
+	for _, i := range lost {
+		if errs[i] != nil {
+			return 0, errs[i]
+		}
+	}
+	return len(lost), nil
+}
+
+// findLostShards reads the whole blob and returns the shards that are
+// missing or fail a checksum.
+func (b *erasureBackend) findLostShards(ctx context.Context, p string, set *shardSet) ([]int, error) {
+	r := b.newReader(ctx, p, set, 0)
+	defer r.Close()
+
+	for {
+		if _, err := r.nextStripe(false); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	var lost []int
+	for i, isLost := range r.lost {
+		if isLost {
+			lost = append(lost, i)
+		}
+	}
+	return lost, nil
+}
@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"s3-like/internal/domain"
+	"strings"
+	"testing"
+)
+
+const (
+	testDataShards   = 4
+	testParityShards = 2
+)
+
+// newTestErasureBackend opens a 4+2 erasure backend over temp directories
+// and returns it along with the directories, so tests can damage shards.
+func newTestErasureBackend(t *testing.T) (*erasureBackend, []string) {
+	t.Helper()
+
+	disks := make([]string, testDataShards+testParityShards)
+	for i := range disks {
+		disks[i] = t.TempDir()
+	}
+
+	backend, err := NewErasureBackend(disks, testDataShards, testParityShards)
+	if err != nil {
+		t.Fatalf("NewErasureBackend: %v", err)
+	}
+	return backend.(*erasureBackend), disks
+}
+
+// shardDamage damages the shards of the blob at p on disks.
+type shardDamage func(t *testing.T, backend *erasureBackend, disks []string, p string)
+
+func removeShards(shards ...int) shardDamage {
+	return func(t *testing.T, backend *erasureBackend, disks []string, p string) {
+		for _, i := range shards {
+			if err := os.Remove(filepath.Join(disks[i], p)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// flipByte flips a byte of shard i at offset, counted from the end of the
+// file when negative.
+func flipByte(i int, offset int64) shardDamage {
+	return func(t *testing.T, backend *erasureBackend, disks []string, p string) {
+		name := filepath.Join(disks[i], p)
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if offset < 0 {
+			offset += int64(len(data))
+		}
+		data[offset] ^= 0xff
+		if err := os.WriteFile(name, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// flipChunk corrupts the chunk of shard i in the given stripe.
+func flipChunk(i int, stripe int64) shardDamage {
+	layout := shardLayout{blockSize: shardBlockSize, dataShards: testDataShards}
+	return flipByte(i, layout.stripeOffset(stripe)+shardChecksumSize+10)
+}
+
+func truncateShard(i int) shardDamage {
+	return func(t *testing.T, backend *erasureBackend, disks []string, p string) {
+		name := filepath.Join(disks[i], p)
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Truncate(name, info.Size()/2); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// staleShard replaces shard i with the shard of an earlier write to p.
+func staleShard(i int) shardDamage {
+	return func(t *testing.T, backend *erasureBackend, disks []string, p string) {
+		ctx := context.Background()
+		current := readShards(t, disks, p)
+
+		if _, err := backend.Put(ctx, p, strings.NewReader("an earlier version")); err != nil {
+			t.Fatal(err)
+		}
+		stale := readShards(t, disks, p)[i]
+
+		for j, shard := range current {
+			if j == i {
+				shard = stale
+			}
+			if err := os.WriteFile(filepath.Join(disks[j], p), shard, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func damageAll(damages ...shardDamage) shardDamage {
+	return func(t *testing.T, backend *erasureBackend, disks []string, p string) {
+		for _, damage := range damages {
+			damage(t, backend, disks, p)
+		}
+	}
+}
+
+// readShards returns the content of every shard file of the blob at p, nil
+// where there is none.
+func readShards(t *testing.T, disks []string, p string) [][]byte {
+	t.Helper()
+
+	shards := make([][]byte, len(disks))
+	for i, disk := range disks {
+		data, err := os.ReadFile(filepath.Join(disk, p))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		shards[i] = data
+	}
+	return shards
+}
+
+func TestErasureReconstruct(t *testing.T) {
+	// Three stripes, the last one partial
+	data := make([]byte, 2*testDataShards*shardBlockSize+12345)
+	rand.New(rand.NewSource(1)).Read(data)
+	const p = "bucket/blob"
+
+	tests := []struct {
+		name   string
+		damage shardDamage
+		// lost is how many shards a heal has to rebuild
+		lost int
+	}{
+		{name: "intact", damage: damageAll()},
+		{name: "data shard missing", damage: removeShards(0), lost: 1},
+		{name: "parity shard missing", damage: removeShards(5), lost: 1},
+		{name: "data and parity shard missing", damage: removeShards(2, 4), lost: 2},
+		{name: "two data shards missing", damage: removeShards(0, 3), lost: 2},
+		{name: "chunk corrupted", damage: flipChunk(1, 1), lost: 1},
+		{name: "chunks corrupted in different stripes", damage: damageAll(flipChunk(0, 0), flipChunk(3, 2)), lost: 2},
+		{name: "header corrupted", damage: flipByte(2, 5), lost: 1},
+		{name: "trailer corrupted", damage: flipByte(4, -1), lost: 1},
+		{name: "shard truncated", damage: truncateShard(3), lost: 1},
+		{name: "shard from an earlier write", damage: damageAll(staleShard(0), removeShards(1)), lost: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend, disks := newTestErasureBackend(t)
+			if _, err := backend.Put(ctx, p, bytes.NewReader(data)); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			pristine := readShards(t, disks, p)
+
+			tt.damage(t, backend, disks, p)
+
+			if got := getBlob(t, backend, p); !bytes.Equal(got, data) {
+				t.Errorf("Get returned %d bytes that differ from the %d written", len(got), len(data))
+			}
+			offset := int64(testDataShards*shardBlockSize - 100)
+			if got := getRange(t, backend, p, offset, 200); !bytes.Equal(got, data[offset:offset+200]) {
+				t.Errorf("GetRange across a stripe boundary returned the wrong bytes")
+			}
+
+			report, err := backend.Heal(ctx)
+			if err != nil {
+				t.Fatalf("Heal: %v", err)
+			}
+			wantHealed := 0
+			if tt.lost > 0 {
+				wantHealed = 1
+			}
+			if report.Checked != 1 || report.Healed != wantHealed || report.Shards != tt.lost || len(report.Unrecoverable) != 0 {
+				t.Errorf("Heal report = %+v, want 1 checked, %d healed, %d shards", report, wantHealed, tt.lost)
+			}
+
+			for i, shard := range readShards(t, disks, p) {
+				if !bytes.Equal(shard, pristine[i]) {
+					t.Errorf("shard %d differs from the one written after the heal", i)
+				}
+			}
+
+			report, err = backend.Heal(ctx)
+			if err != nil || report.Healed != 0 || report.Shards != 0 {
+				t.Errorf("second Heal = %+v, %v, want nothing rebuilt", report, err)
+			}
+		})
+	}
+}
+
+func TestErasureUnrecoverable(t *testing.T) {
+	data := make([]byte, testDataShards*shardBlockSize+100)
+	rand.New(rand.NewSource(2)).Read(data)
+	const p = "bucket/blob"
+
+	tests := []struct {
+		name    string
+		damage  shardDamage
+		wantErr error
+	}{
+		{name: "three shards missing", damage: removeShards(0, 1, 5), wantErr: domain.ErrBlobNotFound},
+		{name: "three headers corrupted", damage: damageAll(flipByte(0, 0), flipByte(2, 0), flipByte(4, 0)), wantErr: domain.ErrBlobNotFound},
+		{name: "three chunks corrupted", damage: damageAll(flipChunk(0, 1), flipChunk(1, 1), flipChunk(2, 0)), wantErr: errTooFewShards},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend, disks := newTestErasureBackend(t)
+			for _, blob := range []string{p, "bucket/other"} {
+				if _, err := backend.Put(ctx, blob, bytes.NewReader(data)); err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+			}
+
+			tt.damage(t, backend, disks, p)
+
+			err := readAll(backend, p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get = %v, want %v", err, tt.wantErr)
+			}
+
+			report, err := backend.Heal(ctx)
+			if err != nil {
+				t.Fatalf("Heal: %v", err)
+			}
+			if report.Checked != 2 || report.Healed != 0 || strings.Join(report.Unrecoverable, ",") != p {
+				t.Errorf("Heal report = %+v, want 2 checked and %s unrecoverable", report, p)
+			}
+			if got := getBlob(t, backend, "bucket/other"); !bytes.Equal(got, data) {
+				t.Errorf("the intact blob no longer reads back")
+			}
+		})
+	}
+}
+
+// readAll reads the blob at p to the end and returns the first error.
+func readAll(backend domain.StorageBackend, p string) error {
+	r, err := backend.Get(context.Background(), p)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	_, err = io.Copy(io.Discard, r)
+	return err
+}
+
+func TestErasureRebuildsReplacedDisk(t *testing.T) {
+	ctx := context.Background()
+	backend, disks := newTestErasureBackend(t)
+
+	paths := []string{"a/one", "a/two", "b/three"}
+	for i, p := range paths {
+		if _, err := backend.Put(ctx, p, strings.NewReader(strings.Repeat(p, i*1000))); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	pristine := map[string][][]byte{}
+	for _, p := range paths {
+		pristine[p] = readShards(t, disks, p)
+	}
+
+	// Swap disk 2 for an empty one
+	if err := os.RemoveAll(disks[2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(disks[2], 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := backend.Heal(ctx)
+	if err != nil {
+		t.Fatalf("Heal: %v", err)
+	}
+	if report.Checked != len(paths) || report.Healed != len(paths) || report.Shards != len(paths) {
+		t.Errorf("Heal report = %+v, want %d blobs healed with one shard each", report, len(paths))
+	}
+	for _, p := range paths {
+		if shard := readShards(t, disks, p)[2]; !bytes.Equal(shard, pristine[p][2]) {
+			t.Errorf("shard 2 of %s was not rebuilt as written", p)
+		}
+	}
+}
+
+func TestNewErasureBackend(t *testing.T) {
+	dir := t.TempDir()
+	disk := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name         string
+		disks        []string
+		dataShards   int
+		parityShards int
+		wantErr      bool
+	}{
+		{name: "valid", disks: []string{disk("a"), disk("b"), disk("c")}, dataShards: 2, parityShards: 1},
+		{name: "no parity", disks: []string{disk("a"), disk("b")}, dataShards: 2, wantErr: true},
+		{name: "no data", disks: []string{disk("a")}, parityShards: 1, wantErr: true},
+		{name: "too few disks", disks: []string{disk("a"), disk("b")}, dataShards: 2, parityShards: 1, wantErr: true},
+		{name: "disk listed twice", disks: []string{disk("a"), disk("b"), disk("a") + "/"}, dataShards: 2, parityShards: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewErasureBackend(tt.disks, tt.dataShards, tt.parityShards)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewErasureBackend = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
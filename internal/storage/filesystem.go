@@ -0,0 +1,295 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"s3-like/internal/domain"
+	"strings"
+)
+
+// tempDir is where files are written before being renamed into place. It
+// sits under the base path so the rename stays within one filesystem, and is
+// hidden from List.
+const tempDir = ".tmp"
+
+type filesystemBackend struct {
+	basePath string
+}
+
+// NewFilesystemBackend opens the backend rooted at basePath. Temp files left
+// behind by a crash mid-write are removed, as nothing can be writing them
+// before the backend exists.
+func NewFilesystemBackend(basePath string) (domain.StorageBackend, error) {
+	basePath = filepath.Clean(basePath)
+	if err := os.RemoveAll(filepath.Join(basePath, tempDir)); err != nil {
+		return nil, fmt.Errorf("failed to clear temp directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(basePath, tempDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &filesystemBackend{basePath: basePath}, nil
+}
+
+func (b *filesystemBackend) Put(ctx context.Context, p string, r io.Reader) (int64, error) {
+	return b.writeFile(p, func(dst *os.File) (int64, error) {
+		return io.Copy(dst, &contextReader{ctx: ctx, r: r})
+	})
+}
+
+func (b *filesystemBackend) Get(ctx context.Context, p string) (io.ReadCloser, error) {
+	return b.open(p)
+}
+
+func (b *filesystemBackend) GetRange(ctx context.Context, p string, offset, length int64) (io.ReadCloser, error) {
+	file, err := b.open(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek file: %w", err)
+	}
+	if length < 0 {
+		return file, nil
+	}
+
+	return &limitedReadCloser{Reader: io.LimitReader(file, length), Closer: file}, nil
+}
+
+// Copy copies within the filesystem. os.File.ReadFrom uses copy_file_range
+// on Linux, which clones the extents on filesystems with reflink support
+// (btrfs, XFS) and copies in the kernel elsewhere.
+func (b *filesystemBackend) Copy(ctx context.Context, srcPath, dstPath string) (int64, error) {
+	src, err := b.open(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	return b.writeFile(dstPath, func(dst *os.File) (int64, error) {
+		return dst.ReadFrom(src)
+	})
+}
+
+// Rename moves the file within the filesystem, which replaces any file at
+// dstPath atomically.
+func (b *filesystemBackend) Rename(ctx context.Context, srcPath, dstPath string) error {
+	srcFullPath, err := b.resolve(srcPath)
+	if err != nil {
+		return err
+	}
+	dstFullPath, err := b.resolve(dstPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstFullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	if err := os.Rename(srcFullPath, dstFullPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return domain.ErrBlobNotFound
+		}
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+
+	return syncDir(filepath.Dir(dstFullPath))
+}
+
+func (b *filesystemBackend) Delete(ctx context.Context, p string) error {
+	fullPath, err := b.resolve(p)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+func (b *filesystemBackend) Stat(ctx context.Context, p string) (*domain.BlobInfo, error) {
+	fullPath, err := b.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, domain.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return &domain.BlobInfo{
+		Path:    p,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+func (b *filesystemBackend) List(ctx context.Context, prefix string) ([]domain.BlobInfo, error) {
+	// Only walk the deepest directory that can contain matches
+	root := b.basePath
+	if dir := path.Dir(prefix); dir != "." && dir != "/" {
+		resolved, err := b.resolve(dir)
+		if err != nil {
+			return nil, err
+		}
+		root = resolved
+	}
+
+	var blobs []domain.BlobInfo
+	err := filepath.WalkDir(root, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if fullPath == filepath.Join(b.basePath, tempDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(b.basePath, fullPath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.HasPrefix(rel, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, domain.BlobInfo{
+			Path:    rel,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return blobs, nil
+}
+
+// writeFile fills the file at p through write without ever exposing a
+// partial file there. The content goes to a temp file, which is fsynced and
+// then renamed into place; the directory is fsynced too so the rename
+// survives a crash.
+func (b *filesystemBackend) writeFile(p string, write func(dst *os.File) (int64, error)) (int64, error) {
+	fullPath, err := b.resolve(p)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Join(b.basePath, tempDir), "write-*")
+	if errors.Is(err, fs.ErrNotExist) {
+		// The disk was swapped for an empty one while the backend was open
+		if err := os.MkdirAll(filepath.Join(b.basePath, tempDir), 0755); err != nil {
+			return 0, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+		tmp, err = os.CreateTemp(filepath.Join(b.basePath, tempDir), "write-*")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	size, err := write(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		os.Remove(tmp.Name())
+		return 0, fmt.Errorf("failed to rename file: %w", err)
+	}
+	if err := syncDir(filepath.Dir(fullPath)); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// syncDir flushes a directory's entries, making renames into it durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	return nil
+}
+
+func (b *filesystemBackend) open(p string) (*os.File, error) {
+	fullPath, err := b.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, domain.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return file, nil
+}
+
+// resolve maps a backend path onto the local filesystem. Rows written before
+// the backend existed stored the full path including basePath, so those are
+// accepted as-is.
+func (b *filesystemBackend) resolve(p string) (string, error) {
+	fullPath := filepath.Clean(filepath.FromSlash(p))
+	if !strings.HasPrefix(fullPath, b.basePath+string(filepath.Separator)) {
+		fullPath = filepath.Join(b.basePath, fullPath)
+	}
+
+	if fullPath != b.basePath && !strings.HasPrefix(fullPath, b.basePath+string(filepath.Separator)) {
+		return "", domain.ErrInvalidPath
+	}
+
+	return fullPath, nil
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"s3-like/internal/domain"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryBlob struct {
+	data    []byte
+	modTime time.Time
+}
+
+// memoryBackend keeps blobs in process memory. It is meant for tests and
+// throwaway instances; nothing survives a restart.
+type memoryBackend struct {
+	mu    sync.RWMutex
+	blobs map[string]memoryBlob
+}
+
+func NewMemoryBackend() domain.StorageBackend {
+	return &memoryBackend{
+		blobs: make(map[string]memoryBlob),
+	}
+}
+
+func (b *memoryBackend) Put(ctx context.Context, path string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(&contextReader{ctx: ctx, r: r})
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.blobs[path] = memoryBlob{data: data, modTime: time.Now()}
+	return int64(len(data)), nil
+}
+
+func (b *memoryBackend) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	blob, ok := b.blobs[path]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	// Blobs are replaced, never mutated, so sharing the slice is safe
+	return io.NopCloser(bytes.NewReader(blob.data)), nil
+}
+
+func (b *memoryBackend) GetRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	blob, ok := b.blobs[path]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	size := int64(len(blob.data))
+	start := min(max(offset, 0), size)
+	end := size
+	if length >= 0 {
+		end = min(start+length, size)
+	}
+
+	return io.NopCloser(bytes.NewReader(blob.data[start:end])), nil
+}
+
+func (b *memoryBackend) Copy(ctx context.Context, srcPath, dstPath string) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	blob, ok := b.blobs[srcPath]
+	if !ok {
+		return 0, domain.ErrBlobNotFound
+	}
+
+	// Blobs are immutable, so the copy can share the data
+	b.blobs[dstPath] = memoryBlob{data: blob.data, modTime: time.Now()}
+	return int64(len(blob.data)), nil
+}
+
+func (b *memoryBackend) Rename(ctx context.Context, srcPath, dstPath string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	blob, ok := b.blobs[srcPath]
+	if !ok {
+		return domain.ErrBlobNotFound
+	}
+
+	b.blobs[dstPath] = blob
+	delete(b.blobs, srcPath)
+	return nil
+}
+
+func (b *memoryBackend) Delete(ctx context.Context, path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.blobs, path)
+	return nil
+}
+
+func (b *memoryBackend) Stat(ctx context.Context, path string) (*domain.BlobInfo, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	blob, ok := b.blobs[path]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+
+	return &domain.BlobInfo{
+		Path:    path,
+		Size:    int64(len(blob.data)),
+		ModTime: blob.modTime,
+	}, nil
+}
+
+func (b *memoryBackend) List(ctx context.Context, prefix string) ([]domain.BlobInfo, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var blobs []domain.BlobInfo
+	for path, blob := range b.blobs {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		blobs = append(blobs, domain.BlobInfo{
+			Path:    path,
+			Size:    int64(len(blob.data)),
+			ModTime: blob.modTime,
+		})
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].Path < blobs[j].Path
+	})
+
+	return blobs, nil
+}
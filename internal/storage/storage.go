@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"s3-like/internal/config"
+	"s3-like/internal/domain"
+)
+
+// New builds the storage backend selected by cfg.Backend.
+func New(cfg config.StorageConfig) (domain.StorageBackend, error) {
+	switch cfg.Backend {
+	case "", "filesystem":
+		return NewFilesystemBackend(cfg.BasePath)
+	case "memory":
+		return NewMemoryBackend(), nil
+	case "erasure":
+		return NewErasureBackend(cfg.Disks, cfg.DataShards, cfg.ParityShards)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Backend)
+	}
+}
+
+// Healer is implemented by backends that store blobs redundantly, to rewrite
+// the redundant copies that are missing or damaged.
+type Healer interface {
+	Heal(ctx context.Context) (*HealReport, error)
+}
+
+// HealReport counts what a heal did. Unrecoverable lists the blobs with too
+// little left of them to rebuild.
+type HealReport struct {
+	Checked       int
+	Healed        int
+	Shards        int
+	Unrecoverable []string
+}
+
+// contextReader stops reading once ctx is cancelled, so long uploads are
+// abandoned when the client goes away.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
+// limitedReadCloser closes the underlying blob of a ranged read.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
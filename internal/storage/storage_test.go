@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"s3-like/internal/domain"
+	"strings"
+	"testing"
+)
+
+// testBackends returns a fresh instance of every backend, so each test runs
+// against all of them.
+func testBackends(t *testing.T) map[string]domain.StorageBackend {
+	t.Helper()
+
+	filesystem, err := NewFilesystemBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFilesystemBackend: %v", err)
+	}
+
+	erasure, _ := newTestErasureBackend(t)
+
+	return map[string]domain.StorageBackend{
+		"memory":     NewMemoryBackend(),
+		"filesystem": filesystem,
+		"erasure":    erasure,
+	}
+}
+
+// getRange reads a blob, or the range of one, failing the test on error.
+func getRange(t *testing.T, backend domain.StorageBackend, p string, offset, length int64) []byte {
+	t.Helper()
+
+	r, err := backend.GetRange(context.Background(), p, offset, length)
+	if err != nil {
+		t.Fatalf("GetRange(%s, %d, %d): %v", p, offset, length, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", p, err)
+	}
+	return data
+}
+
+// getBlob reads a blob, failing the test on error.
+func getBlob(t *testing.T, backend domain.StorageBackend, p string) []byte {
+	t.Helper()
+
+	r, err := backend.Get(context.Background(), p)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", p, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", p, err)
+	}
+	return data
+}
+
+func TestBackendPutGet(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data []byte
+	}{
+		{"empty", "empty", nil},
+		{"small", "a/b/small.txt", []byte("hello, world")},
+		{"large", "large.bin", bytes.Repeat([]byte("0123456789abcdef"), 64<<10)},
+	}
+
+	for name, backend := range testBackends(t) {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+
+				n, err := backend.Put(ctx, tt.path, bytes.NewReader(tt.data))
+				if err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+				if n != int64(len(tt.data)) {
+					t.Errorf("Put wrote %d bytes, want %d", n, len(tt.data))
+				}
+
+				got := getBlob(t, backend, tt.path)
+				if !bytes.Equal(got, tt.data) {
+					t.Errorf("Get returned %d bytes, want %d", len(got), len(tt.data))
+				}
+
+				info, err := backend.Stat(ctx, tt.path)
+				if err != nil {
+					t.Fatalf("Stat: %v", err)
+				}
+				if info.Size != int64(len(tt.data)) || info.Path != tt.path {
+					t.Errorf("Stat = %+v, want path %s and size %d", info, tt.path, len(tt.data))
+				}
+			})
+		}
+	}
+}
+
+func TestBackendPutReplaces(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			for _, data := range []string{"first version", "second"} {
+				if _, err := backend.Put(ctx, "key", strings.NewReader(data)); err != nil {
+					t.Fatalf("Put: %v", err)
+				}
+			}
+
+			if got := getBlob(t, backend, "key"); string(got) != "second" {
+				t.Errorf("Get = %q, want %q", got, "second")
+			}
+		})
+	}
+}
+
+func TestBackendGetRange(t *testing.T) {
+	const data = "0123456789"
+
+	tests := []struct {
+		name   string
+		offset int64
+		length int64
+		want   string
+	}{
+		{"whole", 0, -1, data},
+		{"from offset to end", 4, -1, "456789"},
+		{"middle", 2, 3, "234"},
+		{"length past end", 8, 10, "89"},
+		{"zero length", 5, 0, ""},
+		{"offset at end", 10, -1, ""},
+	}
+
+	for name, backend := range testBackends(t) {
+		ctx := context.Background()
+		if _, err := backend.Put(ctx, "range", strings.NewReader(data)); err != nil {
+			t.Fatalf("%s: Put: %v", name, err)
+		}
+
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				got := getRange(t, backend, "range", tt.offset, tt.length)
+				if string(got) != tt.want {
+					t.Errorf("GetRange(%d, %d) = %q, want %q", tt.offset, tt.length, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestBackendCopyRename(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if _, err := backend.Put(ctx, "src", strings.NewReader("content")); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+
+			n, err := backend.Copy(ctx, "src", "dir/copy")
+			if err != nil {
+				t.Fatalf("Copy: %v", err)
+			}
+			if n != int64(len("content")) {
+				t.Errorf("Copy copied %d bytes, want %d", n, len("content"))
+			}
+			if got := getBlob(t, backend, "dir/copy"); string(got) != "content" {
+				t.Errorf("copy = %q, want %q", got, "content")
+			}
+
+			if _, err := backend.Put(ctx, "dst", strings.NewReader("replaced")); err != nil {
+				t.Fatalf("Put: %v", err)
+			}
+			if err := backend.Rename(ctx, "src", "dst"); err != nil {
+				t.Fatalf("Rename: %v", err)
+			}
+			if got := getBlob(t, backend, "dst"); string(got) != "content" {
+				t.Errorf("renamed = %q, want %q", got, "content")
+			}
+			if _, err := backend.Stat(ctx, "src"); !errors.Is(err, domain.ErrBlobNotFound) {
+				t.Errorf("Stat of the renamed source = %v, want ErrBlobNotFound", err)
+			}
+		})
+	}
+}
+
+func TestBackendNotFound(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if _, err := backend.Get(ctx, "missing"); !errors.Is(err, domain.ErrBlobNotFound) {
+				t.Errorf("Get = %v, want ErrBlobNotFound", err)
+			}
+			if _, err := backend.GetRange(ctx, "missing", 0, 1); !errors.Is(err, domain.ErrBlobNotFound) {
+				t.Errorf("GetRange = %v, want ErrBlobNotFound", err)
+			}
+			if _, err := backend.Stat(ctx, "missing"); !errors.Is(err, domain.ErrBlobNotFound) {
+				t.Errorf("Stat = %v, want ErrBlobNotFound", err)
+			}
+			if _, err := backend.Copy(ctx, "missing", "dst"); !errors.Is(err, domain.ErrBlobNotFound) {
+				t.Errorf("Copy = %v, want ErrBlobNotFound", err)
+			}
+			if err := backend.Rename(ctx, "missing", "dst"); !errors.Is(err, domain.ErrBlobNotFound) {
+				t.Errorf("Rename = %v, want ErrBlobNotFound", err)
+			}
+			if err := backend.Delete(ctx, "missing"); err != nil {
+				t.Errorf("Delete = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestBackendDeleteList(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, p := range []string{"photos/b.jpg", "photos/a.jpg", "photos2/c.jpg", "docs/d.txt"} {
+				if _, err := backend.Put(ctx, p, strings.NewReader(p)); err != nil {
+					t.Fatalf("Put %s: %v", p, err)
+				}
+			}
+			if err := backend.Delete(ctx, "docs/d.txt"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+
+			tests := []struct {
+				prefix string
+				want   []string
+			}{
+				{"", []string{"photos/a.jpg", "photos/b.jpg", "photos2/c.jpg"}},
+				{"photos/", []string{"photos/a.jpg", "photos/b.jpg"}},
+				{"photos", []string{"photos/a.jpg", "photos/b.jpg", "photos2/c.jpg"}},
+				{"docs/", nil},
+				{"nothing/", nil},
+			}
+			for _, tt := range tests {
+				blobs, err := backend.List(ctx, tt.prefix)
+				if err != nil {
+					t.Fatalf("List(%q): %v", tt.prefix, err)
+				}
+				var got []string
+				for _, blob := range blobs {
+					got = append(got, blob.Path)
+				}
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("List(%q) = %v, want %v", tt.prefix, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilesystemInvalidPath(t *testing.T) {
+	backend, err := NewFilesystemBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFilesystemBackend: %v", err)
+	}
+
+	for _, p := range []string{"../escape", "a/../../escape"} {
+		t.Run(p, func(t *testing.T) {
+			if _, err := backend.Put(context.Background(), p, strings.NewReader("x")); !errors.Is(err, domain.ErrInvalidPath) {
+				t.Errorf("Put(%q) = %v, want ErrInvalidPath", p, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"s3-like/internal/domain"
@@ -15,15 +16,17 @@ import (
 type authUseCase struct {
 	userRepo         domain.UserRepository
 	refreshTokenRepo domain.RefreshTokenRepository
+	accessKeyRepo    domain.AccessKeyRepository
 	jwtSecret        string
 	accessTokenTTL   time.Duration
 	refreshTokenTTL  time.Duration
 }
 
-func NewAuthUseCase(userRepo domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, jwtSecret string) domain.AuthUseCase {
+func NewAuthUseCase(userRepo domain.UserRepository, refreshTokenRepo domain.RefreshTokenRepository, accessKeyRepo domain.AccessKeyRepository, jwtSecret string) domain.AuthUseCase {
 	return &authUseCase{
 		userRepo:         userRepo,
 		refreshTokenRepo: refreshTokenRepo,
+		accessKeyRepo:    accessKeyRepo,
 		jwtSecret:        jwtSecret,
 		accessTokenTTL:   time.Hour * 1,      // 1 hour
 		refreshTokenTTL:  time.Hour * 24 * 7, // 7 days
@@ -126,6 +129,48 @@ func (uc *authUseCase) RevokeAllUserTokens(userID uuid.UUID) error {
 	return uc.refreshTokenRepo.RevokeAllUserTokens(userID)
 }
 
+func (uc *authUseCase) CreateAccessKey(userID uuid.UUID) (*domain.CreateAccessKeyResponse, error) {
+	accessKeyID, err := generateAccessKeyID()
+	if err != nil {
+		return nil, err
+	}
+
+	// 30 random bytes encode to the usual 40 character secret
+	secretBytes := make([]byte, 30)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, err
+	}
+
+	key := &domain.AccessKey{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: base64.StdEncoding.EncodeToString(secretBytes),
+		UserID:          userID,
+	}
+
+	if err := uc.accessKeyRepo.Create(key); err != nil {
+		return nil, err
+	}
+
+	// The secret is only ever returned here
+	return &domain.CreateAccessKeyResponse{
+		AccessKeyID:     key.AccessKeyID,
+		SecretAccessKey: key.SecretAccessKey,
+		CreatedAt:       key.CreatedAt,
+	}, nil
+}
+
+func (uc *authUseCase) ListAccessKeys(userID uuid.UUID) ([]domain.AccessKey, error) {
+	return uc.accessKeyRepo.GetByUserID(userID)
+}
+
+func (uc *authUseCase) RevokeAccessKey(userID uuid.UUID, accessKeyID string) error {
+	return uc.accessKeyRepo.Revoke(userID, accessKeyID)
+}
+
+func (uc *authUseCase) GetAccessKey(accessKeyID string) (*domain.AccessKey, error) {
+	return uc.accessKeyRepo.GetByAccessKeyID(accessKeyID)
+}
+
 func (uc *authUseCase) generateTokenPair(user *domain.User) (*domain.AuthResponse, error) {
 	// Generate access token
 	accessToken, err := uc.generateAccessToken(user.ID)
@@ -183,6 +228,22 @@ func (uc *authUseCase) generateRefreshToken(userID uuid.UUID) (string, error) {
 	return tokenString, nil
 }
 
+// generateAccessKeyID returns a 20 character upper-case alphanumeric ID, the
+// shape S3 clients expect.
+func generateAccessKeyID() (string, error) {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	bytes := make([]byte, 20)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	for i, b := range bytes {
+		bytes[i] = alphabet[int(b)%len(alphabet)]
+	}
+
+	return string(bytes), nil
+}
+
 // Cleanup expired tokens (should be called periodically)
 func (uc *authUseCase) CleanupExpiredTokens() error {
 	return uc.refreshTokenRepo.CleanupExpiredTokens()
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"s3-like/internal/domain"
+)
+
+// blobReader makes a stored blob seekable so handlers can serve byte ranges.
+// Seeking only moves the offset; the blob is reopened at the new offset on
+// the next Read.
+type blobReader struct {
+	open    func(offset int64) (io.ReadCloser, error)
+	size    int64
+	offset  int64
+	current io.ReadCloser
+}
+
+func newBlobReader(storage domain.StorageBackend, path string, size int64) (io.ReadSeekCloser, error) {
+	return openBlobReader(size, func(offset int64) (io.ReadCloser, error) {
+		if offset == 0 {
+			return storage.Get(context.Background(), path)
+		}
+		return storage.GetRange(context.Background(), path, offset, size-offset)
+	})
+}
+
+// openBlobReader returns a blobReader that reads the content of size bytes
+// from open. The content is opened up front so a missing blob is reported
+// immediately rather than on the first Read.
+func openBlobReader(size int64, open func(offset int64) (io.ReadCloser, error)) (io.ReadSeekCloser, error) {
+	file, err := open(0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blobReader{
+		open:    open,
+		size:    size,
+		current: file,
+	}, nil
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.current == nil {
+		file, err := r.open(r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.current = file
+	}
+
+	n, err := r.current.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *blobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("blobReader.Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("blobReader.Seek: negative position")
+	}
+
+	if offset != r.offset && r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *blobReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash/fnv"
+	"io"
+	"path"
+	"s3-like/internal/domain"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Object content lives in a content-addressed store: each blob sits at a
+// path derived from the SHA-256 of its stored bytes, and versions stored
+// identically share it. New content is written to a staging path first, as
+// its hash is only known once all of it has been read.
+const (
+	blobStorePrefix   = ".blobs"
+	blobStagingPrefix = ".staging"
+)
+
+// blobLocks serialize committing a blob against garbage collecting it, so a
+// blob can never be deleted between being found in the store and being
+// referenced by the new version. They are per process, which holds as long as
+// the server is the only one writing objects.
+var blobLocks [64]sync.Mutex
+
+func lockBlob(hash string) func() {
+	h := fnv.New32a()
+	h.Write([]byte(hash))
+	mu := &blobLocks[h.Sum32()%uint32(len(blobLocks))]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// blobPath returns the store path of the blob with hash, fanned out over two
+// directory levels.
+func blobPath(hash string) string {
+	return path.Join(blobStorePrefix, hash[:2], hash[2:4], hash)
+}
+
+// stagedBlob is content on its way into the blob store, or a blob already
+// in it that another version is about to share.
+type stagedBlob struct {
+	hash string
+	size int64
+	// stagingPath holds new content until it is committed
+	stagingPath string
+}
+
+// stageBlob writes content to a staging path, hashing it along the way.
+func stageBlob(storage domain.StorageBackend, content io.Reader) (*stagedBlob, error) {
+	stagingPath := path.Join(blobStagingPrefix, uuid.New().String())
+
+	hasher := sha256.New()
+	size, err := storage.Put(context.Background(), stagingPath, io.TeeReader(content, hasher))
+	if err != nil {
+		storage.Delete(context.Background(), stagingPath)
+		return nil, err
+	}
+
+	return &stagedBlob{
+		hash:        hex.EncodeToString(hasher.Sum(nil)),
+		size:        size,
+		stagingPath: stagingPath,
+	}, nil
+}
+
+// shareBlob returns the blob of object for a new version to reference. A
+// version written before blobs were shared has its content staged into the
+// store instead.
+func shareBlob(storage domain.StorageBackend, object *domain.Object) (*stagedBlob, error) {
+	if object.BlobHash != "" {
+		return &stagedBlob{hash: object.BlobHash, size: object.StoredSize}, nil
+	}
+
+	file, err := storage.Get(context.Background(), object.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return stageBlob(storage, file)
+}
+
+func (b *stagedBlob) path() string {
+	return blobPath(b.hash)
+}
+
+// commit moves staged content into the store, unless an identical blob is
+// there already, and then runs record to reference it from a version. The
+// blob is locked throughout, so garbage collection cannot remove it before
+// record has taken its reference.
+func (b *stagedBlob) commit(storage domain.StorageBackend, record func() error) error {
+	unlock := lockBlob(b.hash)
+	defer unlock()
+
+	placed := false
+	_, err := storage.Stat(context.Background(), b.path())
+	switch {
+	case err == nil:
+		b.discard(storage)
+	case errors.Is(err, domain.ErrBlobNotFound) && b.stagingPath != "":
+		if err := storage.Rename(context.Background(), b.stagingPath, b.path()); err != nil {
+			b.discard(storage)
+			return err
+		}
+		placed = true
+	default:
+		b.discard(storage)
+		return err
+	}
+
+	if err := record(); err != nil {
+		if placed {
+			storage.Delete(context.Background(), b.path())
+		}
+		return err
+	}
+	return nil
+}
+
+// discard removes the staged content, if any.
+func (b *stagedBlob) discard(storage domain.StorageBackend) {
+	if b.stagingPath != "" {
+		storage.Delete(context.Background(), b.stagingPath)
+		b.stagingPath = ""
+	}
+}
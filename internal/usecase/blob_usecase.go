@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"s3-like/internal/domain"
+)
+
+type blobUseCase struct {
+	blobRepo  domain.BlobRepository
+	storage   domain.StorageBackend
+	batchSize int
+}
+
+func NewBlobUseCase(blobRepo domain.BlobRepository, storage domain.StorageBackend, batchSize int) domain.BlobUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &blobUseCase{
+		blobRepo:  blobRepo,
+		storage:   storage,
+		batchSize: batchSize,
+	}
+}
+
+// CollectGarbage removes every blob whose last reference is gone. Each blob
+// is locked while it is checked and removed, so an upload of identical
+// content either takes its reference first or writes the blob anew.
+func (uc *blobUseCase) CollectGarbage() (int, error) {
+	collected := 0
+
+	// Collected blobs, and those referenced again, no longer match, so each
+	// batch starts from the top; a blob that fails stops the run rather than
+	// being listed forever
+	for {
+		blobs, err := uc.blobRepo.ListUnreferenced(uc.batchSize)
+		if err != nil {
+			return collected, err
+		}
+		for _, blob := range blobs {
+			removed, err := uc.collect(blob.Hash)
+			if err != nil {
+				return collected, fmt.Errorf("failed to collect blob %s: %w", blob.Hash, err)
+			}
+			if removed {
+				collected++
+			}
+		}
+		if len(blobs) < uc.batchSize {
+			break
+		}
+	}
+
+	if collected > 0 {
+		log.Printf("Collected %d unreferenced blobs", collected)
+	}
+	return collected, nil
+}
+
+// collect removes the blob unless it has gained a reference since it was
+// listed. The file goes first: a record left behind by a failure is simply
+// collected again.
+func (uc *blobUseCase) collect(hash string) (bool, error) {
+	unlock := lockBlob(hash)
+	defer unlock()
+
+	blob, err := uc.blobRepo.GetByHash(hash)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if blob.RefCount > 0 {
+		return false, nil
+	}
+
+	if err := uc.storage.Delete(context.Background(), blobPath(hash)); err != nil {
+		return false, err
+	}
+	return true, uc.blobRepo.DeleteUnreferenced(hash)
+}
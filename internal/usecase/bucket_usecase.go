@@ -1,7 +1,7 @@
 package usecase
 
 import (
-	"errors"
+	"s3-like/internal/compression"
 	"s3-like/internal/domain"
 
 	"github.com/google/uuid"
@@ -9,25 +9,48 @@ import (
 
 type bucketUseCase struct {
 	bucketRepo domain.BucketRepository
+	keys       domain.KeyManager
 }
 
-func NewBucketUseCase(bucketRepo domain.BucketRepository) domain.BucketUseCase {
+func NewBucketUseCase(bucketRepo domain.BucketRepository, keys domain.KeyManager) domain.BucketUseCase {
 	return &bucketUseCase{
 		bucketRepo: bucketRepo,
+		keys:       keys,
 	}
 }
 
 func (uc *bucketUseCase) CreateBucket(userID uuid.UUID, req *domain.CreateBucketRequest) (*domain.Bucket, error) {
+	if req.Compression != "" && !compression.Valid(req.Compression) {
+		return nil, domain.ErrInvalidCompression
+	}
+
 	// Check if bucket already exists
 	if _, err := uc.bucketRepo.GetByName(req.Name); err == nil {
-		return nil, errors.New("bucket already exists")
+		return nil, domain.ErrBucketAlreadyExists
+	}
+
+	versioning := domain.VersioningUnversioned
+	if req.Versioning || req.ObjectLockEnabled {
+		versioning = domain.VersioningEnabled
 	}
 
 	bucket := &domain.Bucket{
-		Name:       req.Name,
-		UserID:     userID,
-		Public:     req.Public,
-		Versioning: req.Versioning,
+		Name:              req.Name,
+		UserID:            userID,
+		Public:            req.Public,
+		Versioning:        versioning,
+		ObjectLockEnabled: req.ObjectLockEnabled,
+		Compression:       req.Compression,
+	}
+
+	if req.DefaultRetention != nil {
+		if !req.ObjectLockEnabled {
+			return nil, domain.ErrObjectLockNotEnabled
+		}
+		if err := validateDefaultRetention(req.DefaultRetention); err != nil {
+			return nil, err
+		}
+		bucket.DefaultRetention = *req.DefaultRetention
 	}
 
 	if err := uc.bucketRepo.Create(bucket); err != nil {
@@ -45,7 +68,20 @@ func (uc *bucketUseCase) GetBucket(userID uuid.UUID, name string) (*domain.Bucke
 
 	// Check if user owns the bucket or if it's public
 	if bucket.UserID != userID && !bucket.Public {
-		return nil, errors.New("access denied")
+		return nil, domain.ErrAccessDenied
+	}
+
+	return bucket, nil
+}
+
+func (uc *bucketUseCase) GetOwnedBucket(userID uuid.UUID, name string) (*domain.Bucket, error) {
+	bucket, err := uc.bucketRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket.UserID != userID {
+		return nil, domain.ErrAccessDenied
 	}
 
 	return bucket, nil
@@ -62,8 +98,129 @@ func (uc *bucketUseCase) DeleteBucket(userID uuid.UUID, name string) error {
 	}
 
 	if bucket.UserID != userID {
-		return errors.New("access denied")
+		return domain.ErrAccessDenied
 	}
 
 	return uc.bucketRepo.Delete(bucket.ID)
 }
+
+// PutBucketVersioning changes the bucket's versioning state. There is no way
+// back to Unversioned: versions written while versioning was enabled stay
+// around, so a bucket that has ever been versioned can only be suspended.
+func (uc *bucketUseCase) PutBucketVersioning(userID uuid.UUID, name string, status domain.VersioningStatus) (*domain.Bucket, error) {
+	if status != domain.VersioningEnabled && status != domain.VersioningSuspended {
+		return nil, domain.ErrInvalidVersioning
+	}
+
+	bucket, err := uc.bucketRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket.UserID != userID {
+		return nil, domain.ErrAccessDenied
+	}
+
+	if bucket.Versioning == status {
+		return bucket, nil
+	}
+
+	if bucket.ObjectLockEnabled {
+		return nil, domain.ErrObjectLockVersioning
+	}
+
+	bucket.Versioning = status
+	if err := uc.bucketRepo.Update(bucket); err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
+
+// PutObjectLockConfiguration enables Object Lock and replaces the default
+// retention. Object Lock needs versioning enabled and, once on, cannot be
+// turned off again.
+func (uc *bucketUseCase) PutObjectLockConfiguration(userID uuid.UUID, name string, req *domain.ObjectLockConfigurationRequest) (*domain.Bucket, error) {
+	bucket, err := uc.bucketRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket.UserID != userID {
+		return nil, domain.ErrAccessDenied
+	}
+
+	if !req.ObjectLockEnabled {
+		return nil, domain.ErrObjectLockNotEnabled
+	}
+	if bucket.Versioning != domain.VersioningEnabled {
+		return nil, domain.ErrObjectLockVersioning
+	}
+
+	retention := domain.DefaultRetention{}
+	if req.DefaultRetention != nil {
+		retention = *req.DefaultRetention
+	}
+	if err := validateDefaultRetention(&retention); err != nil {
+		return nil, err
+	}
+
+	bucket.ObjectLockEnabled = true
+	bucket.DefaultRetention = retention
+	if err := uc.bucketRepo.Update(bucket); err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
+
+// PutBucketEncryption sets or removes the default encryption. A default
+// needs a master key, or every upload without SSE-C would fail.
+func (uc *bucketUseCase) PutBucketEncryption(userID uuid.UUID, name, algorithm string) (*domain.Bucket, error) {
+	if algorithm != "" && algorithm != domain.SSEAlgorithmAES256 {
+		return nil, domain.ErrInvalidEncryption
+	}
+	if algorithm != "" && uc.keys.ActiveKeyID() == "" {
+		return nil, domain.ErrEncryptionNotConfigured
+	}
+
+	bucket, err := uc.bucketRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket.UserID != userID {
+		return nil, domain.ErrAccessDenied
+	}
+
+	bucket.DefaultEncryption = algorithm
+	if err := uc.bucketRepo.Update(bucket); err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
+
+// PutBucketCompression sets or removes the compression of new blobs. Blobs
+// already written keep the compression they were written with.
+func (uc *bucketUseCase) PutBucketCompression(userID uuid.UUID, name, algorithm string) (*domain.Bucket, error) {
+	if algorithm != "" && !compression.Valid(algorithm) {
+		return nil, domain.ErrInvalidCompression
+	}
+
+	bucket, err := uc.bucketRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket.UserID != userID {
+		return nil, domain.ErrAccessDenied
+	}
+
+	bucket.Compression = algorithm
+	if err := uc.bucketRepo.Update(bucket); err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
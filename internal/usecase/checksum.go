@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"s3-like/internal/domain"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumAlgorithm ties a supported x-amz-checksum algorithm to its field
+// on Checksums.
+type checksumAlgorithm struct {
+	newHash func() hash.Hash
+	get     func(checksums domain.Checksums) string
+	set     func(checksums *domain.Checksums, value string)
+}
+
+var checksumAlgorithms = []checksumAlgorithm{
+	{func() hash.Hash { return crc32.NewIEEE() }, func(c domain.Checksums) string { return c.CRC32 }, func(c *domain.Checksums, v string) { c.CRC32 = v }},
+	{func() hash.Hash { return crc32.New(crc32cTable) }, func(c domain.Checksums) string { return c.CRC32C }, func(c *domain.Checksums, v string) { c.CRC32C = v }},
+	{sha1.New, func(c domain.Checksums) string { return c.SHA1 }, func(c *domain.Checksums, v string) { c.SHA1 = v }},
+	{sha256.New, func(c domain.Checksums) string { return c.SHA256 }, func(c *domain.Checksums, v string) { c.SHA256 = v }},
+}
+
+// bodyDigests computes the MD5 of an upload, and the checksums the client
+// sent, while the body is streamed to storage. It also counts the bytes, as
+// the size stored may differ once the body is encrypted.
+type bodyDigests struct {
+	read        byteCounter
+	md5         hash.Hash
+	expectedMD5 []byte
+	checksums   []checksum
+}
+
+type checksum struct {
+	hash     hash.Hash
+	expected []byte
+	// set records the verified value on the version's Checksums
+	set func(checksums *domain.Checksums, value string)
+}
+
+// newBodyDigests decodes the Content-MD5 and x-amz-checksum values given
+// with an upload. Empty values are not checked.
+func newBodyDigests(contentMD5 string, expected domain.Checksums) (*bodyDigests, error) {
+	d := &bodyDigests{md5: md5.New()}
+
+	if contentMD5 != "" {
+		digest, err := base64.StdEncoding.DecodeString(contentMD5)
+		if err != nil || len(digest) != md5.Size {
+			return nil, domain.ErrInvalidDigest
+		}
+		d.expectedMD5 = digest
+	}
+
+	for _, algorithm := range checksumAlgorithms {
+		value := algorithm.get(expected)
+		if value == "" {
+			continue
+		}
+		h := algorithm.newHash()
+		digest, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(digest) != h.Size() {
+			return nil, domain.ErrInvalidChecksum
+		}
+		d.checksums = append(d.checksums, checksum{hash: h, expected: digest, set: algorithm.set})
+	}
+
+	return d, nil
+}
+
+// reader returns body with every byte read from it fed to the digests.
+func (d *bodyDigests) reader(body io.Reader) io.Reader {
+	writers := []io.Writer{&d.read, d.md5}
+	for _, c := range d.checksums {
+		writers = append(writers, c.hash)
+	}
+	return io.TeeReader(body, io.MultiWriter(writers...))
+}
+
+// size returns the number of bytes read from the body so far.
+func (d *bodyDigests) size() int64 {
+	return int64(d.read)
+}
+
+// etag returns the hex MD5 of the body read so far.
+func (d *bodyDigests) etag() string {
+	return fmt.Sprintf("%x", d.md5.Sum(nil))
+}
+
+// verify checks the body against every digest given and returns the
+// checksums to store with the version.
+func (d *bodyDigests) verify() (domain.Checksums, error) {
+	var checksums domain.Checksums
+
+	if d.expectedMD5 != nil && !bytes.Equal(d.md5.Sum(nil), d.expectedMD5) {
+		return checksums, domain.ErrBadDigest
+	}
+	for _, c := range d.checksums {
+		digest := c.hash.Sum(nil)
+		if !bytes.Equal(digest, c.expected) {
+			return domain.Checksums{}, domain.ErrBadDigest
+		}
+		c.set(&checksums, base64.StdEncoding.EncodeToString(digest))
+	}
+
+	return checksums, nil
+}
+
+// compositeChecksums returns the checksums of a completed multipart upload.
+// Like S3, each is the checksum of the concatenated binary part checksums
+// followed by "-<number of parts>", and only algorithms every part was
+// uploaded with are set.
+func compositeChecksums(parts []domain.MultipartPart) domain.Checksums {
+	var checksums domain.Checksums
+
+	for _, algorithm := range checksumAlgorithms {
+		h := algorithm.newHash()
+		for _, part := range parts {
+			digest, err := base64.StdEncoding.DecodeString(algorithm.get(part.Checksums))
+			if err != nil || len(digest) == 0 {
+				h = nil
+				break
+			}
+			h.Write(digest)
+		}
+		if h != nil {
+			algorithm.set(&checksums, fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(h.Sum(nil)), len(parts)))
+		}
+	}
+
+	return checksums
+}
+
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
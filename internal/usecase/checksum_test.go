@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"hash/crc32"
+	"s3-like/internal/domain"
+	"testing"
+)
+
+func TestCompositeChecksums(t *testing.T) {
+	encode := base64.StdEncoding.EncodeToString
+	crc32Of := func(data string) []byte {
+		h := crc32.NewIEEE()
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	sha256Of := func(data []byte) []byte {
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+
+	first, second := crc32Of("first"), crc32Of("second")
+	composite := crc32.NewIEEE()
+	composite.Write(first)
+	composite.Write(second)
+
+	tests := []struct {
+		name  string
+		parts []domain.MultipartPart
+		want  domain.Checksums
+	}{
+		{
+			name: "no checksums",
+			parts: []domain.MultipartPart{
+				{PartNumber: 1},
+				{PartNumber: 2},
+			},
+		},
+		{
+			name: "every part",
+			parts: []domain.MultipartPart{
+				{PartNumber: 1, Checksums: domain.Checksums{CRC32: encode(first)}},
+				{PartNumber: 2, Checksums: domain.Checksums{CRC32: encode(second)}},
+			},
+			want: domain.Checksums{CRC32: encode(composite.Sum(nil)) + "-2"},
+		},
+		{
+			name: "single part",
+			parts: []domain.MultipartPart{
+				{PartNumber: 1, Checksums: domain.Checksums{SHA256: encode(sha256Of([]byte("part")))}},
+			},
+			want: domain.Checksums{SHA256: encode(sha256Of(sha256Of([]byte("part")))) + "-1"},
+		},
+		{
+			name: "missing on one part",
+			parts: []domain.MultipartPart{
+				{PartNumber: 1, Checksums: domain.Checksums{CRC32: encode(first)}},
+				{PartNumber: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compositeChecksums(tt.parts); got != tt.want {
+				t.Errorf("compositeChecksums() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
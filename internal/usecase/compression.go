@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"io"
+	"s3-like/internal/compression"
+	"s3-like/internal/domain"
+	"s3-like/internal/encryption"
+
+	"github.com/google/uuid"
+)
+
+// objectCompression returns the algorithm a new blob in the bucket is
+// compressed with: the bucket's, unless the content is compressed already.
+// An empty algorithm stores the content as it is.
+func objectCompression(bucketRepo domain.BucketRepository, bucketID uuid.UUID, contentType string, headers domain.ContentHeaders) (string, error) {
+	bucket, err := bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return "", err
+	}
+
+	if bucket.Compression == "" || !compression.Compressible(contentType, headers.ContentEncoding) {
+		return "", nil
+	}
+	return bucket.Compression, nil
+}
+
+// encodeBody returns body the way it is stored: compressed with algorithm,
+// then encrypted with dataKey. Either step is skipped when not set.
+// Compression has to come first, as ciphertext does not compress.
+func encodeBody(body io.Reader, algorithm string, dataKey []byte) (io.Reader, error) {
+	if algorithm != "" {
+		compressed, err := compression.NewReader(body, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		body = compressed
+	}
+	return encryptBody(body, dataKey)
+}
+
+// openObjectBlob opens the content of object, decrypting it with dataKey
+// and decompressing it as needed. A compressed stream can only be read from
+// its start, so seeking into compressed content decompresses and discards
+// everything before the new offset.
+func openObjectBlob(storage domain.StorageBackend, object *domain.Object, dataKey []byte) (io.ReadSeekCloser, error) {
+	if object.Compression == "" {
+		return openBlob(storage, object.StoragePath, object.Size, dataKey)
+	}
+
+	// The encrypted chunks hold the compressed stream
+	compressedSize := object.StoredSize
+	if dataKey != nil {
+		compressedSize = encryption.PlaintextSize(object.StoredSize)
+	}
+
+	return openBlobReader(object.Size, func(offset int64) (io.ReadCloser, error) {
+		blob, err := openBlob(storage, object.StoragePath, compressedSize, dataKey)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := compression.NewDecompressReader(blob, object.Compression)
+		if err != nil {
+			blob.Close()
+			return nil, err
+		}
+		if _, err := io.CopyN(io.Discard, content, offset); err != nil {
+			content.Close()
+			blob.Close()
+			return nil, err
+		}
+		return decompressedBlob{ReadCloser: content, blob: blob}, nil
+	})
+}
+
+// decompressedBlob closes the blob along with the decompressor reading it.
+type decompressedBlob struct {
+	io.ReadCloser
+	blob io.Closer
+}
+
+func (r decompressedBlob) Close() error {
+	r.ReadCloser.Close()
+	return r.blob.Close()
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"s3-like/internal/domain"
+	"s3-like/internal/encryption"
+
+	"github.com/google/uuid"
+)
+
+// encryptionMode returns the SSE mode of a new version in the bucket: what
+// the request asks for, or else the bucket default. An empty mode stores the
+// content as plaintext.
+func encryptionMode(bucketRepo domain.BucketRepository, bucketID uuid.UUID, req domain.EncryptionRequest) (string, error) {
+	if req.CustomerKey != nil {
+		if req.ServerSide != "" {
+			return "", domain.ErrInvalidEncryption
+		}
+		return domain.SSEModeC, nil
+	}
+
+	algorithm := req.ServerSide
+	if algorithm == "" {
+		bucket, err := bucketRepo.GetByID(bucketID)
+		if err != nil {
+			return "", err
+		}
+		algorithm = bucket.DefaultEncryption
+	}
+
+	switch algorithm {
+	case "":
+		return "", nil
+	case domain.SSEAlgorithmAES256:
+		return domain.SSEModeS3, nil
+	default:
+		return "", domain.ErrInvalidEncryption
+	}
+}
+
+// newDataKey generates the data key of a new version encrypted with mode and
+// returns it along with the encryption to record, which holds it wrapped. It
+// returns a nil key for plaintext.
+func newDataKey(keys domain.KeyManager, mode string, customerKey []byte) (domain.ObjectEncryption, []byte, error) {
+	enc := domain.ObjectEncryption{SSE: mode}
+
+	switch mode {
+	case "":
+		return enc, nil, nil
+	case domain.SSEModeS3:
+		// Fail before generating anything when no master key is configured
+		if keys.ActiveKeyID() == "" {
+			return enc, nil, domain.ErrEncryptionNotConfigured
+		}
+	case domain.SSEModeC:
+		if len(customerKey) != encryption.KeySize {
+			return enc, nil, domain.ErrInvalidCustomerKey
+		}
+	}
+
+	dataKey, err := encryption.GenerateKey()
+	if err != nil {
+		return enc, nil, err
+	}
+
+	if mode == domain.SSEModeC {
+		enc.WrappedKey, err = encryption.WrapKey(customerKey, dataKey)
+		enc.CustomerKeyMD5 = customerKeyMD5(customerKey)
+	} else {
+		enc.MasterKeyID, enc.WrappedKey, err = keys.WrapKey(dataKey)
+	}
+	if err != nil {
+		return enc, nil, err
+	}
+	return enc, dataKey, nil
+}
+
+// newObjectEncryption combines encryptionMode and newDataKey.
+func newObjectEncryption(bucketRepo domain.BucketRepository, keys domain.KeyManager, bucketID uuid.UUID, req domain.EncryptionRequest) (domain.ObjectEncryption, []byte, error) {
+	mode, err := encryptionMode(bucketRepo, bucketID, req)
+	if err != nil {
+		return domain.ObjectEncryption{}, nil, err
+	}
+	return newDataKey(keys, mode, req.CustomerKey)
+}
+
+// objectDataKey unwraps the data key of an encrypted version or upload. SSE-C
+// needs the customer key it was written with. It returns a nil key for
+// plaintext.
+func objectDataKey(keys domain.KeyManager, enc domain.ObjectEncryption, customerKey []byte) ([]byte, error) {
+	switch enc.SSE {
+	case "":
+		return nil, nil
+	case domain.SSEModeS3:
+		return keys.UnwrapKey(enc.MasterKeyID, enc.WrappedKey)
+	case domain.SSEModeC:
+		if customerKey == nil {
+			return nil, domain.ErrCustomerKeyRequired
+		}
+		if customerKeyMD5(customerKey) != enc.CustomerKeyMD5 {
+			return nil, domain.ErrCustomerKeyMismatch
+		}
+		dataKey, err := encryption.UnwrapKey(customerKey, enc.WrappedKey)
+		if err != nil {
+			return nil, domain.ErrCustomerKeyMismatch
+		}
+		return dataKey, nil
+	default:
+		return nil, domain.ErrInvalidEncryption
+	}
+}
+
+// sameEncryption reports whether content encrypted as src can be copied
+// as-is to a version encrypted with mode and customerKey, keeping its wrapped
+// data key.
+func sameEncryption(src domain.ObjectEncryption, mode string, customerKey []byte) bool {
+	if src.SSE != mode {
+		return false
+	}
+	if mode == domain.SSEModeC {
+		return customerKeyMD5(customerKey) == src.CustomerKeyMD5
+	}
+	return true
+}
+
+// customerKeyMD5 returns the base64 MD5 of an SSE-C key, as sent in the
+// x-amz-server-side-encryption-customer-key-MD5 header.
+func customerKeyMD5(key []byte) string {
+	digest := md5.Sum(key)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// encryptBody returns body encrypted with dataKey, or body itself when the
+// key is nil.
+func encryptBody(body io.Reader, dataKey []byte) (io.Reader, error) {
+	if dataKey == nil {
+		return body, nil
+	}
+	return encryption.NewEncryptReader(body, dataKey)
+}
+
+// openBlob opens the stored content of size bytes, decrypting it with
+// dataKey unless the key is nil.
+func openBlob(storage domain.StorageBackend, path string, size int64, dataKey []byte) (io.ReadSeekCloser, error) {
+	if dataKey == nil {
+		return newBlobReader(storage, path, size)
+	}
+
+	return openBlobReader(size, func(offset int64) (io.ReadCloser, error) {
+		// Decryption has to start at the chunk holding offset
+		chunk := offset / encryption.ChunkSize
+		start := encryption.ChunkOffset(chunk)
+		file, err := storage.GetRange(context.Background(), path, start, encryption.EncryptedSize(size)-start)
+		if err != nil {
+			return nil, err
+		}
+
+		plain, err := encryption.NewDecryptReader(file, dataKey, chunk, size)
+		if err == nil {
+			_, err = io.CopyN(io.Discard, plain, offset-chunk*encryption.ChunkSize)
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return readCloser{Reader: plain, Closer: file}, nil
+	})
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"s3-like/internal/encryption"
+	"s3-like/internal/storage"
+	"testing"
+)
+
+func TestOpenEncryptedBlobSeek(t *testing.T) {
+	const size = 3*encryption.ChunkSize + 100
+	plain := make([]byte, size)
+	rand.New(rand.NewSource(1)).Read(plain)
+
+	dataKey, err := encryption.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	body, err := encryptBody(bytes.NewReader(plain), dataKey)
+	if err != nil {
+		t.Fatalf("encryptBody: %v", err)
+	}
+	backend := storage.NewMemoryBackend()
+	if _, err := backend.Put(context.Background(), "blob", body); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		length int64
+	}{
+		{"start", 0, 10},
+		{"end of the first chunk", encryption.ChunkSize - 5, 5},
+		{"across a chunk boundary", encryption.ChunkSize - 5, 10},
+		{"start of a chunk", 2 * encryption.ChunkSize, 10},
+		{"middle of a chunk", encryption.ChunkSize + 1234, 100},
+		{"across several chunks", 100, 2*encryption.ChunkSize + 50},
+		{"last byte", size - 1, 1},
+		{"to the end", 2*encryption.ChunkSize + 7, size - (2*encryption.ChunkSize + 7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := openBlob(backend, "blob", size, dataKey)
+			if err != nil {
+				t.Fatalf("openBlob: %v", err)
+			}
+			defer r.Close()
+
+			if _, err := r.Seek(tt.offset, io.SeekStart); err != nil {
+				t.Fatalf("Seek(%d): %v", tt.offset, err)
+			}
+			got := make([]byte, tt.length)
+			if _, err := io.ReadFull(r, got); err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if !bytes.Equal(got, plain[tt.offset:tt.offset+tt.length]) {
+				t.Errorf("read %d bytes at %d that differ from the plaintext", tt.length, tt.offset)
+			}
+		})
+	}
+}
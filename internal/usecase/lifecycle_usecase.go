@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"s3-like/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type lifecycleUseCase struct {
+	lifecycleRepo    domain.LifecycleRepository
+	bucketRepo       domain.BucketRepository
+	objectRepo       domain.ObjectRepository
+	multipartUseCase domain.MultipartUseCase
+	storage          domain.StorageBackend
+	batchSize        int
+}
+
+func NewLifecycleUseCase(lifecycleRepo domain.LifecycleRepository, bucketRepo domain.BucketRepository, objectRepo domain.ObjectRepository, multipartUseCase domain.MultipartUseCase, storage domain.StorageBackend, batchSize int) domain.LifecycleUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &lifecycleUseCase{
+		lifecycleRepo:    lifecycleRepo,
+		bucketRepo:       bucketRepo,
+		objectRepo:       objectRepo,
+		multipartUseCase: multipartUseCase,
+		storage:          storage,
+		batchSize:        batchSize,
+	}
+}
+
+func (uc *lifecycleUseCase) GetBucketLifecycle(userID uuid.UUID, bucketName string) (*domain.BucketLifecycle, error) {
+	bucket, err := uc.ownedBucket(userID, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	return uc.lifecycleRepo.Get(bucket.ID)
+}
+
+func (uc *lifecycleUseCase) PutBucketLifecycle(userID uuid.UUID, bucketName string, rules []domain.LifecycleRule) (*domain.BucketLifecycle, error) {
+	if err := validateLifecycleRules(rules); err != nil {
+		return nil, err
+	}
+
+	bucket, err := uc.ownedBucket(userID, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		if rules[i].ID == "" {
+			rules[i].ID = uuid.New().String()
+		}
+	}
+
+	lifecycle := &domain.BucketLifecycle{
+		BucketID: bucket.ID,
+		Rules:    rules,
+	}
+	if err := uc.lifecycleRepo.Save(lifecycle); err != nil {
+		return nil, err
+	}
+
+	return lifecycle, nil
+}
+
+func (uc *lifecycleUseCase) DeleteBucketLifecycle(userID uuid.UUID, bucketName string) error {
+	bucket, err := uc.ownedBucket(userID, bucketName)
+	if err != nil {
+		return err
+	}
+	return uc.lifecycleRepo.Delete(bucket.ID)
+}
+
+// ApplyLifecycle runs every bucket's rules once. A failing bucket does not
+// stop the others; its error is reported along with the rest.
+func (uc *lifecycleUseCase) ApplyLifecycle() error {
+	lifecycles, err := uc.lifecycleRepo.List()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+	for i := range lifecycles {
+		if err := uc.applyBucketLifecycle(&lifecycles[i], now); err != nil {
+			errs = append(errs, fmt.Errorf("bucket %s: %w", lifecycles[i].BucketID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ownedBucket looks up a bucket whose lifecycle userID may manage.
+func (uc *lifecycleUseCase) ownedBucket(userID uuid.UUID, bucketName string) (*domain.Bucket, error) {
+	bucket, err := uc.bucketRepo.GetByName(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	if bucket.UserID != userID {
+		return nil, domain.ErrAccessDenied
+	}
+
+	return bucket, nil
+}
+
+func (uc *lifecycleUseCase) applyBucketLifecycle(lifecycle *domain.BucketLifecycle, now time.Time) error {
+	bucket, err := uc.bucketRepo.GetByID(lifecycle.BucketID)
+	if errors.Is(err, domain.ErrBucketNotFound) {
+		// The bucket is gone; its rules have nothing left to act on
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var uploadRules, objectRules []domain.LifecycleRule
+	for _, rule := range lifecycle.Rules {
+		if rule.Status != domain.LifecycleEnabled {
+			continue
+		}
+		if rule.AbortIncompleteMultipartUpload != nil {
+			uploadRules = append(uploadRules, rule)
+		}
+		if rule.Expiration != nil || rule.NoncurrentVersionExpiration != nil {
+			objectRules = append(objectRules, rule)
+		}
+	}
+
+	if len(uploadRules) > 0 {
+		if err := uc.abortIncompleteUploads(bucket.ID, uploadRules, now); err != nil {
+			return err
+		}
+	}
+
+	if len(objectRules) == 0 {
+		return nil
+	}
+
+	// Only keys under a prefix shared by every rule can match, so the walk is
+	// narrowed to it
+	prefix := objectRules[0].Filter.Prefix
+	for _, rule := range objectRules[1:] {
+		prefix = commonPrefix(prefix, rule.Filter.Prefix)
+	}
+
+	var expired int
+	startAfter := ""
+	for {
+		keys, err := uc.objectRepo.ListKeys(bucket.ID, prefix, startAfter, uc.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			n, err := uc.expireKey(bucket, key, objectRules, now)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			expired += n
+		}
+
+		if len(keys) < uc.batchSize {
+			break
+		}
+		startAfter = keys[len(keys)-1]
+	}
+
+	if expired > 0 {
+		log.Printf("Lifecycle expired %d object versions in bucket %s", expired, bucket.Name)
+	}
+	return nil
+}
+
+// expireKey applies the rules to every version of one key in a single
+// transaction and returns how many versions it removed or hid.
+func (uc *lifecycleUseCase) expireKey(bucket *domain.Bucket, key string, rules []domain.LifecycleRule, now time.Time) (int, error) {
+	var storagePaths []string
+	var expired int
+
+	err := uc.objectRepo.Transaction(func(repo domain.ObjectRepository) error {
+		if err := repo.LockKey(bucket.ID, key); err != nil {
+			return err
+		}
+
+		versions, err := repo.GetVersions(bucket.ID, key)
+		if err != nil || len(versions) == 0 {
+			return err
+		}
+
+		plan := planExpiration(versions, rules, now)
+
+		for i := range plan.noncurrent {
+			storagePath, err := deleteObjectVersion(repo, &plan.noncurrent[i])
+			if err != nil {
+				return err
+			}
+			if storagePath != "" {
+				storagePaths = append(storagePaths, storagePath)
+			}
+		}
+		expired += len(plan.noncurrent)
+
+		if plan.expireCurrent {
+			_, storagePath, err := deleteObject(repo, bucket.ID, bucket.Versioning, domain.ObjectIdentifier{Key: key}, false)
+			if err != nil {
+				return err
+			}
+			if storagePath != "" {
+				storagePaths = append(storagePaths, storagePath)
+			}
+			expired++
+		}
+
+		if plan.removeMarker {
+			if _, err := deleteObjectVersion(repo, &versions[0]); err != nil {
+				return err
+			}
+			expired++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, storagePath := range storagePaths {
+		if err := uc.storage.Delete(context.Background(), storagePath); err != nil {
+			log.Printf("failed to delete blob %s: %v", storagePath, err)
+		}
+	}
+
+	return expired, nil
+}
+
+func (uc *lifecycleUseCase) abortIncompleteUploads(bucketID uuid.UUID, rules []domain.LifecycleRule, now time.Time) error {
+	uploads, err := uc.multipartUseCase.ListMultipartUploads(bucketID)
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		for _, rule := range rules {
+			if !strings.HasPrefix(upload.Key, rule.Filter.Prefix) ||
+				!daysElapsed(upload.CreatedAt, rule.AbortIncompleteMultipartUpload.DaysAfterInitiation, now) {
+				continue
+			}
+
+			err := uc.multipartUseCase.AbortMultipartUpload(bucketID, upload.ID)
+			if err != nil && !errors.Is(err, domain.ErrUploadNotFound) {
+				return fmt.Errorf("failed to abort upload %s: %w", upload.ID, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// lifecyclePlan is what the rules decided for the versions of one key.
+type lifecyclePlan struct {
+	// noncurrent holds the noncurrent versions to delete permanently
+	noncurrent []domain.Object
+	// expireCurrent hides or removes the current version like a plain delete
+	expireCurrent bool
+	// removeMarker deletes the current version, a delete marker that no
+	// longer hides anything
+	removeMarker bool
+}
+
+// planExpiration evaluates rules against the versions of one key, newest
+// first. A version becomes noncurrent when the next newer one is written, so
+// that version's creation time is when the noncurrent clock starts.
+func planExpiration(versions []domain.Object, rules []domain.LifecycleRule, now time.Time) lifecyclePlan {
+	var plan lifecyclePlan
+	current := &versions[0]
+	expired := make([]bool, len(versions))
+
+	for _, rule := range rules {
+		if exp := rule.Expiration; exp != nil && exp.Days > 0 &&
+			current.IsLatest && !current.IsDeleteMarker &&
+			filterMatches(&rule.Filter, current) && daysElapsed(current.CreatedAt, exp.Days, now) {
+			plan.expireCurrent = true
+		}
+
+		if nve := rule.NoncurrentVersionExpiration; nve != nil {
+			for i := 1; i < len(versions); i++ {
+				if !filterMatches(&rule.Filter, &versions[i]) {
+					continue
+				}
+				if nve.NoncurrentDays > 0 && !daysElapsed(versions[i-1].CreatedAt, nve.NoncurrentDays, now) {
+					continue
+				}
+				// i-1 noncurrent versions are newer than this one
+				if nve.NewerNoncurrentVersions > 0 && i-1 < nve.NewerNoncurrentVersions {
+					continue
+				}
+				expired[i] = true
+			}
+		}
+	}
+
+	// Lifecycle never bypasses Object Lock; a locked version waits for a
+	// later run
+	for i := 1; i < len(versions); i++ {
+		if expired[i] && checkObjectLock(&versions[i].ObjectLock, false, now) == nil {
+			plan.noncurrent = append(plan.noncurrent, versions[i])
+		}
+	}
+
+	// A delete marker is expired once every version behind it is gone
+	if current.IsDeleteMarker && len(plan.noncurrent) == len(versions)-1 {
+		for _, rule := range rules {
+			if rule.Expiration != nil && rule.Expiration.ExpiredObjectDeleteMarker &&
+				strings.HasPrefix(current.Key, rule.Filter.Prefix) {
+				plan.removeMarker = true
+				break
+			}
+		}
+	}
+
+	return plan
+}
+
+// filterMatches reports whether object passes every condition of filter.
+func filterMatches(filter *domain.LifecycleFilter, object *domain.Object) bool {
+	if !strings.HasPrefix(object.Key, filter.Prefix) {
+		return false
+	}
+	if !tagsMatch(object.Tags, filter.Tags) {
+		return false
+	}
+	if filter.ObjectSizeGreaterThan > 0 && object.Size <= filter.ObjectSizeGreaterThan {
+		return false
+	}
+	if filter.ObjectSizeLessThan > 0 && object.Size >= filter.ObjectSizeLessThan {
+		return false
+	}
+	return true
+}
+
+// daysElapsed reports whether at least days whole days have passed since t.
+func daysElapsed(t time.Time, days int, now time.Time) bool {
+	return !now.Before(t.Add(time.Duration(days) * 24 * time.Hour))
+}
+
+func commonPrefix(a, b string) string {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return a[:i]
+		}
+	}
+	return a[:n]
+}
+
+// validateLifecycleRules applies the constraints S3 puts on a lifecycle
+// configuration, beyond what request binding already checks.
+func validateLifecycleRules(rules []domain.LifecycleRule) error {
+	if len(rules) == 0 || len(rules) > domain.MaxLifecycleRules {
+		return fmt.Errorf("%w: between 1 and %d rules are required", domain.ErrInvalidLifecycle, domain.MaxLifecycleRules)
+	}
+
+	ids := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				return fmt.Errorf("%w: duplicate rule ID %q", domain.ErrInvalidLifecycle, rule.ID)
+			}
+			ids[rule.ID] = true
+		}
+
+		if rule.Status != domain.LifecycleEnabled && rule.Status != domain.LifecycleDisabled {
+			return fmt.Errorf("%w: rule %q: status must be Enabled or Disabled", domain.ErrInvalidLifecycle, rule.ID)
+		}
+		if rule.Expiration == nil && rule.NoncurrentVersionExpiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+			return fmt.Errorf("%w: rule %q has no action", domain.ErrInvalidLifecycle, rule.ID)
+		}
+
+		filter := rule.Filter
+		if filter.ObjectSizeGreaterThan < 0 || filter.ObjectSizeLessThan < 0 ||
+			(filter.ObjectSizeLessThan > 0 && filter.ObjectSizeGreaterThan >= filter.ObjectSizeLessThan) {
+			return fmt.Errorf("%w: rule %q: invalid object size range", domain.ErrInvalidLifecycle, rule.ID)
+		}
+
+		if exp := rule.Expiration; exp != nil {
+			if exp.Days < 0 || (exp.Days == 0) == !exp.ExpiredObjectDeleteMarker {
+				return fmt.Errorf("%w: rule %q: expiration needs either days or expired_object_delete_marker", domain.ErrInvalidLifecycle, rule.ID)
+			}
+			if exp.ExpiredObjectDeleteMarker && len(filter.Tags) > 0 {
+				return fmt.Errorf("%w: rule %q: expired_object_delete_marker cannot be used with a tag filter", domain.ErrInvalidLifecycle, rule.ID)
+			}
+		}
+
+		if nve := rule.NoncurrentVersionExpiration; nve != nil {
+			if nve.NoncurrentDays < 0 || nve.NewerNoncurrentVersions < 0 ||
+				(nve.NoncurrentDays == 0 && nve.NewerNoncurrentVersions == 0) {
+				return fmt.Errorf("%w: rule %q: noncurrent version expiration needs noncurrent_days or newer_noncurrent_versions", domain.ErrInvalidLifecycle, rule.ID)
+			}
+		}
+
+		if abort := rule.AbortIncompleteMultipartUpload; abort != nil {
+			if abort.DaysAfterInitiation <= 0 {
+				return fmt.Errorf("%w: rule %q: days_after_initiation must be positive", domain.ErrInvalidLifecycle, rule.ID)
+			}
+			if len(filter.Tags) > 0 || filter.ObjectSizeGreaterThan > 0 || filter.ObjectSizeLessThan > 0 {
+				return fmt.Errorf("%w: rule %q: aborting incomplete uploads only supports a prefix filter", domain.ErrInvalidLifecycle, rule.ID)
+			}
+		}
+	}
+
+	return nil
+}
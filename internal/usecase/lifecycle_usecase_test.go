@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"s3-like/internal/domain"
+	"s3-like/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPlanExpiration(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	// versions builds the versions of one key, newest first, from their ages
+	// in days; the first is the current version
+	versions := func(ages ...int) []domain.Object {
+		objects := make([]domain.Object, len(ages))
+		for i, age := range ages {
+			objects[i] = domain.Object{
+				Key:       "logs/app.log",
+				VersionID: string(rune('a' + i)),
+				Size:      100,
+				IsLatest:  i == 0,
+				CreatedAt: daysAgo(age),
+			}
+		}
+		return objects
+	}
+	withMarker := func(objects []domain.Object) []domain.Object {
+		objects[0].IsDeleteMarker = true
+		objects[0].Size = 0
+		return objects
+	}
+	withTags := func(objects []domain.Object, tags domain.ObjectTags) []domain.Object {
+		objects[0].Tags = tags
+		return objects
+	}
+	withLock := func(objects []domain.Object, i int, lock domain.ObjectLock) []domain.Object {
+		objects[i].ObjectLock = lock
+		return objects
+	}
+
+	expiration := func(filter domain.LifecycleFilter, days int) domain.LifecycleRule {
+		return domain.LifecycleRule{Status: domain.LifecycleEnabled, Filter: filter, Expiration: &domain.LifecycleExpiration{Days: days}}
+	}
+	noncurrent := func(days, newer int) domain.LifecycleRule {
+		return domain.LifecycleRule{Status: domain.LifecycleEnabled, NoncurrentVersionExpiration: &domain.NoncurrentVersionExpiration{NoncurrentDays: days, NewerNoncurrentVersions: newer}}
+	}
+	markerCleanup := func(prefix string) domain.LifecycleRule {
+		return domain.LifecycleRule{Status: domain.LifecycleEnabled, Filter: domain.LifecycleFilter{Prefix: prefix}, Expiration: &domain.LifecycleExpiration{ExpiredObjectDeleteMarker: true}}
+	}
+
+	tests := []struct {
+		name          string
+		versions      []domain.Object
+		rules         []domain.LifecycleRule
+		noncurrent    string
+		expireCurrent bool
+		removeMarker  bool
+	}{
+		{
+			name:          "current version old enough",
+			versions:      versions(30),
+			rules:         []domain.LifecycleRule{expiration(domain.LifecycleFilter{}, 30)},
+			expireCurrent: true,
+		},
+		{
+			name:     "current version too young",
+			versions: versions(29),
+			rules:    []domain.LifecycleRule{expiration(domain.LifecycleFilter{}, 30)},
+		},
+		{
+			name:     "prefix does not match",
+			versions: versions(60),
+			rules:    []domain.LifecycleRule{expiration(domain.LifecycleFilter{Prefix: "tmp/"}, 30)},
+		},
+		{
+			name:          "tags match",
+			versions:      withTags(versions(60), domain.ObjectTags{"env": "dev", "team": "a"}),
+			rules:         []domain.LifecycleRule{expiration(domain.LifecycleFilter{Tags: map[string]string{"env": "dev"}}, 30)},
+			expireCurrent: true,
+		},
+		{
+			name:     "tags do not match",
+			versions: versions(60),
+			rules:    []domain.LifecycleRule{expiration(domain.LifecycleFilter{Tags: map[string]string{"env": "dev"}}, 30)},
+		},
+		{
+			name:     "size outside the bounds",
+			versions: versions(60),
+			rules: []domain.LifecycleRule{
+				expiration(domain.LifecycleFilter{ObjectSizeGreaterThan: 100}, 30),
+				expiration(domain.LifecycleFilter{ObjectSizeLessThan: 100}, 30),
+			},
+		},
+		{
+			name:          "size inside the bounds",
+			versions:      versions(60),
+			rules:         []domain.LifecycleRule{expiration(domain.LifecycleFilter{ObjectSizeGreaterThan: 99, ObjectSizeLessThan: 101}, 30)},
+			expireCurrent: true,
+		},
+		{
+			name:     "delete marker is not expired as a current version",
+			versions: withMarker(versions(60, 90)),
+			rules:    []domain.LifecycleRule{expiration(domain.LifecycleFilter{}, 30)},
+		},
+		{
+			// b became noncurrent 10 days ago, c 20 days ago, d 40 days ago
+			name:       "noncurrent days count from the newer version",
+			versions:   versions(10, 20, 40, 50),
+			rules:      []domain.LifecycleRule{noncurrent(15, 0)},
+			noncurrent: "c,d",
+		},
+		{
+			name:       "newer noncurrent versions are kept",
+			versions:   versions(1, 2, 3, 4, 5),
+			rules:      []domain.LifecycleRule{noncurrent(0, 2)},
+			noncurrent: "d,e",
+		},
+		{
+			name:       "noncurrent days and newer versions combined",
+			versions:   versions(1, 2, 30, 40, 50),
+			rules:      []domain.LifecycleRule{noncurrent(10, 1)},
+			noncurrent: "d,e",
+		},
+		{
+			name:          "rules combine",
+			versions:      versions(40, 50, 60),
+			rules:         []domain.LifecycleRule{expiration(domain.LifecycleFilter{}, 30), noncurrent(5, 1)},
+			noncurrent:    "c",
+			expireCurrent: true,
+		},
+		{
+			name:       "locked versions wait",
+			versions:   withLock(withLock(versions(10, 20, 30, 40), 1, domain.ObjectLock{LegalHold: true}), 2, domain.ObjectLock{RetentionMode: domain.RetentionGovernance, RetainUntil: &future}),
+			rules:      []domain.LifecycleRule{noncurrent(1, 0)},
+			noncurrent: "d",
+		},
+		{
+			name:       "lapsed retention does not hold a version",
+			versions:   withLock(versions(10, 20), 1, domain.ObjectLock{RetentionMode: domain.RetentionCompliance, RetainUntil: &past}),
+			rules:      []domain.LifecycleRule{noncurrent(1, 0)},
+			noncurrent: "b",
+		},
+		{
+			name:         "lone delete marker is removed",
+			versions:     withMarker(versions(5)),
+			rules:        []domain.LifecycleRule{markerCleanup("logs/")},
+			removeMarker: true,
+		},
+		{
+			name:         "delete marker removed with the last version behind it",
+			versions:     withMarker(versions(5, 40)),
+			rules:        []domain.LifecycleRule{noncurrent(1, 0), markerCleanup("")},
+			noncurrent:   "b",
+			removeMarker: true,
+		},
+		{
+			name:       "delete marker kept while a version survives",
+			versions:   withMarker(versions(5, 10, 40)),
+			rules:      []domain.LifecycleRule{noncurrent(1, 1), markerCleanup("")},
+			noncurrent: "c",
+		},
+		{
+			name:     "delete marker cleanup needs a matching prefix",
+			versions: withMarker(versions(5)),
+			rules:    []domain.LifecycleRule{markerCleanup("tmp/")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planExpiration(tt.versions, tt.rules, now)
+
+			var ids []string
+			for _, object := range plan.noncurrent {
+				ids = append(ids, object.VersionID)
+			}
+			if got := strings.Join(ids, ","); got != tt.noncurrent {
+				t.Errorf("noncurrent = %q, want %q", got, tt.noncurrent)
+			}
+			if plan.expireCurrent != tt.expireCurrent {
+				t.Errorf("expireCurrent = %v, want %v", plan.expireCurrent, tt.expireCurrent)
+			}
+			if plan.removeMarker != tt.removeMarker {
+				t.Errorf("removeMarker = %v, want %v", plan.removeMarker, tt.removeMarker)
+			}
+		})
+	}
+}
+
+func TestApplyBucketLifecycleRemovesRows(t *testing.T) {
+	now := time.Now()
+	bucketID := uuid.New()
+	repo := newMemoryObjectRepo()
+
+	// Oldest first, so GetVersions returns them newest first
+	for _, age := range []int{40, 30, 20, 10} {
+		repo.Create(&domain.Object{Key: "logs/app.log", BucketID: bucketID, VersionID: uuid.New().String(), IsLatest: age == 10, CreatedAt: now.AddDate(0, 0, -age)})
+	}
+	repo.Create(&domain.Object{Key: "logs/db.log", BucketID: bucketID, VersionID: uuid.New().String(), IsLatest: true, CreatedAt: now})
+	repo.Create(&domain.Object{Key: "readme.txt", BucketID: bucketID, VersionID: uuid.New().String(), IsLatest: true, CreatedAt: now.AddDate(0, 0, -90)})
+
+	uc := &lifecycleUseCase{bucketRepo: versionedBucketRepo{}, objectRepo: repo, storage: storage.NewMemoryBackend(), batchSize: 1}
+	lifecycle := &domain.BucketLifecycle{
+		BucketID: bucketID,
+		Rules: []domain.LifecycleRule{{
+			Status:                      domain.LifecycleEnabled,
+			Filter:                      domain.LifecycleFilter{Prefix: "logs/"},
+			NoncurrentVersionExpiration: &domain.NoncurrentVersionExpiration{NoncurrentDays: 1},
+		}},
+	}
+	if err := uc.applyBucketLifecycle(lifecycle, now); err != nil {
+		t.Fatalf("applyBucketLifecycle: %v", err)
+	}
+
+	// The three noncurrent versions of logs/app.log are gone for good; only
+	// the current versions are left
+	if rows := len(repo.state.objects); rows != 3 {
+		t.Fatalf("%d rows left after expiration, want 3", rows)
+	}
+	for _, object := range repo.state.objects {
+		if !object.IsLatest {
+			t.Errorf("noncurrent version of %s survived expiration", object.Key)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package usecase
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"s3-like/internal/domain"
+	"s3-like/internal/encryption"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minPartNumber = 1
+	maxPartNumber = 10000
+	minPartSize   = 5 << 20 // 5 MiB, except for the last part
+)
+
+type multipartUseCase struct {
+	uploadRepo domain.MultipartUploadRepository
+	objectRepo domain.ObjectRepository
+	bucketRepo domain.BucketRepository
+	storage    domain.StorageBackend
+	keys       domain.KeyManager
+}
+
+func NewMultipartUseCase(uploadRepo domain.MultipartUploadRepository, objectRepo domain.ObjectRepository, bucketRepo domain.BucketRepository, storage domain.StorageBackend, keys domain.KeyManager) domain.MultipartUseCase {
+	return &multipartUseCase{
+		uploadRepo: uploadRepo,
+		objectRepo: objectRepo,
+		bucketRepo: bucketRepo,
+		storage:    storage,
+		keys:       keys,
+	}
+}
+
+func (uc *multipartUseCase) CreateMultipartUpload(bucketID uuid.UUID, key string, opts *domain.PutObjectOptions) (*domain.MultipartUpload, error) {
+	if opts == nil {
+		opts = &domain.PutObjectOptions{}
+	}
+
+	if err := validateTags(opts.Tags); err != nil {
+		return nil, err
+	}
+
+	metadata, err := normalizeMetadata(opts.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock is only applied at completion, but a request the bucket
+	// cannot honor is turned away now
+	bucket, err := uc.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := newObjectLock(bucket, opts.ObjectLock, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Every part would need the customer key again, which is not kept
+	mode, err := encryptionMode(uc.bucketRepo, bucketID, opts.Encryption)
+	if err != nil {
+		return nil, err
+	}
+	if mode == domain.SSEModeC {
+		return nil, domain.ErrSSECMultipart
+	}
+	enc, _, err := newDataKey(uc.keys, mode, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Headers, metadata, tags and upload info are kept aside until completion
+	upload := &domain.MultipartUpload{
+		BucketID:         bucketID,
+		Key:              key,
+		ContentType:      opts.ContentType,
+		ContentHeaders:   opts.ContentHeaders,
+		Metadata:         metadata,
+		Tags:             opts.Tags,
+		UploadInfo:       opts.UploadInfo,
+		ObjectEncryption: enc,
+		ObjectLock:       opts.ObjectLock,
+	}
+
+	if err := uc.uploadRepo.Create(upload); err != nil {
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+func (uc *multipartUseCase) UploadPart(bucketID, uploadID uuid.UUID, partNumber int, body io.Reader, opts *domain.UploadPartOptions) (*domain.MultipartPart, error) {
+	if partNumber < minPartNumber || partNumber > maxPartNumber {
+		return nil, domain.ErrInvalidPartNumber
+	}
+
+	if opts == nil {
+		opts = &domain.UploadPartOptions{}
+	}
+	digests, err := newBodyDigests(opts.ContentMD5, opts.Checksums)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := uc.getUpload(bucketID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := objectDataKey(uc.keys, upload.ObjectEncryption, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each attempt gets its own path so a retried part never overwrites one
+	// that a concurrent completion may be reading
+	storagePath := path.Join(multipartStoragePrefix(uploadID), fmt.Sprintf("%05d-%s", partNumber, uuid.New().String()))
+
+	content, err := encryptBody(digests.reader(body), partKey(dataKey, storagePath))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.storage.Put(context.Background(), storagePath, content); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, domain.ErrIncompleteBody
+		}
+		return nil, err
+	}
+
+	checksums, err := digests.verify()
+	if err != nil {
+		uc.storage.Delete(context.Background(), storagePath)
+		return nil, err
+	}
+
+	part := &domain.MultipartPart{
+		UploadID:    uploadID,
+		PartNumber:  partNumber,
+		Size:        digests.size(),
+		ETag:        digests.etag(),
+		Checksums:   checksums,
+		StoragePath: storagePath,
+	}
+
+	if err := uc.uploadRepo.SavePart(part); err != nil {
+		uc.storage.Delete(context.Background(), storagePath)
+		return nil, err
+	}
+
+	// The part this one replaces is left in place, as a completion may have
+	// listed it already; discarding the upload removes it
+	return part, nil
+}
+
+func (uc *multipartUseCase) ListParts(bucketID, uploadID uuid.UUID) (*domain.MultipartUpload, []domain.MultipartPart, error) {
+	upload, err := uc.getUpload(bucketID, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parts, err := uc.uploadRepo.ListParts(uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return upload, parts, nil
+}
+
+func (uc *multipartUseCase) ListMultipartUploads(bucketID uuid.UUID) ([]domain.MultipartUpload, error) {
+	return uc.uploadRepo.ListByBucket(bucketID)
+}
+
+// CompleteMultipartUpload concatenates the listed parts into a new object
+// version. Like S3, the ETag is the MD5 of the concatenated binary part MD5s
+// followed by "-<number of parts>"; checksums the parts were uploaded with
+// are combined the same way.
+func (uc *multipartUseCase) CompleteMultipartUpload(bucketID, uploadID uuid.UUID, completed []domain.CompletedPart) (response *domain.UploadObjectResponse, err error) {
+	if len(completed) == 0 {
+		return nil, domain.ErrNoParts
+	}
+	for i := 1; i < len(completed); i++ {
+		if completed[i].PartNumber <= completed[i-1].PartNumber {
+			return nil, domain.ErrInvalidPartOrder
+		}
+	}
+
+	upload, err := uc.getUpload(bucketID, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A completion running at the same time fails as if this one had
+	// finished already; if this one fails, the upload may be completed again
+	if err := uc.uploadRepo.Claim(uploadID); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if unclaimErr := uc.uploadRepo.Unclaim(uploadID); unclaimErr != nil {
+				log.Printf("failed to release multipart upload %s: %v", uploadID, unclaimErr)
+			}
+		}
+	}()
+
+	stored, err := uc.uploadRepo.ListParts(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	byNumber := make(map[int]domain.MultipartPart, len(stored))
+	for _, part := range stored {
+		byNumber[part.PartNumber] = part
+	}
+
+	parts := make([]domain.MultipartPart, 0, len(completed))
+	var size int64
+	etagHasher := md5.New()
+	for i, c := range completed {
+		part, ok := byNumber[c.PartNumber]
+		if !ok || strings.Trim(c.ETag, "\"") != part.ETag {
+			return nil, domain.ErrInvalidPart
+		}
+		if i < len(completed)-1 && part.Size < minPartSize {
+			return nil, domain.ErrEntityTooSmall
+		}
+
+		digest, err := hex.DecodeString(part.ETag)
+		if err != nil {
+			return nil, domain.ErrInvalidPart
+		}
+		etagHasher.Write(digest)
+		parts = append(parts, part)
+		size += part.Size
+	}
+
+	versionID, lock, err := newVersion(uc.bucketRepo, bucketID, upload.ObjectLock)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := objectDataKey(uc.keys, upload.ObjectEncryption, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := defaultContentType(upload.ContentType)
+	algorithm, err := objectCompression(uc.bucketRepo, bucketID, contentType, upload.ContentHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assemble the parts into the final blob, compressed as the bucket asks
+	// and encrypted with the upload's data key. Parts are never compressed,
+	// as most of them would be thrown away again.
+	content, err := encodeBody(newPartsReader(uc.storage, parts, dataKey), algorithm, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := stageBlob(uc.storage, content)
+	if err != nil {
+		return nil, err
+	}
+
+	object := &domain.Object{
+		Key:              upload.Key,
+		BucketID:         bucketID,
+		VersionID:        versionID,
+		Size:             size,
+		ContentType:      contentType,
+		ContentHeaders:   upload.ContentHeaders,
+		ETag:             fmt.Sprintf("%x-%d", etagHasher.Sum(nil), len(parts)),
+		Checksums:        compositeChecksums(parts),
+		ObjectEncryption: upload.ObjectEncryption,
+		Compression:      algorithm,
+		Metadata:         upload.Metadata,
+		UploadInfo:       upload.UploadInfo,
+		ObjectLock:       lock,
+		Tags:             upload.Tags,
+	}
+
+	if err := commitObjectBlob(uc.objectRepo, uc.storage, object, blob); err != nil {
+		return nil, err
+	}
+
+	// The object is committed; leftover parts are only garbage from here on
+	if err := uc.discardUpload(uploadID); err != nil {
+		log.Printf("failed to clean up multipart upload %s: %v", uploadID, err)
+	}
+
+	return &domain.UploadObjectResponse{
+		Object:    *object,
+		VersionID: versionID,
+	}, nil
+}
+
+func (uc *multipartUseCase) AbortMultipartUpload(bucketID, uploadID uuid.UUID) error {
+	if _, err := uc.getUpload(bucketID, uploadID); err != nil {
+		return err
+	}
+
+	return uc.discardUpload(uploadID)
+}
+
+// AbortStaleUploads aborts every upload started more than olderThan ago.
+func (uc *multipartUseCase) AbortStaleUploads(olderThan time.Duration) error {
+	uploads, err := uc.uploadRepo.ListStale(time.Now().Add(-olderThan))
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		if err := uc.discardUpload(upload.ID); err != nil {
+			return fmt.Errorf("failed to abort upload %s: %w", upload.ID, err)
+		}
+	}
+
+	if len(uploads) > 0 {
+		log.Printf("Aborted %d stale multipart uploads", len(uploads))
+	}
+	return nil
+}
+
+func (uc *multipartUseCase) getUpload(bucketID, uploadID uuid.UUID) (*domain.MultipartUpload, error) {
+	upload, err := uc.uploadRepo.GetByID(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.BucketID != bucketID {
+		return nil, domain.ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// discardUpload removes the part blobs, among them those of parts uploaded
+// again since, and then the upload records.
+func (uc *multipartUseCase) discardUpload(uploadID uuid.UUID) error {
+	blobs, err := uc.storage.List(context.Background(), multipartStoragePrefix(uploadID)+"/")
+	if err != nil {
+		return err
+	}
+
+	for _, blob := range blobs {
+		if err := uc.storage.Delete(context.Background(), blob.Path); err != nil {
+			return err
+		}
+	}
+
+	return uc.uploadRepo.Delete(uploadID)
+}
+
+func multipartStoragePrefix(uploadID uuid.UUID) string {
+	return path.Join(".multipart", uploadID.String())
+}
+
+// partUploadID returns the upload whose part blob is at storagePath.
+func partUploadID(storagePath string) (uuid.UUID, bool) {
+	segments := strings.Split(storagePath, "/")
+	if len(segments) != 3 || segments[0] != ".multipart" {
+		return uuid.Nil, false
+	}
+	uploadID, err := uuid.Parse(segments[1])
+	return uploadID, err == nil
+}
+
+// recordedPartPaths returns the storage paths of the upload's parts, which are
+// none once the upload is gone.
+func recordedPartPaths(uploadRepo domain.MultipartUploadRepository, uploadID uuid.UUID) (map[string]bool, error) {
+	paths := map[string]bool{}
+
+	if _, err := uploadRepo.GetByID(uploadID); err != nil {
+		if errors.Is(err, domain.ErrUploadNotFound) {
+			return paths, nil
+		}
+		return nil, err
+	}
+
+	parts, err := uploadRepo.ListParts(uploadID)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		paths[part.StoragePath] = true
+	}
+	return paths, nil
+}
+
+// partKey derives the key of one part blob from the upload's data key, so
+// the parts and the completed object never share a key and its nonces. It
+// returns nil for a plaintext upload.
+func partKey(dataKey []byte, storagePath string) []byte {
+	if dataKey == nil {
+		return nil
+	}
+	return encryption.DeriveKey(dataKey, storagePath)
+}
+
+// partsReader streams the parts one after another, decrypted, opening each
+// part only when the previous one is exhausted.
+type partsReader struct {
+	storage domain.StorageBackend
+	parts   []domain.MultipartPart
+	dataKey []byte
+	current io.ReadCloser
+}
+
+func newPartsReader(storage domain.StorageBackend, parts []domain.MultipartPart, dataKey []byte) io.Reader {
+	return &partsReader{storage: storage, parts: parts, dataKey: dataKey}
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			part := r.parts[0]
+			file, err := openBlob(r.storage, part.StoragePath, part.Size, partKey(r.dataKey, part.StoragePath))
+			if err != nil {
+				return 0, err
+			}
+			r.current = file
+			r.parts = r.parts[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
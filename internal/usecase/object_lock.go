@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"s3-like/internal/domain"
+	"time"
+)
+
+// checkObjectLock returns ErrObjectLocked when the version may not be
+// deleted or overwritten: a legal hold always protects it, COMPLIANCE
+// retention until it runs out, and GOVERNANCE retention unless the request
+// bypasses governance.
+func checkObjectLock(lock *domain.ObjectLock, bypassGovernance bool, now time.Time) error {
+	if lock.LegalHold {
+		return domain.ErrObjectLocked
+	}
+	if !retentionActive(lock, now) {
+		return nil
+	}
+	if lock.RetentionMode == domain.RetentionGovernance && bypassGovernance {
+		return nil
+	}
+	return domain.ErrObjectLocked
+}
+
+func retentionActive(lock *domain.ObjectLock, now time.Time) bool {
+	return lock.RetainUntil != nil && now.Before(*lock.RetainUntil)
+}
+
+// newObjectLock returns the lock for a new version in bucket: the requested
+// retention, or the bucket default when none is requested.
+func newObjectLock(bucket *domain.Bucket, requested domain.ObjectLock, now time.Time) (domain.ObjectLock, error) {
+	if requested == (domain.ObjectLock{}) && !bucket.ObjectLockEnabled {
+		return domain.ObjectLock{}, nil
+	}
+	if !bucket.ObjectLockEnabled {
+		return domain.ObjectLock{}, domain.ErrObjectLockNotEnabled
+	}
+
+	lock := requested
+	if lock.RetentionMode == "" && lock.RetainUntil == nil {
+		if mode := bucket.DefaultRetention.Mode; mode != "" {
+			until := now.AddDate(bucket.DefaultRetention.Years, 0, bucket.DefaultRetention.Days).UTC()
+			lock.RetentionMode = mode
+			lock.RetainUntil = &until
+		}
+		return lock, nil
+	}
+
+	if err := validateRetention(lock.RetentionMode, lock.RetainUntil, now); err != nil {
+		return domain.ObjectLock{}, err
+	}
+	return lock, nil
+}
+
+// changeRetention applies a retention update to lock. Extending retention is
+// always allowed, and so is moving from GOVERNANCE to the stricter
+// COMPLIANCE. Anything that weakens active retention needs GOVERNANCE mode
+// and a request that bypasses governance.
+func changeRetention(lock *domain.ObjectLock, mode string, retainUntil *time.Time, bypassGovernance bool, now time.Time) error {
+	removing := mode == "" && retainUntil == nil
+	if !removing {
+		if err := validateRetention(mode, retainUntil, now); err != nil {
+			return err
+		}
+	}
+
+	if retentionActive(lock, now) {
+		weakened := removing ||
+			retainUntil.Before(*lock.RetainUntil) ||
+			(lock.RetentionMode == domain.RetentionCompliance && mode != domain.RetentionCompliance)
+		if weakened && (lock.RetentionMode != domain.RetentionGovernance || !bypassGovernance) {
+			return domain.ErrObjectLocked
+		}
+	}
+
+	if removing {
+		lock.RetentionMode = ""
+		lock.RetainUntil = nil
+		return nil
+	}
+
+	until := retainUntil.UTC()
+	lock.RetentionMode = mode
+	lock.RetainUntil = &until
+	return nil
+}
+
+func validateRetention(mode string, retainUntil *time.Time, now time.Time) error {
+	if mode != domain.RetentionGovernance && mode != domain.RetentionCompliance {
+		return domain.ErrInvalidRetention
+	}
+	if retainUntil == nil || !retainUntil.After(now) {
+		return domain.ErrInvalidRetention
+	}
+	return nil
+}
+
+// validateDefaultRetention checks a bucket default retention; the zero value
+// means no default.
+func validateDefaultRetention(retention *domain.DefaultRetention) error {
+	if *retention == (domain.DefaultRetention{}) {
+		return nil
+	}
+	if retention.Mode != domain.RetentionGovernance && retention.Mode != domain.RetentionCompliance {
+		return domain.ErrInvalidDefaultRetention
+	}
+	if retention.Days < 0 || retention.Years < 0 || (retention.Days > 0) == (retention.Years > 0) {
+		return domain.ErrInvalidDefaultRetention
+	}
+	return nil
+}
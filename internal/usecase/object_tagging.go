@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"s3-like/internal/domain"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func (uc *objectUseCase) PutObjectTagging(bucketID uuid.UUID, key, versionID string, tags domain.ObjectTags) (*domain.Object, error) {
+	if err := validateTags(tags); err != nil {
+		return nil, err
+	}
+
+	return uc.updateObjectVersion(bucketID, key, versionID, func(object *domain.Object) error {
+		object.Tags = tags
+		if len(tags) == 0 {
+			object.Tags = nil
+		}
+		return nil
+	})
+}
+
+// validateTags applies the S3 limits to a tag set: at most 10 tags, keys of
+// 1 to 128 characters and values of up to 256, and no keys in the reserved
+// "aws:" namespace.
+func validateTags(tags domain.ObjectTags) error {
+	if len(tags) > domain.MaxObjectTags {
+		return domain.ErrInvalidTag
+	}
+	for key, value := range tags {
+		if key == "" || utf8.RuneCountInString(key) > domain.MaxTagKeyLength ||
+			utf8.RuneCountInString(value) > domain.MaxTagValueLength ||
+			strings.HasPrefix(strings.ToLower(key), "aws:") {
+			return domain.ErrInvalidTag
+		}
+	}
+	return nil
+}
+
+// tagsMatch reports whether tags include every pair in filter.
+func tagsMatch(tags, filter domain.ObjectTags) bool {
+	for key, value := range filter {
+		if tag, ok := tags[key]; !ok || tag != value {
+			return false
+		}
+	}
+	return true
+}
@@ -1,119 +1,110 @@
 package usecase
 
 import (
-	"crypto/md5"
-	"encoding/json"
-	"fmt"
+	"context"
+	"encoding/base64"
+	"errors"
 	"io"
-	"mime/multipart"
-	"os"
-	"path/filepath"
+	"log"
 	"s3-like/internal/domain"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
 type objectUseCase struct {
 	objectRepo domain.ObjectRepository
-	basePath   string
+	bucketRepo domain.BucketRepository
+	storage    domain.StorageBackend
+	keys       domain.KeyManager
 }
 
-func NewObjectUseCase(objectRepo domain.ObjectRepository, basePath string) domain.ObjectUseCase {
+func NewObjectUseCase(objectRepo domain.ObjectRepository, bucketRepo domain.BucketRepository, storage domain.StorageBackend, keys domain.KeyManager) domain.ObjectUseCase {
 	return &objectUseCase{
 		objectRepo: objectRepo,
-		basePath:   basePath,
+		bucketRepo: bucketRepo,
+		storage:    storage,
+		keys:       keys,
 	}
 }
 
-func (uc *objectUseCase) UploadObject(bucketID uuid.UUID, key string, file multipart.File, header *multipart.FileHeader, metadata map[string]string) (*domain.UploadObjectResponse, error) {
-	// Generate version ID
-	versionID := uuid.New().String()
+func (uc *objectUseCase) PutObject(bucketID uuid.UUID, key string, body io.Reader, opts *domain.PutObjectOptions) (*domain.UploadObjectResponse, error) {
+	if opts == nil {
+		opts = &domain.PutObjectOptions{}
+	}
 
-	// Create storage path
-	storagePath := filepath.Join(uc.basePath, bucketID.String(), key, versionID)
-	if err := os.MkdirAll(filepath.Dir(storagePath), 0755); err != nil {
-		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	if err := validateTags(opts.Tags); err != nil {
+		return nil, err
 	}
 
-	// Create file on disk
-	dst, err := os.Create(storagePath)
+	metadata, err := normalizeMetadata(opts.Metadata)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		return nil, err
 	}
-	defer dst.Close()
 
-	// Copy file content and calculate hash
-	hasher := md5.New()
-	multiWriter := io.MultiWriter(dst, hasher)
-
-	size, err := io.Copy(multiWriter, file)
+	digests, err := newBodyDigests(opts.ContentMD5, opts.Checksums)
 	if err != nil {
-		return nil, fmt.Errorf("failed to copy file: %w", err)
+		return nil, err
 	}
 
-	etag := fmt.Sprintf("%x", hasher.Sum(nil))
+	versionID, lock, err := newVersion(uc.bucketRepo, bucketID, opts.ObjectLock)
+	if err != nil {
+		return nil, err
+	}
 
-	// Prepare metadata JSON
-	var metadataJSON string
-	if metadata != nil && len(metadata) > 0 {
-		// Add system metadata
-		systemMetadata := make(map[string]any)
-		for k, v := range metadata {
-			systemMetadata[k] = v
-		}
+	enc, dataKey, err := newObjectEncryption(uc.bucketRepo, uc.keys, bucketID, opts.Encryption)
+	if err != nil {
+		return nil, err
+	}
 
-		// Add automatic metadata
-		systemMetadata["upload_time"] = time.Now().UTC().Format(time.RFC3339)
-		systemMetadata["original_filename"] = header.Filename
-		systemMetadata["file_size"] = size
-		systemMetadata["content_type"] = header.Header.Get("Content-Type")
+	contentType := defaultContentType(opts.ContentType)
+	algorithm, err := objectCompression(uc.bucketRepo, bucketID, contentType, opts.ContentHeaders)
+	if err != nil {
+		return nil, err
+	}
 
-		metadataBytes, err := json.Marshal(systemMetadata)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
-		}
-		metadataJSON = string(metadataBytes)
-	} else {
-		// Default metadata if none provided
-		defaultMetadata := map[string]any{
-			"upload_time":       time.Now().UTC().Format(time.RFC3339),
-			"original_filename": header.Filename,
-			"file_size":         size,
-			"content_type":      header.Header.Get("Content-Type"),
-		}
-		metadataBytes, err := json.Marshal(defaultMetadata)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal default metadata: %w", err)
+	// Store content and calculate hashes, over the content as uploaded
+	content, err := encodeBody(digests.reader(body), algorithm, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := stageBlob(uc.storage, content)
+	if err != nil {
+		// The HTTP server reports a body cut short of its Content-Length
+		// as an unexpected EOF
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, domain.ErrIncompleteBody
 		}
-		metadataJSON = string(metadataBytes)
+		return nil, err
 	}
 
-	// Determine content type
-	contentType := header.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	checksums, err := digests.verify()
+	if err != nil {
+		blob.discard(uc.storage)
+		return nil, err
 	}
 
-	// Mark previous versions as not latest
-	uc.objectRepo.MarkAsNotLatest(bucketID, key)
-
 	// Create object record
 	object := &domain.Object{
-		Key:         key,
-		BucketID:    bucketID,
-		VersionID:   versionID,
-		Size:        size,
-		ContentType: contentType,
-		ETag:        etag,
-		StoragePath: storagePath,
-		IsLatest:    true,
-		Metadata:    metadataJSON,
+		Key:              key,
+		BucketID:         bucketID,
+		VersionID:        versionID,
+		Size:             digests.size(),
+		ContentType:      contentType,
+		ContentHeaders:   opts.ContentHeaders,
+		ETag:             digests.etag(),
+		Checksums:        checksums,
+		ObjectEncryption: enc,
+		Compression:      algorithm,
+		Metadata:         metadata,
+		UploadInfo:       opts.UploadInfo,
+		ObjectLock:       lock,
+		Tags:             opts.Tags,
 	}
 
-	if err := uc.objectRepo.Create(object); err != nil {
-		// Clean up file if database operation fails
-		os.Remove(storagePath)
+	if err := commitObjectBlob(uc.objectRepo, uc.storage, object, blob); err != nil {
 		return nil, err
 	}
 
@@ -123,70 +114,764 @@ func (uc *objectUseCase) UploadObject(bucketID uuid.UUID, key string, file multi
 	}, nil
 }
 
-func (uc *objectUseCase) GetObject(bucketID uuid.UUID, key string) (*domain.Object, io.ReadCloser, error) {
-	object, err := uc.objectRepo.GetByKey(bucketID, key)
+func (uc *objectUseCase) GetObject(bucketID uuid.UUID, key string, customerKey []byte) (*domain.Object, io.ReadSeekCloser, error) {
+	return uc.openObject(bucketID, key, "", customerKey)
+}
+
+func (uc *objectUseCase) GetObjectVersion(bucketID uuid.UUID, key, versionID string, customerKey []byte) (*domain.Object, io.ReadSeekCloser, error) {
+	return uc.openObject(bucketID, key, versionID, customerKey)
+}
+
+// HeadObject returns ErrDeleteMarker, along with the marker, when the
+// requested version is a delete marker.
+func (uc *objectUseCase) HeadObject(bucketID uuid.UUID, key, versionID string) (*domain.Object, error) {
+	object, err := findObject(uc.objectRepo, bucketID, domain.ObjectIdentifier{Key: key, VersionID: versionID})
+	if err != nil {
+		return nil, err
+	}
+	if object.IsDeleteMarker {
+		return object, domain.ErrDeleteMarker
+	}
+	return object, nil
+}
+
+func (uc *objectUseCase) openObject(bucketID uuid.UUID, key, versionID string, customerKey []byte) (*domain.Object, io.ReadSeekCloser, error) {
+	object, err := uc.HeadObject(bucketID, key, versionID)
+	if err != nil {
+		return object, nil, err
+	}
+
+	dataKey, err := objectDataKey(uc.keys, object.ObjectEncryption, customerKey)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	file, err := os.Open(object.StoragePath)
+	file, err := openObjectBlob(uc.storage, object, dataKey)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, nil, err
 	}
 
 	return object, file, nil
 }
 
-func (uc *objectUseCase) GetObjectVersion(bucketID uuid.UUID, key, versionID string) (*domain.Object, io.ReadCloser, error) {
-	object, err := uc.objectRepo.GetByKeyAndVersion(bucketID, key, versionID)
+// CopyObject copies the source object into a new version of the destination
+// key. The copy shares the source's blob, without reading it, unless it is
+// encrypted or compressed differently from the source.
+func (uc *objectUseCase) CopyObject(srcBucketID uuid.UUID, srcKey string, dstBucketID uuid.UUID, dstKey string, opts *domain.CopyObjectOptions) (*domain.UploadObjectResponse, error) {
+	if opts == nil {
+		opts = &domain.CopyObjectOptions{}
+	}
+
+	replace := opts.MetadataDirective == domain.MetadataDirectiveReplace
+	replaceTags := opts.TaggingDirective == domain.MetadataDirectiveReplace
+	encrypt := opts.Encryption.ServerSide != "" || opts.Encryption.CustomerKey != nil
+	if srcBucketID == dstBucketID && srcKey == dstKey && opts.SourceVersionID == "" && !replace && !replaceTags && !encrypt {
+		return nil, domain.ErrCopyToItself
+	}
+
+	return uc.copyObject(srcBucketID, srcKey, dstBucketID, dstKey, opts, false)
+}
+
+// copyObject implements CopyObject. With keepEncryption the copy is stored
+// like the source, with the same data key and compression, instead of as opts
+// and the destination bucket ask.
+func (uc *objectUseCase) copyObject(srcBucketID uuid.UUID, srcKey string, dstBucketID uuid.UUID, dstKey string, opts *domain.CopyObjectOptions, keepEncryption bool) (*domain.UploadObjectResponse, error) {
+	replace := opts.MetadataDirective == domain.MetadataDirectiveReplace
+
+	source, err := uc.HeadObject(srcBucketID, srcKey, opts.SourceVersionID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	if !copyConditionsMet(source, &opts.Conditions) {
+		return nil, domain.ErrPreconditionFailed
 	}
 
-	file, err := os.Open(object.StoragePath)
+	var replacement domain.UserMetadata
+	if replace {
+		if replacement, err = normalizeMetadata(opts.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := source.Tags
+	if opts.TaggingDirective == domain.MetadataDirectiveReplace {
+		if err := validateTags(opts.Tags); err != nil {
+			return nil, err
+		}
+		tags = opts.Tags
+	}
+
+	versionID, lock, err := newVersion(uc.bucketRepo, dstBucketID, domain.ObjectLock{})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
 
-	return object, file, nil
+	contentType := source.ContentType
+	headers := source.ContentHeaders
+	metadata := source.Metadata
+	if replace {
+		contentType = defaultContentType(opts.ContentType)
+		headers = opts.ContentHeaders
+		metadata = replacement
+	}
+
+	// A copy encrypted and compressed like its source shares the source's
+	// data key, and so its blob
+	enc := source.ObjectEncryption
+	algorithm := source.Compression
+	var dataKey []byte
+	reencode := false
+	if !keepEncryption {
+		mode, err := encryptionMode(uc.bucketRepo, dstBucketID, opts.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		if algorithm, err = objectCompression(uc.bucketRepo, dstBucketID, contentType, headers); err != nil {
+			return nil, err
+		}
+		if !sameEncryption(source.ObjectEncryption, mode, opts.Encryption.CustomerKey) || algorithm != source.Compression {
+			if enc, dataKey, err = newDataKey(uc.keys, mode, opts.Encryption.CustomerKey); err != nil {
+				return nil, err
+			}
+			reencode = true
+		}
+	}
+
+	var blob *stagedBlob
+	if reencode {
+		blob, err = uc.reencodeBlob(source, opts.SourceCustomerKey, algorithm, dataKey)
+	} else {
+		blob, err = shareBlob(uc.storage, source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	object := &domain.Object{
+		Key:              dstKey,
+		BucketID:         dstBucketID,
+		VersionID:        versionID,
+		Size:             source.Size,
+		ContentType:      contentType,
+		ContentHeaders:   headers,
+		ETag:             source.ETag,
+		Checksums:        source.Checksums,
+		ObjectEncryption: enc,
+		Compression:      algorithm,
+		Metadata:         metadata,
+		UploadInfo:       source.UploadInfo,
+		ObjectLock:       lock,
+		Tags:             tags,
+	}
+
+	if err := commitObjectBlob(uc.objectRepo, uc.storage, object, blob); err != nil {
+		return nil, err
+	}
+
+	return &domain.UploadObjectResponse{
+		Object:    *object,
+		VersionID: versionID,
+	}, nil
 }
 
-func (uc *objectUseCase) ListObjects(bucketID uuid.UUID, prefix string, page, pageSize int) (*domain.ListObjectsResponse, error) {
-	if page <= 0 {
-		page = 1
+// reencodeBlob stages the content of source, decoded with its data key and
+// compression, compressed with algorithm and encrypted with dataKey.
+func (uc *objectUseCase) reencodeBlob(source *domain.Object, customerKey []byte, algorithm string, dataKey []byte) (*stagedBlob, error) {
+	sourceKey, err := objectDataKey(uc.keys, source.ObjectEncryption, customerKey)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize <= 0 {
-		pageSize = 50
+
+	file, err := openObjectBlob(uc.storage, source, sourceKey)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	objects, total, err := uc.objectRepo.List(bucketID, prefix, page, pageSize)
+	content, err := encodeBody(file, algorithm, dataKey)
 	if err != nil {
 		return nil, err
 	}
+	return stageBlob(uc.storage, content)
+}
 
-	return &domain.ListObjectsResponse{
-		Objects:    objects,
-		TotalCount: total,
-		Page:       page,
-		PageSize:   pageSize,
-	}, nil
+// ListObjects pages through the latest versions with keyset pagination. The
+// continuation token encodes the key to resume after, so each page is a
+// single index range scan instead of an OFFSET.
+func (uc *objectUseCase) ListObjects(bucketID uuid.UUID, req *domain.ListObjectsRequest) (*domain.ListObjectsResponse, error) {
+	maxKeys := req.MaxKeys
+	if maxKeys < 0 {
+		maxKeys = 0
+	}
+
+	after := req.StartAfter
+	if req.ContinuationToken != "" {
+		decoded, err := decodeContinuationToken(req.ContinuationToken)
+		if err != nil {
+			return nil, err
+		}
+		after = decoded
+	}
+
+	response := &domain.ListObjectsResponse{
+		Objects:        []domain.Object{},
+		CommonPrefixes: []string{},
+		Prefix:         req.Prefix,
+		Delimiter:      req.Delimiter,
+		MaxKeys:        maxKeys,
+	}
+	if maxKeys == 0 {
+		return response, nil
+	}
+
+	// Fetch one more row than needed so truncation is detected without a
+	// COUNT query
+	batchSize := maxKeys + 1
+	lastPrefix := strings.TrimSuffix(after, skipPrefix(""))
+	if lastPrefix == after {
+		lastPrefix = ""
+	}
+
+scan:
+	for {
+		objects, err := uc.objectRepo.List(bucketID, req.Prefix, after, req.Tags, batchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range objects {
+			commonPrefix := rollUpKey(object.Key, req.Prefix, req.Delimiter)
+			if commonPrefix != "" && commonPrefix == lastPrefix {
+				continue
+			}
+
+			if response.KeyCount == maxKeys {
+				response.IsTruncated = true
+				break scan
+			}
+			response.KeyCount++
+
+			if commonPrefix != "" {
+				response.CommonPrefixes = append(response.CommonPrefixes, commonPrefix)
+				lastPrefix = commonPrefix
+				after = skipPrefix(commonPrefix)
+				continue
+			}
+
+			response.Objects = append(response.Objects, object)
+			after = object.Key
+		}
+
+		if len(objects) < batchSize {
+			break
+		}
+	}
+
+	if response.IsTruncated {
+		response.NextContinuationToken = encodeContinuationToken(after)
+	}
+
+	return response, nil
 }
 
 func (uc *objectUseCase) ListObjectVersions(bucketID uuid.UUID, key string) ([]domain.Object, error) {
 	return uc.objectRepo.GetVersions(bucketID, key)
 }
 
-func (uc *objectUseCase) DeleteObject(bucketID uuid.UUID, key string) error {
-	object, err := uc.objectRepo.GetByKey(bucketID, key)
+func (uc *objectUseCase) PutObjectRetention(bucketID uuid.UUID, key, versionID, mode string, retainUntil *time.Time, bypassGovernance bool) (*domain.Object, error) {
+	return uc.updateObjectLock(bucketID, key, versionID, func(lock *domain.ObjectLock) error {
+		return changeRetention(lock, mode, retainUntil, bypassGovernance, time.Now())
+	})
+}
+
+func (uc *objectUseCase) PutObjectLegalHold(bucketID uuid.UUID, key, versionID string, legalHold bool) (*domain.Object, error) {
+	return uc.updateObjectLock(bucketID, key, versionID, func(lock *domain.ObjectLock) error {
+		lock.LegalHold = legalHold
+		return nil
+	})
+}
+
+// updateObjectLock applies change to the lock of one version in a bucket
+// with Object Lock enabled. Delete markers carry no lock.
+func (uc *objectUseCase) updateObjectLock(bucketID uuid.UUID, key, versionID string, change func(lock *domain.ObjectLock) error) (*domain.Object, error) {
+	bucket, err := uc.bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if !bucket.ObjectLockEnabled {
+		return nil, domain.ErrObjectLockNotEnabled
+	}
+
+	return uc.updateObjectVersion(bucketID, key, versionID, func(object *domain.Object) error {
+		return change(&object.ObjectLock)
+	})
+}
+
+func (uc *objectUseCase) PutObjectMetadata(bucketID uuid.UUID, key, versionID string, metadata domain.UserMetadata) (*domain.Object, error) {
+	metadata, err := normalizeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.updateObjectVersion(bucketID, key, versionID, func(object *domain.Object) error {
+		object.Metadata = metadata
+		return nil
+	})
+}
+
+// updateObjectVersion applies change to one version in place, within a
+// transaction. The content is not touched, so the ETag stays the same.
+// Delete markers have nothing to change.
+func (uc *objectUseCase) updateObjectVersion(bucketID uuid.UUID, key, versionID string, change func(object *domain.Object) error) (*domain.Object, error) {
+	var object *domain.Object
+	err := uc.objectRepo.Transaction(func(repo domain.ObjectRepository) error {
+		var err error
+		object, err = findObject(repo, bucketID, domain.ObjectIdentifier{Key: key, VersionID: versionID})
+		if err != nil {
+			return err
+		}
+		if object.IsDeleteMarker {
+			return domain.ErrDeleteMarker
+		}
+
+		if err := change(object); err != nil {
+			return err
+		}
+		return repo.Update(object)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// RestoreObjectVersion copies an earlier version into a new latest version,
+// so the rollback itself shows up in the history. The copy is committed by
+// commitObjectVersion, which demotes the current latest version in the same
+// transaction.
+func (uc *objectUseCase) RestoreObjectVersion(bucketID uuid.UUID, key, versionID string) (*domain.UploadObjectResponse, error) {
+	source, err := uc.HeadObject(bucketID, key, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Already current; restoring it again would only duplicate it
+	if source.IsLatest {
+		return &domain.UploadObjectResponse{
+			Object:    *source,
+			VersionID: source.VersionID,
+		}, nil
+	}
+
+	// The restored version keeps its encryption, which for SSE-C could not
+	// be recreated without the customer key, and its compression
+	return uc.copyObject(bucketID, key, bucketID, key, &domain.CopyObjectOptions{
+		SourceVersionID: versionID,
+	}, true)
+}
+
+// DeleteObject deletes the given version permanently. Without a version, a
+// bucket that has ever been versioned gets a delete marker on top of the
+// history, while an unversioned bucket loses the object for good.
+func (uc *objectUseCase) DeleteObject(bucketID uuid.UUID, key, versionID string, bypassGovernance bool) (*domain.DeletedObject, error) {
+	versioning, err := uc.versioning(bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted *domain.DeletedObject
+	var storagePath string
+	err = uc.objectRepo.Transaction(func(repo domain.ObjectRepository) error {
+		deleted, storagePath, err = deleteObject(repo, bucketID, versioning, domain.ObjectIdentifier{Key: key, VersionID: versionID}, bypassGovernance)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete from database, then the file from storage
+	if storagePath != "" {
+		if err := uc.storage.Delete(context.Background(), storagePath); err != nil {
+			return nil, err
+		}
+	}
+
+	return deleted, nil
+}
+
+func (uc *objectUseCase) DeleteObjects(bucketID uuid.UUID, objects []domain.ObjectIdentifier, bypassGovernance bool) (*domain.DeleteObjectsResponse, error) {
+	if len(objects) > domain.MaxDeleteObjects {
+		return nil, domain.ErrTooManyKeys
+	}
+
+	versioning, err := uc.versioning(bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.DeleteObjectsResponse{
+		Deleted: []domain.DeletedObject{},
+		Errors:  []domain.DeleteObjectError{},
+	}
+	var storagePaths []string
+
+	err = uc.objectRepo.Transaction(func(repo domain.ObjectRepository) error {
+		for _, id := range objects {
+			deleted, storagePath, err := deleteObject(repo, bucketID, versioning, id, bypassGovernance)
+			if errors.Is(err, domain.ErrObjectNotFound) {
+				// Like S3, deleting something that does not exist succeeds
+				response.Deleted = append(response.Deleted, domain.DeletedObject{Key: id.Key, VersionID: id.VersionID})
+				continue
+			}
+			if errors.Is(err, domain.ErrObjectLocked) {
+				// Nothing was changed for this key, so the rest can go ahead
+				response.Errors = append(response.Errors, domain.DeleteObjectError{
+					Key:       id.Key,
+					VersionID: id.VersionID,
+					Message:   err.Error(),
+					Err:       err,
+				})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if storagePath != "" {
+				storagePaths = append(storagePaths, storagePath)
+			}
+			response.Deleted = append(response.Deleted, *deleted)
+		}
+		return nil
+	})
+	if err != nil {
+		// The transaction was rolled back, so nothing was deleted
+		response.Deleted = []domain.DeletedObject{}
+		for _, id := range objects {
+			response.Errors = append(response.Errors, domain.DeleteObjectError{
+				Key:       id.Key,
+				VersionID: id.VersionID,
+				Message:   err.Error(),
+				Err:       err,
+			})
+		}
+		return response, nil
+	}
+
+	// Blobs go only once the rows are gone; a failure here leaves an orphan
+	// blob rather than a row pointing at nothing
+	for _, storagePath := range storagePaths {
+		if err := uc.storage.Delete(context.Background(), storagePath); err != nil {
+			log.Printf("failed to delete blob %s: %v", storagePath, err)
+		}
+	}
+
+	return response, nil
+}
+
+// versioning returns the bucket's versioning status.
+func (uc *objectUseCase) versioning(bucketID uuid.UUID) (domain.VersioningStatus, error) {
+	return bucketVersioning(uc.bucketRepo, bucketID)
+}
+
+// deleteObject applies one delete within a transaction and returns the blob
+// to remove once it commits, if any. In a suspended bucket the delete marker
+// takes the "null" version ID and replaces the null version. Permanently
+// deleting a version is subject to its Object Lock.
+func deleteObject(repo domain.ObjectRepository, bucketID uuid.UUID, versioning domain.VersioningStatus, id domain.ObjectIdentifier, bypassGovernance bool) (*domain.DeletedObject, string, error) {
+	if err := repo.LockKey(bucketID, id.Key); err != nil {
+		return nil, "", err
+	}
+
+	if id.VersionID == "" && versioning != domain.VersioningUnversioned {
+		marker := &domain.Object{
+			Key:            id.Key,
+			BucketID:       bucketID,
+			VersionID:      newVersionID(versioning),
+			IsDeleteMarker: true,
+		}
+		storagePath, err := insertObjectVersion(repo, marker)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return &domain.DeletedObject{
+			Key:                   id.Key,
+			DeleteMarker:          true,
+			DeleteMarkerVersionID: marker.VersionID,
+		}, storagePath, nil
+	}
+
+	object, err := findObject(repo, bucketID, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := checkObjectLock(&object.ObjectLock, bypassGovernance, time.Now()); err != nil {
+		return nil, "", err
+	}
+
+	storagePath, err := deleteObjectVersion(repo, object)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &domain.DeletedObject{
+		Key:          id.Key,
+		VersionID:    id.VersionID,
+		DeleteMarker: object.IsDeleteMarker,
+	}, storagePath, nil
+}
+
+// findObject looks up the version named by id, or the latest version.
+func findObject(repo domain.ObjectRepository, bucketID uuid.UUID, id domain.ObjectIdentifier) (*domain.Object, error) {
+	if id.VersionID != "" {
+		return repo.GetByKeyAndVersion(bucketID, id.Key, id.VersionID)
+	}
+	return repo.GetByKey(bucketID, id.Key)
+}
+
+// deleteObjectVersion removes a version, like removeObjectVersion. When it
+// was the latest version, the newest remaining version becomes the latest.
+func deleteObjectVersion(repo domain.ObjectRepository, object *domain.Object) (string, error) {
+	storagePath, err := removeObjectVersion(repo, object)
+	if err != nil || !object.IsLatest {
+		return storagePath, err
+	}
+
+	versions, err := repo.GetVersions(object.BucketID, object.Key)
+	if err != nil {
+		return "", err
+	}
+	for _, version := range versions {
+		if version.ID != object.ID {
+			version.IsLatest = true
+			return storagePath, repo.Update(&version)
+		}
+	}
+	return storagePath, nil
+}
+
+// removeObjectVersion deletes a version row and drops its reference to its
+// blob, which garbage collection removes once unreferenced. A version written
+// before blobs were shared owns its blob, whose path is returned to delete
+// once the transaction commits.
+func removeObjectVersion(repo domain.ObjectRepository, object *domain.Object) (string, error) {
+	if err := repo.Delete(object.ID); err != nil {
+		return "", err
+	}
+	if object.BlobHash == "" {
+		return object.StoragePath, nil
+	}
+	return "", repo.ReleaseBlob(object.BlobHash)
+}
+
+// copyConditionsMet evaluates the copy preconditions the way S3 does: any
+// failing condition fails the copy, including a matching If-None-Match.
+func copyConditionsMet(object *domain.Object, cond *domain.CopyConditions) bool {
+	// HTTP dates have second precision
+	modified := object.UpdatedAt.Truncate(time.Second)
+
+	if cond.IfMatch != "" && !etagMatches(cond.IfMatch, object.ETag) {
+		return false
+	}
+	if cond.IfNoneMatch != "" && etagMatches(cond.IfNoneMatch, object.ETag) {
+		return false
+	}
+	if cond.IfModifiedSince != nil && !modified.After(*cond.IfModifiedSince) {
+		return false
+	}
+	if cond.IfUnmodifiedSince != nil && modified.After(*cond.IfUnmodifiedSince) {
+		return false
+	}
+	return true
+}
+
+// etagMatches reports whether a comma-separated If-Match style list, which may
+// be "*", contains etag. Quotes and weak validator prefixes are ignored.
+func etagMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidate = strings.Trim(strings.TrimPrefix(candidate, "W/"), "\"")
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// rollUpKey returns the common prefix key is listed under, or "" when the key
+// is listed on its own.
+func rollUpKey(key, prefix, delimiter string) string {
+	if delimiter == "" {
+		return ""
+	}
+
+	i := strings.Index(key[len(prefix):], delimiter)
+	if i < 0 {
+		return ""
+	}
+	return key[:len(prefix)+i+len(delimiter)]
+}
+
+// skipPrefix returns a marker that sorts after nearly every key starting with
+// prefix, so the next query jumps past a rolled-up "folder". The rare keys
+// that sort after it anyway are skipped by the caller.
+func skipPrefix(prefix string) string {
+	return prefix + string(utf8.MaxRune)
+}
+
+func encodeContinuationToken(after string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(after))
+}
+
+func decodeContinuationToken(token string) (string, error) {
+	after, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !utf8.Valid(after) {
+		return "", domain.ErrInvalidContinuation
+	}
+	return string(after), nil
+}
+
+// normalizeMetadata lower-cases the keys of user metadata, as header names
+// are case-insensitive, and enforces the S3 size limit.
+func normalizeMetadata(metadata domain.UserMetadata) (domain.UserMetadata, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	normalized := make(domain.UserMetadata, len(metadata))
+	size := 0
+	for k, v := range metadata {
+		size += len(k) + len(v)
+		normalized[strings.ToLower(k)] = v
+	}
+	if size > domain.MaxUserMetadataSize {
+		return nil, domain.ErrMetadataTooLarge
+	}
+	return normalized, nil
+}
+
+func defaultContentType(contentType string) string {
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+	return contentType
+}
+
+// bucketVersioning returns the versioning status of the bucket.
+func bucketVersioning(bucketRepo domain.BucketRepository, bucketID uuid.UUID) (domain.VersioningStatus, error) {
+	bucket, err := bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return "", err
+	}
+	return bucket.Versioning, nil
+}
+
+// newVersion returns the version ID and Object Lock for a new version of an
+// object in the bucket.
+func newVersion(bucketRepo domain.BucketRepository, bucketID uuid.UUID, requested domain.ObjectLock) (string, domain.ObjectLock, error) {
+	bucket, err := bucketRepo.GetByID(bucketID)
+	if err != nil {
+		return "", domain.ObjectLock{}, err
+	}
+
+	lock, err := newObjectLock(bucket, requested, time.Now())
+	if err != nil {
+		return "", domain.ObjectLock{}, err
+	}
+
+	return newVersionID(bucket.Versioning), lock, nil
+}
+
+// newVersionID returns a unique version ID while versioning is enabled and
+// the "null" version ID otherwise, which each write replaces.
+func newVersionID(versioning domain.VersioningStatus) string {
+	if versioning == domain.VersioningEnabled {
+		return uuid.New().String()
+	}
+	return domain.NullVersionID
+}
+
+// commitObjectBlob records object, stored in blob, as the latest version of
+// its key, moving the blob into the store.
+func commitObjectBlob(objectRepo domain.ObjectRepository, storage domain.StorageBackend, object *domain.Object, blob *stagedBlob) error {
+	object.StoragePath = blob.path()
+	object.BlobHash = blob.hash
+	object.StoredSize = blob.size
+
+	return blob.commit(storage, func() error {
+		return commitObjectVersion(objectRepo, storage, object)
+	})
+}
+
+// commitObjectVersion records object as the latest version of its key. A
+// "null" version replaces the previous "null" version, whose blob is removed
+// once the transaction commits if it owned one.
+func commitObjectVersion(objectRepo domain.ObjectRepository, storage domain.StorageBackend, object *domain.Object) error {
+	var storagePath string
+	err := objectRepo.Transaction(func(repo domain.ObjectRepository) error {
+		var err error
+		storagePath, err = insertObjectVersion(repo, object)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	// Delete file from storage
-	if err := os.Remove(object.StoragePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete file: %w", err)
+	if storagePath != "" {
+		if err := storage.Delete(context.Background(), storagePath); err != nil {
+			log.Printf("failed to delete replaced blob %s: %v", storagePath, err)
+		}
+	}
+	return nil
+}
+
+// insertObjectVersion adds object as the latest version of its key, taking a
+// reference to its blob. When object is a "null" version, the previous null
+// version is removed, unless Object Lock protects it; the path of a blob to
+// delete after commit, as returned by removeObjectVersion, is passed on. The
+// key stays locked until the transaction ends, so concurrent writers cannot
+// both end up latest.
+func insertObjectVersion(repo domain.ObjectRepository, object *domain.Object) (string, error) {
+	if err := repo.LockKey(object.BucketID, object.Key); err != nil {
+		return "", err
+	}
+
+	var storagePath string
+	if object.VersionID == domain.NullVersionID {
+		previous, err := repo.GetByKeyAndVersion(object.BucketID, object.Key, domain.NullVersionID)
+		if err != nil && !errors.Is(err, domain.ErrObjectNotFound) {
+			return "", err
+		}
+		if previous != nil {
+			if err := checkObjectLock(&previous.ObjectLock, false, time.Now()); err != nil {
+				return "", err
+			}
+			if storagePath, err = removeObjectVersion(repo, previous); err != nil {
+				return "", err
+			}
+		}
 	}
 
-	// Delete from database
-	return uc.objectRepo.Delete(object.ID)
+	// Mark previous versions as not latest
+	if err := repo.MarkAsNotLatest(object.BucketID, object.Key); err != nil {
+		return "", err
+	}
+
+	object.IsLatest = true
+	if err := repo.Create(object); err != nil {
+		return "", err
+	}
+	if object.BlobHash != "" {
+		if err := repo.AcquireBlob(object.BlobHash, object.StoredSize); err != nil {
+			return "", err
+		}
+	}
+	return storagePath, nil
 }
@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"errors"
+	"runtime"
+	"s3-like/internal/domain"
+	"s3-like/internal/storage"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// listingRepo serves List from a fixed set of keys, in the byte order the
+// database returns them in.
+type listingRepo struct {
+	domain.ObjectRepository
+	keys []string
+}
+
+func (r *listingRepo) List(bucketID uuid.UUID, prefix, startAfter string, tags domain.ObjectTags, limit int) ([]domain.Object, error) {
+	keys := append([]string(nil), r.keys...)
+	sort.Strings(keys)
+
+	var objects []domain.Object
+	for _, key := range keys {
+		if len(objects) == limit {
+			break
+		}
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			objects = append(objects, domain.Object{Key: key})
+		}
+	}
+	return objects, nil
+}
+
+func TestRollUpKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		prefix    string
+		delimiter string
+		want      string
+	}{
+		{"photos/2024/a.jpg", "", "", ""},
+		{"photos/2024/a.jpg", "", "/", "photos/"},
+		{"photos/2024/a.jpg", "photos/", "/", "photos/2024/"},
+		{"photos/a.jpg", "photos/", "/", ""},
+		{"photos/2024/a.jpg", "photos/20", "/", "photos/2024/"},
+		{"a--b--c", "", "--", "a--"},
+		{"a--b--c", "a--", "--", "a--b--"},
+		{"readme", "", "/", ""},
+	}
+
+	for _, tt := range tests {
+		if got := rollUpKey(tt.key, tt.prefix, tt.delimiter); got != tt.want {
+			t.Errorf("rollUpKey(%q, %q, %q) = %q, want %q", tt.key, tt.prefix, tt.delimiter, got, tt.want)
+		}
+	}
+}
+
+func TestContinuationToken(t *testing.T) {
+	for _, after := range []string{"", "photos/a.jpg", "naïve/ключ", skipPrefix("photos/")} {
+		got, err := decodeContinuationToken(encodeContinuationToken(after))
+		if err != nil || got != after {
+			t.Errorf("round trip of %q = %q, %v", after, got, err)
+		}
+	}
+
+	for _, token := range []string{"not base64!", "_w"} {
+		if _, err := decodeContinuationToken(token); !errors.Is(err, domain.ErrInvalidContinuation) {
+			t.Errorf("decodeContinuationToken(%q) = %v, want ErrInvalidContinuation", token, err)
+		}
+	}
+}
+
+func TestListObjectsPages(t *testing.T) {
+	keys := []string{
+		"a.txt",
+		"docs/guide.md",
+		"docs/readme.md",
+		"photos/2023/a.jpg",
+		"photos/2023/b.jpg",
+		"photos/2023/c.jpg",
+		"photos/2023/d.jpg",
+		"photos/2024/a.jpg",
+		"photos/cover.jpg",
+		"z.txt",
+	}
+
+	// Each page lists its keys, with common prefixes marked by their
+	// trailing delimiter
+	tests := []struct {
+		name  string
+		req   domain.ListObjectsRequest
+		pages [][]string
+	}{
+		{
+			name:  "flat",
+			req:   domain.ListObjectsRequest{MaxKeys: 4},
+			pages: [][]string{keys[:4], keys[4:8], keys[8:]},
+		},
+		{
+			name:  "delimiter",
+			req:   domain.ListObjectsRequest{Delimiter: "/", MaxKeys: 1000},
+			pages: [][]string{{"a.txt", "docs/", "photos/", "z.txt"}},
+		},
+		{
+			name:  "delimiter across pages",
+			req:   domain.ListObjectsRequest{Delimiter: "/", MaxKeys: 1},
+			pages: [][]string{{"a.txt"}, {"docs/"}, {"photos/"}, {"z.txt"}},
+		},
+		{
+			name:  "prefix and delimiter",
+			req:   domain.ListObjectsRequest{Prefix: "photos/", Delimiter: "/", MaxKeys: 2},
+			pages: [][]string{{"photos/2023/", "photos/2024/"}, {"photos/cover.jpg"}},
+		},
+		{
+			name:  "start after",
+			req:   domain.ListObjectsRequest{StartAfter: "photos/2023/d.jpg", MaxKeys: 2},
+			pages: [][]string{{"photos/2024/a.jpg", "photos/cover.jpg"}, {"z.txt"}},
+		},
+		{
+			name:  "start after inside a rolled-up prefix",
+			req:   domain.ListObjectsRequest{StartAfter: "photos/2023/b.jpg", Prefix: "photos/", Delimiter: "/", MaxKeys: 1000},
+			pages: [][]string{{"photos/2023/", "photos/2024/", "photos/cover.jpg"}},
+		},
+		{
+			name:  "exactly one page",
+			req:   domain.ListObjectsRequest{Prefix: "docs/", MaxKeys: 2},
+			pages: [][]string{{"docs/guide.md", "docs/readme.md"}},
+		},
+		{
+			name:  "no matches",
+			req:   domain.ListObjectsRequest{Prefix: "music/", MaxKeys: 10},
+			pages: [][]string{nil},
+		},
+	}
+
+	uc := &objectUseCase{objectRepo: &listingRepo{keys: keys}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			var pages [][]string
+			for {
+				response, err := uc.ListObjects(uuid.New(), &req)
+				if err != nil {
+					t.Fatalf("ListObjects: %v", err)
+				}
+
+				var page []string
+				for _, object := range response.Objects {
+					page = append(page, object.Key)
+				}
+				page = append(page, response.CommonPrefixes...)
+				sort.Strings(page)
+				pages = append(pages, page)
+
+				if response.KeyCount != len(page) {
+					t.Errorf("page %d: KeyCount = %d, want %d", len(pages), response.KeyCount, len(page))
+				}
+				if !response.IsTruncated {
+					if response.NextContinuationToken != "" {
+						t.Errorf("page %d: last page has a continuation token", len(pages))
+					}
+					break
+				}
+				if len(pages) > len(tt.pages) {
+					t.Fatalf("got more than the %d expected pages", len(tt.pages))
+				}
+				req.ContinuationToken = response.NextContinuationToken
+			}
+
+			if len(pages) != len(tt.pages) {
+				t.Fatalf("got %d pages %v, want %d pages %v", len(pages), pages, len(tt.pages), tt.pages)
+			}
+			for i := range pages {
+				if strings.Join(pages[i], ",") != strings.Join(tt.pages[i], ",") {
+					t.Errorf("page %d = %v, want %v", i+1, pages[i], tt.pages[i])
+				}
+			}
+		})
+	}
+}
+
+func TestListObjectsInvalidToken(t *testing.T) {
+	uc := &objectUseCase{objectRepo: &listingRepo{}}
+
+	_, err := uc.ListObjects(uuid.New(), &domain.ListObjectsRequest{ContinuationToken: "%%%", MaxKeys: 10})
+	if !errors.Is(err, domain.ErrInvalidContinuation) {
+		t.Errorf("ListObjects = %v, want ErrInvalidContinuation", err)
+	}
+}
+
+// memoryObjects is the shared state behind memoryObjectRepo.
+type memoryObjects struct {
+	mu       sync.Mutex
+	objects  []domain.Object
+	blobRefs map[string]int
+	keyLocks map[string]*sync.Mutex
+}
+
+// memoryObjectRepo keeps versions in memory. LockKey holds a per-key mutex
+// until the enclosing Transaction returns, like the advisory lock in
+// Postgres, and MarkAsNotLatest yields to widen any race between writers.
+type memoryObjectRepo struct {
+	domain.ObjectRepository
+	state *memoryObjects
+	held  map[string]*sync.Mutex
+}
+
+func newMemoryObjectRepo() *memoryObjectRepo {
+	return &memoryObjectRepo{state: &memoryObjects{blobRefs: make(map[string]int), keyLocks: make(map[string]*sync.Mutex)}}
+}
+
+func (r *memoryObjectRepo) Create(object *domain.Object) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	if object.ID == uuid.Nil {
+		object.ID = uuid.New()
+	}
+	r.state.objects = append(r.state.objects, *object)
+	return nil
+}
+
+func (r *memoryObjectRepo) find(match func(o *domain.Object) bool) (*domain.Object, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	for i := len(r.state.objects) - 1; i >= 0; i-- {
+		if object := r.state.objects[i]; match(&object) {
+			return &object, nil
+		}
+	}
+	return nil, domain.ErrObjectNotFound
+}
+
+func (r *memoryObjectRepo) GetByKey(bucketID uuid.UUID, key string) (*domain.Object, error) {
+	return r.find(func(o *domain.Object) bool {
+		return o.BucketID == bucketID && o.Key == key && o.IsLatest
+	})
+}
+
+func (r *memoryObjectRepo) GetByKeyAndVersion(bucketID uuid.UUID, key, versionID string) (*domain.Object, error) {
+	return r.find(func(o *domain.Object) bool {
+		return o.BucketID == bucketID && o.Key == key && o.VersionID == versionID
+	})
+}
+
+func (r *memoryObjectRepo) GetVersions(bucketID uuid.UUID, key string) ([]domain.Object, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	var versions []domain.Object
+	for i := len(r.state.objects) - 1; i >= 0; i-- {
+		if object := r.state.objects[i]; object.BucketID == bucketID && object.Key == key {
+			versions = append(versions, object)
+		}
+	}
+	return versions, nil
+}
+
+func (r *memoryObjectRepo) ListKeys(bucketID uuid.UUID, prefix, startAfter string, limit int) ([]string, error) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	seen := make(map[string]bool)
+	var keys []string
+	for _, object := range r.state.objects {
+		if object.BucketID == bucketID && strings.HasPrefix(object.Key, prefix) && object.Key > startAfter && !seen[object.Key] {
+			seen[object.Key] = true
+			keys = append(keys, object.Key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+func (r *memoryObjectRepo) Delete(id uuid.UUID) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	for i, object := range r.state.objects {
+		if object.ID == id {
+			r.state.objects = append(r.state.objects[:i], r.state.objects[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *memoryObjectRepo) MarkAsNotLatest(bucketID uuid.UUID, key string) error {
+	r.state.mu.Lock()
+	for i := range r.state.objects {
+		if object := &r.state.objects[i]; object.BucketID == bucketID && object.Key == key {
+			object.IsLatest = false
+		}
+	}
+	r.state.mu.Unlock()
+	runtime.Gosched()
+	return nil
+}
+
+func (r *memoryObjectRepo) AcquireBlob(hash string, size int64) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.blobRefs[hash]++
+	return nil
+}
+
+func (r *memoryObjectRepo) ReleaseBlob(hash string) error {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.blobRefs[hash]--
+	return nil
+}
+
+func (r *memoryObjectRepo) LockKey(bucketID uuid.UUID, key string) error {
+	name := bucketID.String() + "/" + key
+	if _, ok := r.held[name]; ok {
+		return nil
+	}
+
+	r.state.mu.Lock()
+	lock, ok := r.state.keyLocks[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.state.keyLocks[name] = lock
+	}
+	r.state.mu.Unlock()
+
+	lock.Lock()
+	r.held[name] = lock
+	return nil
+}
+
+func (r *memoryObjectRepo) Transaction(fn func(repo domain.ObjectRepository) error) error {
+	tx := &memoryObjectRepo{state: r.state, held: make(map[string]*sync.Mutex)}
+	defer func() {
+		for _, lock := range tx.held {
+			lock.Unlock()
+		}
+	}()
+	return fn(tx)
+}
+
+// versionedBucketRepo reports every bucket as versioned.
+type versionedBucketRepo struct {
+	domain.BucketRepository
+}
+
+func (versionedBucketRepo) GetByID(id uuid.UUID) (*domain.Bucket, error) {
+	return &domain.Bucket{ID: id, Versioning: domain.VersioningEnabled}, nil
+}
+
+func TestRestoreObjectVersionConcurrentWrites(t *testing.T) {
+	repo := newMemoryObjectRepo()
+	uc := &objectUseCase{objectRepo: repo, bucketRepo: versionedBucketRepo{}, storage: storage.NewMemoryBackend()}
+	bucketID := uuid.New()
+
+	first, err := uc.PutObject(bucketID, "report.txt", strings.NewReader("v1"), nil)
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		var wg sync.WaitGroup
+		errs := make(chan error, 2)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := uc.RestoreObjectVersion(bucketID, "report.txt", first.VersionID)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := uc.PutObject(bucketID, "report.txt", strings.NewReader("v2"), nil)
+			errs <- err
+		}()
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Fatalf("round %d: %v", i, err)
+			}
+		}
+
+		versions, _ := repo.GetVersions(bucketID, "report.txt")
+		latest := 0
+		for _, version := range versions {
+			if version.IsLatest {
+				latest++
+			}
+		}
+		if latest != 1 {
+			t.Fatalf("round %d: %d latest versions, want 1", i, latest)
+		}
+	}
+}
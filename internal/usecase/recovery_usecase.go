@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"s3-like/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type recoveryUseCase struct {
+	objectRepo domain.ObjectRepository
+	uploadRepo domain.MultipartUploadRepository
+	storage    domain.StorageBackend
+}
+
+func NewRecoveryUseCase(objectRepo domain.ObjectRepository, uploadRepo domain.MultipartUploadRepository, storage domain.StorageBackend) domain.RecoveryUseCase {
+	return &recoveryUseCase{
+		objectRepo: objectRepo,
+		uploadRepo: uploadRepo,
+		storage:    storage,
+	}
+}
+
+// Recover relies on nothing being written while it runs: any staged blob or
+// unrecorded part it finds belongs to a request that did not finish.
+func (uc *recoveryUseCase) Recover() error {
+	staged, err := uc.removeStagedBlobs()
+	if err != nil {
+		return fmt.Errorf("failed to remove staged blobs: %w", err)
+	}
+
+	parts, err := uc.removeUnrecordedParts()
+	if err != nil {
+		return fmt.Errorf("failed to remove unrecorded parts: %w", err)
+	}
+
+	repaired, err := uc.objectRepo.RepairLatest()
+	if err != nil {
+		return fmt.Errorf("failed to repair latest versions: %w", err)
+	}
+
+	if staged > 0 || parts > 0 || repaired > 0 {
+		log.Printf("Recovery removed %d staged blobs and %d unrecorded parts, and repaired %d versions", staged, parts, repaired)
+	}
+	return nil
+}
+
+// removeStagedBlobs removes content whose upload stopped before it was
+// committed to the blob store.
+func (uc *recoveryUseCase) removeStagedBlobs() (int, error) {
+	blobs, err := uc.storage.List(context.Background(), blobStagingPrefix+"/")
+	if err != nil {
+		return 0, err
+	}
+
+	for _, blob := range blobs {
+		if err := uc.storage.Delete(context.Background(), blob.Path); err != nil {
+			return 0, err
+		}
+	}
+	return len(blobs), nil
+}
+
+// removeUnrecordedParts removes the part blobs no part record points to: those
+// written by an upload that stopped before saving the part, replaced by a
+// later upload of the same part, or left by an upload that was completed or
+// aborted before they were removed.
+func (uc *recoveryUseCase) removeUnrecordedParts() (int, error) {
+	blobs, err := uc.storage.List(context.Background(), ".multipart/")
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	recorded := map[uuid.UUID]map[string]bool{}
+	for _, blob := range blobs {
+		uploadID, ok := partUploadID(blob.Path)
+		if !ok {
+			continue
+		}
+
+		paths, ok := recorded[uploadID]
+		if !ok {
+			paths, err = recordedPartPaths(uc.uploadRepo, uploadID)
+			if err != nil {
+				return removed, err
+			}
+			recorded[uploadID] = paths
+		}
+		if paths[blob.Path] {
+			continue
+		}
+
+		if err := uc.storage.Delete(context.Background(), blob.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,522 @@
+package usecase
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"path"
+	"s3-like/internal/domain"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// quarantinePrefix is where a repairing scrub moves orphaned content,
+	// under its original path, for an operator to inspect or delete
+	quarantinePrefix = ".quarantine"
+	// orphanGracePeriod keeps content written shortly before a scrub from
+	// being taken for an orphan while its record is still being saved
+	orphanGracePeriod = time.Hour
+	// maxScrubFindings is how many findings a report lists
+	maxScrubFindings = 1000
+)
+
+// scrubMetrics are totals over every scrub since the server started,
+// published through expvar.
+var (
+	scrubMetrics  = expvar.NewMap("scrub")
+	lastScrubUnix = new(expvar.Int)
+)
+
+func init() {
+	scrubMetrics.Set("last_finished_unix", lastScrubUnix)
+}
+
+type scrubUseCase struct {
+	objectRepo domain.ObjectRepository
+	blobRepo   domain.BlobRepository
+	uploadRepo domain.MultipartUploadRepository
+	storage    domain.StorageBackend
+	rate       int64
+	batchSize  int
+
+	// running is held for the whole of a scrub, so only one runs at a time
+	running sync.Mutex
+	// mu guards report, which the running scrub fills in as it goes
+	mu     sync.Mutex
+	report *domain.ScrubReport
+}
+
+// NewScrubUseCase returns a scrubber that reads at most rate bytes per
+// second; a rate of 0 or less does not limit it.
+func NewScrubUseCase(objectRepo domain.ObjectRepository, blobRepo domain.BlobRepository, uploadRepo domain.MultipartUploadRepository, storage domain.StorageBackend, rate int64, batchSize int) domain.ScrubUseCase {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &scrubUseCase{
+		objectRepo: objectRepo,
+		blobRepo:   blobRepo,
+		uploadRepo: uploadRepo,
+		storage:    storage,
+		rate:       rate,
+		batchSize:  batchSize,
+	}
+}
+
+// Scrub rehashes every referenced blob against its content address, and
+// every version stored outside the blob store against its ETag where the
+// ETag is a plain MD5. It then reports records whose content is missing and
+// content no record points to. Corrupt and missing content is only
+// reported, as nothing here can restore it.
+func (uc *scrubUseCase) Scrub(repair bool) (*domain.ScrubReport, error) {
+	if !uc.running.TryLock() {
+		return nil, domain.ErrScrubRunning
+	}
+	defer uc.running.Unlock()
+
+	return uc.scrub(repair)
+}
+
+func (uc *scrubUseCase) StartScrub(repair bool) error {
+	if !uc.running.TryLock() {
+		return domain.ErrScrubRunning
+	}
+
+	go func() {
+		defer uc.running.Unlock()
+		if _, err := uc.scrub(repair); err != nil {
+			log.Printf("Storage scrub failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (uc *scrubUseCase) Report() *domain.ScrubReport {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if uc.report == nil {
+		return nil
+	}
+	report := *uc.report
+	report.Findings = make([]domain.ScrubFinding, len(uc.report.Findings))
+	copy(report.Findings, uc.report.Findings)
+	return &report
+}
+
+func (uc *scrubUseCase) scrub(repair bool) (*domain.ScrubReport, error) {
+	pass := &scrubPass{
+		uc:        uc,
+		repair:    repair,
+		startedAt: time.Now(),
+		pacer:     &pacer{rate: uc.rate},
+		parts:     map[uuid.UUID]map[string]bool{},
+	}
+
+	uc.mu.Lock()
+	uc.report = &domain.ScrubReport{
+		StartedAt: pass.startedAt,
+		Repair:    repair,
+		Findings:  []domain.ScrubFinding{},
+	}
+	uc.mu.Unlock()
+
+	err := pass.run()
+
+	finishedAt := time.Now()
+	uc.mu.Lock()
+	uc.report.FinishedAt = &finishedAt
+	if err != nil {
+		uc.report.Error = err.Error()
+	}
+	uc.mu.Unlock()
+
+	scrubMetrics.Add("passes", 1)
+	lastScrubUnix.Set(finishedAt.Unix())
+
+	report := uc.Report()
+	log.Printf("Storage scrub checked %d blobs (%d bytes): %d corrupt, %d missing, %d orphaned, %d quarantined",
+		report.BlobsChecked, report.BytesChecked, report.CorruptCount, report.MissingCount, report.OrphanedCount, report.Quarantined)
+	return report, err
+}
+
+// scrubPass is the state of one scrub.
+type scrubPass struct {
+	uc        *scrubUseCase
+	repair    bool
+	startedAt time.Time
+	pacer     *pacer
+	// parts caches the recorded part paths of each upload seen
+	parts map[uuid.UUID]map[string]bool
+}
+
+func (p *scrubPass) run() error {
+	if err := p.checkBlobs(); err != nil {
+		return fmt.Errorf("failed to check blobs: %w", err)
+	}
+	if err := p.checkUnshared(); err != nil {
+		return fmt.Errorf("failed to check unshared versions: %w", err)
+	}
+	if err := p.checkParts(); err != nil {
+		return fmt.Errorf("failed to check multipart parts: %w", err)
+	}
+	if err := p.findOrphans(); err != nil {
+		return fmt.Errorf("failed to look for orphaned content: %w", err)
+	}
+	return nil
+}
+
+func (p *scrubPass) checkBlobs() error {
+	after := ""
+	for {
+		blobs, err := p.uc.blobRepo.List(after, p.uc.batchSize)
+		if err != nil {
+			return err
+		}
+		for i := range blobs {
+			// Unreferenced blobs are left to garbage collection
+			if blobs[i].RefCount <= 0 {
+				continue
+			}
+			if err := p.checkBlob(&blobs[i]); err != nil {
+				return fmt.Errorf("blob %s: %w", blobs[i].Hash, err)
+			}
+		}
+		if len(blobs) < p.uc.batchSize {
+			return nil
+		}
+		after = blobs[len(blobs)-1].Hash
+	}
+}
+
+func (p *scrubPass) checkBlob(blob *domain.Blob) error {
+	sum, size, err := p.hash(blobPath(blob.Hash), sha256.New())
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return p.checkMissingBlob(blob.Hash)
+	}
+	if err != nil {
+		return err
+	}
+
+	if sum != blob.Hash || size != blob.Size {
+		p.found(domain.ScrubFinding{
+			Kind:   domain.ScrubCorrupt,
+			Path:   blobPath(blob.Hash),
+			Detail: fmt.Sprintf("read %d bytes hashing to %s, expected %d bytes", size, sum, blob.Size),
+		})
+	}
+	return nil
+}
+
+// checkMissingBlob reports a blob whose file is gone, unless garbage
+// collection removed it after it was listed.
+func (p *scrubPass) checkMissingBlob(hash string) error {
+	unlock := lockBlob(hash)
+	defer unlock()
+
+	blob, err := p.uc.blobRepo.GetByHash(hash)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if blob.RefCount <= 0 {
+		return nil
+	}
+
+	if _, err := p.uc.storage.Stat(context.Background(), blobPath(hash)); !errors.Is(err, domain.ErrBlobNotFound) {
+		return err
+	}
+	p.found(domain.ScrubFinding{
+		Kind:   domain.ScrubMissing,
+		Path:   blobPath(hash),
+		Detail: fmt.Sprintf("blob referenced by %d versions", blob.RefCount),
+	})
+	return nil
+}
+
+// checkUnshared checks the versions written before blobs were shared.
+func (p *scrubPass) checkUnshared() error {
+	after := uuid.Nil
+	for {
+		objects, err := p.uc.objectRepo.ListUnshared(after, p.uc.batchSize)
+		if err != nil {
+			return err
+		}
+		for i := range objects {
+			if err := p.checkUnsharedObject(&objects[i]); err != nil {
+				return fmt.Errorf("version %s: %w", objects[i].ID, err)
+			}
+		}
+		if len(objects) < p.uc.batchSize {
+			return nil
+		}
+		after = objects[len(objects)-1].ID
+	}
+}
+
+// checkUnsharedObject rehashes content stored as it was uploaded, whose
+// single-part ETag is its MD5. Anything else is only checked for its size.
+func (p *scrubPass) checkUnsharedObject(object *domain.Object) error {
+	version := fmt.Sprintf("version %s of %q", object.VersionID, object.Key)
+
+	if object.SSE == "" && object.Compression == "" && !strings.Contains(object.ETag, "-") {
+		sum, size, err := p.hash(object.StoragePath, md5.New())
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			return p.checkMissingContent(object.StoragePath, version)
+		}
+		if err != nil {
+			return err
+		}
+		if sum != object.ETag || size != object.Size {
+			p.found(domain.ScrubFinding{
+				Kind:   domain.ScrubCorrupt,
+				Path:   object.StoragePath,
+				Detail: fmt.Sprintf("%s: read %d bytes with MD5 %s, expected %d bytes", version, size, sum, object.Size),
+			})
+		}
+		return nil
+	}
+
+	info, err := p.uc.storage.Stat(context.Background(), object.StoragePath)
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return p.checkMissingContent(object.StoragePath, version)
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size != object.StoredSize {
+		p.found(domain.ScrubFinding{
+			Kind:   domain.ScrubCorrupt,
+			Path:   object.StoragePath,
+			Detail: fmt.Sprintf("%s: stored size is %d bytes, expected %d", version, info.Size, object.StoredSize),
+		})
+	}
+	return nil
+}
+
+// checkMissingContent reports content gone from under a version, unless the
+// version was deleted after it was listed.
+func (p *scrubPass) checkMissingContent(storagePath, version string) error {
+	recorded, err := p.uc.objectRepo.HasStoragePath(storagePath)
+	if err != nil || !recorded {
+		return err
+	}
+	p.found(domain.ScrubFinding{Kind: domain.ScrubMissing, Path: storagePath, Detail: version})
+	return nil
+}
+
+// checkParts looks for the parts of uploads in progress whose content is
+// gone. Parts are not rehashed: they are short-lived, and their content is
+// checked against the part ETags when the upload completes.
+func (p *scrubPass) checkParts() error {
+	uploads, err := p.uc.uploadRepo.ListStale(p.startedAt)
+	if err != nil {
+		return err
+	}
+
+	for _, upload := range uploads {
+		parts, err := p.uc.uploadRepo.ListParts(upload.ID)
+		if err != nil {
+			return err
+		}
+		for _, part := range parts {
+			_, err := p.uc.storage.Stat(context.Background(), part.StoragePath)
+			if errors.Is(err, domain.ErrBlobNotFound) {
+				err = p.checkMissingPart(&part)
+			}
+			if err != nil {
+				return fmt.Errorf("upload %s: %w", upload.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkMissingPart reports a part whose content is gone, unless it was
+// replaced or its upload finished after it was listed.
+func (p *scrubPass) checkMissingPart(part *domain.MultipartPart) error {
+	current, err := p.uc.uploadRepo.GetPart(part.UploadID, part.PartNumber)
+	if errors.Is(err, domain.ErrInvalidPart) {
+		return nil
+	}
+	if err != nil || current.StoragePath != part.StoragePath {
+		return err
+	}
+	p.found(domain.ScrubFinding{
+		Kind:   domain.ScrubMissing,
+		Path:   part.StoragePath,
+		Detail: fmt.Sprintf("part %d of upload %s", part.PartNumber, part.UploadID),
+	})
+	return nil
+}
+
+// findOrphans walks all stored content for anything no record points to.
+func (p *scrubPass) findOrphans() error {
+	files, err := p.uc.storage.List(context.Background(), "")
+	if err != nil {
+		return err
+	}
+
+	settled := p.startedAt.Add(-orphanGracePeriod)
+	for _, file := range files {
+		if file.ModTime.After(settled) || strings.HasPrefix(file.Path, quarantinePrefix+"/") {
+			continue
+		}
+		if err := p.checkOrphan(file.Path); err != nil {
+			return fmt.Errorf("%s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+func (p *scrubPass) checkOrphan(storagePath string) error {
+	switch {
+	case strings.HasPrefix(storagePath, blobStagingPrefix+"/"):
+		// Staged content is committed or discarded as soon as it is written
+		return p.orphan(storagePath)
+
+	case strings.HasPrefix(storagePath, blobStorePrefix+"/"):
+		// Lock the blob as commit does, so one cannot be written and
+		// recorded in between
+		hash := path.Base(storagePath)
+		unlock := lockBlob(hash)
+		defer unlock()
+
+		_, err := p.uc.blobRepo.GetByHash(hash)
+		if errors.Is(err, domain.ErrBlobNotFound) {
+			return p.orphan(storagePath)
+		}
+		return err
+
+	default:
+		if uploadID, ok := partUploadID(storagePath); ok {
+			paths, ok := p.parts[uploadID]
+			if !ok {
+				var err error
+				if paths, err = recordedPartPaths(p.uc.uploadRepo, uploadID); err != nil {
+					return err
+				}
+				p.parts[uploadID] = paths
+			}
+			if !paths[storagePath] {
+				return p.orphan(storagePath)
+			}
+			return nil
+		}
+
+		recorded, err := p.uc.objectRepo.HasStoragePath(storagePath)
+		if err != nil || recorded {
+			return err
+		}
+		return p.orphan(storagePath)
+	}
+}
+
+// orphan reports orphaned content, first moving it into quarantine when
+// repairing.
+func (p *scrubPass) orphan(storagePath string) error {
+	finding := domain.ScrubFinding{Kind: domain.ScrubOrphaned, Path: storagePath}
+	if p.repair {
+		if err := p.uc.storage.Rename(context.Background(), storagePath, path.Join(quarantinePrefix, storagePath)); err != nil {
+			return err
+		}
+		finding.Quarantined = true
+	}
+	p.found(finding)
+	return nil
+}
+
+func (p *scrubPass) found(finding domain.ScrubFinding) {
+	p.uc.mu.Lock()
+	defer p.uc.mu.Unlock()
+
+	report := p.uc.report
+	switch finding.Kind {
+	case domain.ScrubCorrupt:
+		report.CorruptCount++
+	case domain.ScrubMissing:
+		report.MissingCount++
+	case domain.ScrubOrphaned:
+		report.OrphanedCount++
+	}
+	if finding.Quarantined {
+		report.Quarantined++
+		scrubMetrics.Add("quarantined", 1)
+	}
+	if len(report.Findings) < maxScrubFindings {
+		report.Findings = append(report.Findings, finding)
+	}
+	scrubMetrics.Add(finding.Kind, 1)
+
+	log.Printf("Storage scrub found %s content at %s %s", finding.Kind, finding.Path, finding.Detail)
+}
+
+// hash reads the content at storagePath through h, paced, and returns the
+// hex digest and the number of bytes read.
+func (p *scrubPass) hash(storagePath string, h hash.Hash) (string, int64, error) {
+	file, err := p.uc.storage.Get(context.Background(), storagePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	size, err := io.Copy(h, p.pacer.reader(file))
+
+	p.uc.mu.Lock()
+	p.uc.report.BlobsChecked++
+	p.uc.report.BytesChecked += size
+	p.uc.mu.Unlock()
+	scrubMetrics.Add("blobs_checked", 1)
+	scrubMetrics.Add("bytes_checked", size)
+
+	if err != nil {
+		return "", size, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// pacer holds the reads of a scrub to rate bytes per second, so it does not
+// starve requests of disk bandwidth.
+type pacer struct {
+	rate int64
+	// next is when the bytes read so far are paid for
+	next time.Time
+}
+
+func (p *pacer) reader(r io.Reader) io.Reader {
+	if p.rate <= 0 {
+		return r
+	}
+	return &pacedReader{r: r, pacer: p}
+}
+
+type pacedReader struct {
+	r     io.Reader
+	pacer *pacer
+}
+
+func (r *pacedReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+
+	// Time not spent reading is not banked, so reads never burst
+	now := time.Now()
+	if r.pacer.next.Before(now) {
+		r.pacer.next = now
+	}
+	r.pacer.next = r.pacer.next.Add(time.Duration(int64(n) * int64(time.Second) / r.pacer.rate))
+	time.Sleep(time.Until(r.pacer.next))
+
+	return n, err
+}
@@ -0,0 +1,410 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"s3-like/internal/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SigV4Algorithm        = "AWS4-HMAC-SHA256"
+	SigV4TimeFormat       = "20060102T150405Z"
+	SigV4DateFormat       = "20060102"
+	UnsignedPayload       = "UNSIGNED-PAYLOAD"
+	StreamingPayload      = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	EmptyPayloadSHA256    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	sigV4ChunkAlgorithm   = "AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4MaxChunkSize     = 16 << 20
+	sigV4ChunkSignatureKV = "chunk-signature="
+)
+
+// Query parameters of a presigned URL
+const (
+	SigV4QueryAlgorithm     = "X-Amz-Algorithm"
+	SigV4QueryCredential    = "X-Amz-Credential"
+	SigV4QueryDate          = "X-Amz-Date"
+	SigV4QueryExpires       = "X-Amz-Expires"
+	SigV4QuerySignedHeaders = "X-Amz-SignedHeaders"
+	SigV4QuerySignature     = "X-Amz-Signature"
+	SigV4MaxPresignExpiry   = 7 * 24 * time.Hour
+)
+
+// SigV4Credential is the parsed Credential component of a SigV4 signature:
+// <access key>/<date>/<region>/<service>/aws4_request.
+type SigV4Credential struct {
+	AccessKeyID string
+	Date        string
+	Region      string
+	Service     string
+}
+
+func (c SigV4Credential) Scope() string {
+	return c.Date + "/" + c.Region + "/" + c.Service + "/aws4_request"
+}
+
+func ParseSigV4Credential(value string) (*SigV4Credential, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" {
+		return nil, errors.New("malformed credential")
+	}
+
+	return &SigV4Credential{
+		AccessKeyID: parts[0],
+		Date:        parts[1],
+		Region:      parts[2],
+		Service:     parts[3],
+	}, nil
+}
+
+// SigV4Authorization is a parsed "AWS4-HMAC-SHA256 Credential=..., SignedHeaders=..., Signature=..." header.
+type SigV4Authorization struct {
+	Credential    SigV4Credential
+	SignedHeaders []string
+	Signature     string
+}
+
+func ParseSigV4Authorization(header string) (*SigV4Authorization, error) {
+	if !strings.HasPrefix(header, SigV4Algorithm+" ") {
+		return nil, errors.New("unsupported authorization type")
+	}
+
+	auth := &SigV4Authorization{}
+	for _, field := range strings.Split(strings.TrimPrefix(header, SigV4Algorithm+" "), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			return nil, errors.New("malformed authorization header")
+		}
+
+		switch name {
+		case "Credential":
+			credential, err := ParseSigV4Credential(value)
+			if err != nil {
+				return nil, err
+			}
+			auth.Credential = *credential
+		case "SignedHeaders":
+			auth.SignedHeaders = strings.Split(value, ";")
+		case "Signature":
+			auth.Signature = value
+		}
+	}
+
+	if auth.Credential.AccessKeyID == "" || len(auth.SignedHeaders) == 0 || auth.Signature == "" {
+		return nil, errors.New("malformed authorization header")
+	}
+	// An unsigned host would let a captured request be replayed elsewhere
+	if !SigV4SignsHeader(auth.SignedHeaders, "host") {
+		return nil, errors.New("the host header must be signed")
+	}
+
+	return auth, nil
+}
+
+// SigV4SignsHeader reports whether name, in lower case, is among the signed
+// headers.
+func SigV4SignsHeader(signedHeaders []string, name string) bool {
+	for _, header := range signedHeaders {
+		if header == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SigV4CanonicalRequest builds the canonical request for r. Query parameters
+// named in excludeQuery (the signature itself, for presigned URLs) are left out.
+func SigV4CanonicalRequest(r *http.Request, signedHeaders []string, payloadHash string, excludeQuery ...string) string {
+	var b strings.Builder
+
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+
+	canonicalURI := SigV4URIEncode(r.URL.Path, false)
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	b.WriteString(canonicalURI)
+	b.WriteByte('\n')
+
+	b.WriteString(sigV4CanonicalQuery(r.URL.Query(), excludeQuery))
+	b.WriteByte('\n')
+
+	for _, name := range signedHeaders {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(sigV4HeaderValue(r, name))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+
+	b.WriteString(strings.Join(signedHeaders, ";"))
+	b.WriteByte('\n')
+	b.WriteString(payloadHash)
+
+	return b.String()
+}
+
+func SigV4StringToSign(t time.Time, scope, canonicalRequest string) string {
+	return SigV4Algorithm + "\n" +
+		t.UTC().Format(SigV4TimeFormat) + "\n" +
+		scope + "\n" +
+		hexSHA256([]byte(canonicalRequest))
+}
+
+func SigV4SigningKey(secret string, credential SigV4Credential) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), credential.Date)
+	key = hmacSHA256(key, credential.Region)
+	key = hmacSHA256(key, credential.Service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func SigV4Signature(signingKey []byte, stringToSign string) string {
+	return hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+}
+
+// PresignSigV4 adds SigV4 query authentication to r's URL so the request can
+// be replayed by anyone holding the URL until it expires. signedHeaders must
+// be lower-case, sorted and include "host".
+func PresignSigV4(r *http.Request, secret string, credential SigV4Credential, t time.Time, expires time.Duration, signedHeaders []string) {
+	query := r.URL.Query()
+	query.Set(SigV4QueryAlgorithm, SigV4Algorithm)
+	query.Set(SigV4QueryCredential, credential.AccessKeyID+"/"+credential.Scope())
+	query.Set(SigV4QueryDate, t.UTC().Format(SigV4TimeFormat))
+	query.Set(SigV4QueryExpires, strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set(SigV4QuerySignedHeaders, strings.Join(signedHeaders, ";"))
+	r.URL.RawQuery = query.Encode()
+
+	canonicalRequest := SigV4CanonicalRequest(r, signedHeaders, UnsignedPayload, SigV4QuerySignature)
+	signature := SigV4Signature(SigV4SigningKey(secret, credential), SigV4StringToSign(t, credential.Scope(), canonicalRequest))
+
+	query.Set(SigV4QuerySignature, signature)
+	r.URL.RawQuery = query.Encode()
+}
+
+// SigV4URIEncode percent-encodes everything except the RFC 3986 unreserved
+// characters, which is stricter than net/url and what SigV4 requires.
+func SigV4URIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sigV4CanonicalQuery(query url.Values, exclude []string) string {
+	type pair struct{ key, value string }
+
+	var pairs []pair
+	for key, values := range query {
+		skip := false
+		for _, name := range exclude {
+			if key == name {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		for _, value := range values {
+			pairs = append(pairs, pair{SigV4URIEncode(key, true), SigV4URIEncode(value, true)})
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p.key + "=" + p.value
+	}
+	return strings.Join(parts, "&")
+}
+
+func sigV4HeaderValue(r *http.Request, name string) string {
+	// net/http moves these out of the header map
+	switch name {
+	case "host":
+		return r.Host
+	case "content-length":
+		if v := r.Header.Get("Content-Length"); v != "" {
+			return v
+		}
+		return strconv.FormatInt(r.ContentLength, 10)
+	case "transfer-encoding":
+		return strings.Join(r.TransferEncoding, ",")
+	}
+
+	values := append([]string(nil), r.Header.Values(name)...)
+	for i, v := range values {
+		values[i] = strings.Join(strings.Fields(v), " ")
+	}
+	return strings.Join(values, ",")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sha256VerifyingReader checks the body against x-amz-content-sha256 once it
+// has been read to the end.
+type sha256VerifyingReader struct {
+	body     io.ReadCloser
+	hasher   hash.Hash
+	expected string
+}
+
+func NewSHA256VerifyingReader(body io.ReadCloser, expectedHex string) io.ReadCloser {
+	return &sha256VerifyingReader{
+		body:     body,
+		hasher:   sha256.New(),
+		expected: strings.ToLower(expectedHex),
+	}
+}
+
+func (r *sha256VerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.hasher.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(r.hasher.Sum(nil)) != r.expected {
+		return n, domain.ErrContentSHA256Mismatch
+	}
+	return n, err
+}
+
+func (r *sha256VerifyingReader) Close() error {
+	return r.body.Close()
+}
+
+// sigV4ChunkedReader decodes an aws-chunked body signed with
+// STREAMING-AWS4-HMAC-SHA256-PAYLOAD. Each chunk signature chains from the
+// previous one, starting with the seed signature of the request itself.
+type sigV4ChunkedReader struct {
+	body          io.ReadCloser
+	r             *bufio.Reader
+	signingKey    []byte
+	timestamp     string
+	scope         string
+	prevSignature string
+	chunk         []byte
+	done          bool
+	// decodedLength is the declared size of the decoded body, or -1 if none
+	// was declared; decoded counts the bytes decoded so far
+	decodedLength int64
+	decoded       int64
+}
+
+// NewSigV4ChunkedReader decodes body, failing if it does not decode to
+// decodedLength bytes unless that is negative.
+func NewSigV4ChunkedReader(body io.ReadCloser, signingKey []byte, t time.Time, scope, seedSignature string, decodedLength int64) io.ReadCloser {
+	return &sigV4ChunkedReader{
+		body:          body,
+		r:             bufio.NewReader(body),
+		signingKey:    signingKey,
+		timestamp:     t.UTC().Format(SigV4TimeFormat),
+		scope:         scope,
+		prevSignature: seedSignature,
+		decodedLength: decodedLength,
+	}
+}
+
+func (cr *sigV4ChunkedReader) Read(p []byte) (int, error) {
+	for len(cr.chunk) == 0 {
+		if cr.done {
+			return 0, io.EOF
+		}
+		if err := cr.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, cr.chunk)
+	cr.chunk = cr.chunk[n:]
+	return n, nil
+}
+
+func (cr *sigV4ChunkedReader) Close() error {
+	return cr.body.Close()
+}
+
+func (cr *sigV4ChunkedReader) readChunk() error {
+	line, err := cr.r.ReadString('\n')
+	if err != nil {
+		return domain.ErrIncompleteChunkedBody
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	sizeHex, params, ok := strings.Cut(line, ";")
+	if !ok || !strings.HasPrefix(params, sigV4ChunkSignatureKV) {
+		return domain.ErrIncompleteChunkedBody
+	}
+	signature := strings.TrimPrefix(params, sigV4ChunkSignatureKV)
+
+	size, err := strconv.ParseInt(sizeHex, 16, 64)
+	if err != nil || size < 0 || size > sigV4MaxChunkSize {
+		return domain.ErrIncompleteChunkedBody
+	}
+	if cr.decodedLength >= 0 && (cr.decoded+size > cr.decodedLength || size == 0 && cr.decoded != cr.decodedLength) {
+		return domain.ErrDecodedLengthMismatch
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(cr.r, data); err != nil {
+		return domain.ErrIncompleteChunkedBody
+	}
+
+	trailer := make([]byte, 2)
+	if _, err := io.ReadFull(cr.r, trailer); err != nil || !bytes.Equal(trailer, []byte("\r\n")) {
+		return domain.ErrIncompleteChunkedBody
+	}
+
+	stringToSign := sigV4ChunkAlgorithm + "\n" +
+		cr.timestamp + "\n" +
+		cr.scope + "\n" +
+		cr.prevSignature + "\n" +
+		EmptyPayloadSHA256 + "\n" +
+		hexSHA256(data)
+	expected := SigV4Signature(cr.signingKey, stringToSign)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return domain.ErrSignatureDoesNotMatch
+	}
+
+	cr.prevSignature = signature
+	cr.decoded += size
+	cr.chunk = data
+	cr.done = size == 0
+	return nil
+}
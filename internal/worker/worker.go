@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every runs fn every interval until ctx is cancelled. Failures are logged
+// and the job keeps running on its next tick.
+func Every(ctx context.Context, name string, interval time.Duration, fn func() error) {
+	if interval <= 0 {
+		log.Printf("Background job %s disabled", name)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(); err != nil {
+				log.Printf("Background job %s failed: %v", name, err)
+			}
+		}
+	}
+}